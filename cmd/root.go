@@ -4,17 +4,28 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 
 	"github.com/alpen/alpen-cli/internal/commands"
 	"github.com/alpen/alpen-cli/internal/config"
 	"github.com/alpen/alpen-cli/internal/executor"
+	"github.com/alpen/alpen-cli/internal/i18n"
+	"github.com/alpen/alpen-cli/internal/logging"
 	"github.com/alpen/alpen-cli/internal/plugins"
+	"github.com/alpen/alpen-cli/internal/plugins/audit"
+	"github.com/alpen/alpen-cli/internal/plugins/metrics"
+	"github.com/alpen/alpen-cli/internal/plugins/notify"
+	"github.com/alpen/alpen-cli/internal/redact"
+	"github.com/alpen/alpen-cli/internal/scripts"
+	"github.com/alpen/alpen-cli/internal/secrets"
+	"github.com/alpen/alpen-cli/internal/tracing"
 	"github.com/alpen/alpen-cli/internal/ui"
 )
 
@@ -26,11 +37,24 @@ var (
 	date    = "unknown" // 构建日期
 )
 
+// notifyGracePeriod 为命令结束后等待通知发送的宽限期，超时仍未完成的通知会被放弃
+const notifyGracePeriod = 2 * time.Second
+
+// notifier 在配置加载后绑定通知规则，并在进程退出前等待异步通知完成
+var notifier *notify.Plugin
+
+// shutdownTracing 结束进程根 Span 并导出追踪数据，未启用追踪时为 nil
+var shutdownTracing func() error
+
+// logCloser 在进程退出前关闭日志文件
+var logCloser io.Closer
+
+// traceFileName 为未配置采集端或采集端不可用时写入的追踪文件
+const traceFileName = "traces.jsonl"
+
 // rootCmd 负责定义 CLI 根命令
 var rootCmd = &cobra.Command{
-	Use:   "alpen",
-	Short: "Alpen CLI - 团队脚本统一入口",
-	Long:  "Alpen CLI 提供脚本统一管理与执行能力，支持按配置驱动的脚本维护方式。",
+	Use: "alpen",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		showVersion, err := cmd.Flags().GetBool("version")
 		if err != nil {
@@ -49,9 +73,27 @@ func Execute() error {
 	preprocessArgs()
 	start := time.Now()
 	err := rootCmd.Execute()
+	if notifier != nil {
+		notifier.Flush(notifyGracePeriod)
+	}
+	if shutdownTracing != nil {
+		if root := tracing.Root(); root != nil {
+			root.RecordError(err)
+		}
+		if traceErr := shutdownTracing(); traceErr != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("root.trace_export_failed"), traceErr)
+		}
+	}
+	if logCloser != nil {
+		_ = logCloser.Close()
+	}
 	if err != nil {
 		if !commands.IsReportedError(err) {
 			writer := rootCmd.ErrOrStderr()
+			if !ui.Decorations() {
+				ui.Error(writer, "%s", translateRootError(err))
+				return err
+			}
 			displayName := strings.Join(os.Args[1:], " ")
 			displayName = strings.TrimSpace(displayName)
 			if displayName == "" {
@@ -67,9 +109,30 @@ func Execute() error {
 }
 
 func init() {
+	flags := detectInitialFlags(os.Args[1:])
+	_ = i18n.SetLocale(i18n.Detect(flags.Lang))
+	settings, err := config.LoadSettings()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, i18n.T("root.settings_load_failed"), err)
+	}
+	if settings != nil {
+		_ = i18n.SetLocale(i18n.Detect(flags.Lang, settings.Lang))
+	}
+	logger := setupLogging(flags, settings)
+	_ = ui.SetColorMode(flags.Color)
+	if path, err := config.ThemePath(); err == nil {
+		if err := ui.LoadTheme(path); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("root.theme_load_failed"), err)
+		}
+	}
+	// 标准输出重定向到文件或管道时只保留脚本输出
+	ui.SetDecorations(!flags.Quiet && term.IsTerminal(int(os.Stdout.Fd())))
+	rootCmd.Short = i18n.T("root.short")
+	rootCmd.Long = i18n.T("root.long")
+	setupTracing(settings)
 	baseDir, err := os.Getwd()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "获取工作目录失败，已回退为当前目录: %v\n", err)
+		fmt.Fprintf(os.Stderr, i18n.T("root.workdir_failed"), err)
 		baseDir = "."
 	}
 	if home, err := config.ResolveHomeDir(); err == nil {
@@ -77,26 +140,63 @@ func init() {
 	}
 	loader := config.NewLoader(baseDir)
 	registry := plugins.NewRegistry()
-	logger := log.New(os.Stdout, "[alpen] ", log.LstdFlags)
+	registerBuiltinPlugins(registry, logger, settings)
 	exec := executor.NewExecutor(registry, logger)
+	if settings != nil {
+		if checker, err := scripts.NewTrustChecker(settings.Scripts.TrustPolicy); err == nil {
+			exec.SetTrustChecker(checker)
+		} else {
+			logger.Warn(i18n.T("root.trust_init_failed"), "err", err)
+		}
+	}
+	if redactor, err := redact.New(redact.Options{Keys: settings.Redact.Env, Patterns: settings.Redact.Patterns}); err == nil {
+		exec.SetRedactor(redactor)
+	} else {
+		logger.Warn(i18n.T("root.redact_init_failed"), "err", err)
+	}
+	exec.SetSecretResolver(secrets.NewResolver(func() (*secrets.Vault, error) {
+		return secrets.OpenDefaultExisting(settings.Secrets.KeyFile, os.Stdin, os.Stderr)
+	}))
 	deps := commands.Dependencies{
 		Loader:   loader,
 		Executor: exec,
 		Registry: registry,
 		Logger:   logger,
+		Settings: settings,
 		BaseDir:  baseDir,
+		Version:  version,
 	}
 
 	defaultConfigPath, err := config.NormalizeConfigPath("")
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "计算默认配置路径失败: %v\n", err)
+		fmt.Fprintf(os.Stderr, i18n.T("root.default_config_failed"), err)
 		defaultConfigPath = "."
 	}
-	rootCmd.PersistentFlags().StringP("config", "c", defaultConfigPath, "指定命令配置文件路径（仅限 ~/.alpen 下的文件）")
-	rootCmd.PersistentFlags().String("environment", "", "指定环境名称，用于加载环境差异配置")
-	rootCmd.PersistentFlags().BoolP("version", "v", false, "查看当前版本信息")
+	rootCmd.PersistentFlags().StringP("config", "c", defaultConfigPath, i18n.T("root.flag.config"))
+	rootCmd.PersistentFlags().String("environment", "", i18n.T("root.flag.environment"))
+	rootCmd.PersistentFlags().BoolP("version", "v", false, i18n.T("root.version"))
+	rootCmd.PersistentFlags().BoolP("yes", "y", false, i18n.T("root.flag.yes"))
+	rootCmd.PersistentFlags().Bool("dry-run", false, i18n.T("root.flag.dry_run"))
+	rootCmd.PersistentFlags().String("lang", "", i18n.T("root.flag.lang"))
+	rootCmd.PersistentFlags().BoolP("quiet", "q", false, i18n.T("root.flag.quiet"))
+	rootCmd.PersistentFlags().Bool("verbose", false, i18n.T("root.flag.verbose"))
+	rootCmd.PersistentFlags().String("log-format", "", i18n.T("root.flag.log_format"))
+	rootCmd.PersistentFlags().String("log-file", "", i18n.T("root.flag.log_file"))
+	rootCmd.PersistentFlags().String("color", ui.ColorAuto, i18n.T("root.flag.color"))
 	rootCmd.SilenceErrors = true
 	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if lang, _ := cmd.Root().PersistentFlags().GetString("lang"); lang != "" {
+			if err := i18n.SetLocale(lang); err != nil {
+				return err
+			}
+		}
+		if err := validateLogFlags(cmd.Root()); err != nil {
+			return err
+		}
+		color, _ := cmd.Root().PersistentFlags().GetString("color")
+		if err := ui.SetColorMode(color); err != nil {
+			return err
+		}
 		path, err := cmd.Root().PersistentFlags().GetString("config")
 		if err != nil {
 			return err
@@ -115,9 +215,9 @@ func init() {
 	rootCmd.AddCommand(newVersionCmd())
 	commands.Register(rootCmd, deps)
 
-	loaded, configPathUsed, loadErr := bootstrapCommands(rootCmd, deps, loader, logger)
+	loaded, configPathUsed, loadErr := bootstrapCommands(rootCmd, deps, loader)
 	if loadErr != nil {
-		logger.Printf("初始化加载配置失败: %v", loadErr)
+		logger.Warn(i18n.T("root.config_load_failed"), "err", loadErr)
 	}
 	if !loaded {
 		originalRunE := rootCmd.RunE
@@ -137,20 +237,123 @@ func init() {
 					hintPath = computed
 				}
 			}
-			ui.Warning(writer, "未检测到命令配置文件 %s", ui.Highlight(hintPath))
-			ui.Info(writer, "可执行 %s 生成默认配置示例", ui.Highlight("alpen init"))
-			ui.Info(writer, "如需切换其它配置，请确保文件位于 ~/.alpen 内并使用 %s 指定", ui.Highlight("--config"))
+			ui.Warning(writer, i18n.T("root.no_config"), ui.Highlight(hintPath))
+			ui.Info(writer, i18n.T("root.no_config_init_hint"), ui.Highlight("alpen init"))
+			ui.Info(writer, i18n.T("root.no_config_switch_hint"), ui.Highlight("--config"))
 			fmt.Fprintln(writer, "")
 			return cmd.Help()
 		}
 	}
 }
 
+// setupTracing 按全局设置启用执行链路追踪，并继承父进程传入的 TRACEPARENT
+func setupTracing(settings *config.Settings) {
+	if settings == nil || !settings.Tracing.Active() {
+		return
+	}
+	file := settings.Tracing.File
+	if strings.TrimSpace(file) == "" {
+		if path, err := config.StatePath(traceFileName); err == nil {
+			file = path
+		}
+	}
+	shutdownTracing = tracing.Setup(tracing.Options{
+		ServiceName: settings.Tracing.ServiceName,
+		Endpoint:    settings.Tracing.Endpoint,
+		Headers:     settings.Tracing.Headers,
+		File:        config.ExpandPath(file),
+		Parent:      os.Getenv(tracing.TraceparentEnv),
+	}, "alpen")
+	if root := tracing.Root(); root != nil {
+		root.SetAttr("alpen.argv", redact.Args(os.Args[1:]))
+		root.SetAttr("alpen.version", version)
+	}
+}
+
+// setupLogging 按 --quiet、--verbose、--log-format、--log-file 与全局设置 log 创建日志记录器，
+// 参数优先于设置；参数无效时使用默认值，由 validateLogFlags 报告错误
+func setupLogging(flags initialFlags, settings *config.Settings) *slog.Logger {
+	var options config.LogSettings
+	if settings != nil {
+		options = settings.Log
+	}
+	level, _ := logging.ParseLevel(options.Level)
+	if flags.Verbose {
+		level = slog.LevelDebug
+	}
+	if flags.LogFormat != "" {
+		options.Format = flags.LogFormat
+	}
+	format, _ := logging.ParseFormat(options.Format)
+	if flags.LogFile != "" {
+		options.File = flags.LogFile
+	}
+	logger, closer, err := logging.New(logging.Options{
+		Level:  level,
+		Format: format,
+		File:   config.ExpandPath(options.File),
+		Quiet:  flags.Quiet,
+	})
+	if err != nil {
+		logger.Warn(err.Error())
+	}
+	logCloser = closer
+	return logger
+}
+
+// validateLogFlags 校验日志相关的全局参数
+func validateLogFlags(root *cobra.Command) error {
+	quiet, _ := root.PersistentFlags().GetBool("quiet")
+	verbose, _ := root.PersistentFlags().GetBool("verbose")
+	if quiet && verbose {
+		return i18n.Errorf("root.quiet_verbose_conflict")
+	}
+	format, _ := root.PersistentFlags().GetString("log-format")
+	_, err := logging.ParseFormat(format)
+	return err
+}
+
+// registerBuiltinPlugins 注册内置插件，单个插件初始化失败不影响 CLI 启动
+func registerBuiltinPlugins(registry *plugins.Registry, logger *slog.Logger, settings *config.Settings) {
+	if auditPath, err := config.StatePath(audit.DefaultFileName); err == nil {
+		if err := registry.Register(audit.New(auditPath, logger)); err != nil {
+			logger.Warn(i18n.T("root.register_audit_failed"), "err", err)
+		}
+	}
+	if metricsPath, err := config.StatePath(metrics.DefaultFileName); err == nil {
+		textfile := ""
+		if settings != nil {
+			textfile = config.ExpandPath(settings.Metrics.Textfile)
+		}
+		if err := registry.Register(metrics.New(metricsPath, textfile, logger)); err != nil {
+			logger.Warn(i18n.T("root.register_metrics_failed"), "err", err)
+		}
+	}
+	notifier = notify.New(notify.Options{Logger: logger})
+	if err := registry.Register(notifier); err != nil {
+		logger.Warn(i18n.T("root.register_notify_failed"), "err", err)
+	}
+}
+
+// bindNotifyRules 让通知插件按命令路径读取配置中的 notify 规则
+func bindNotifyRules(cfg *config.Config) {
+	if notifier == nil {
+		return
+	}
+	notifier.SetResolver(func(path []string) *config.NotifySpec {
+		target, ok := cfg.ResolveTarget(path)
+		if !ok {
+			return nil
+		}
+		return target.Notify
+	})
+}
+
 // newVersionCmd 输出版本信息
 func newVersionCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "version",
-		Short: "查看当前版本信息",
+		Short: i18n.T("root.version"),
 		Run: func(cmd *cobra.Command, args []string) {
 			printVersion(cmd.OutOrStdout())
 		},
@@ -161,17 +364,22 @@ func printVersion(writer io.Writer) {
 	fmt.Fprintf(writer, "Alpen CLI %s (commit: %s, build date: %s)\n", version, commit, date)
 }
 
-func bootstrapCommands(root *cobra.Command, deps commands.Dependencies, loader *config.Loader, _ *log.Logger) (bool, string, error) {
-	configPath, envName := detectInitialFlags(os.Args[1:])
+func bootstrapCommands(root *cobra.Command, deps commands.Dependencies, loader *config.Loader) (bool, string, error) {
+	flags := detectInitialFlags(os.Args[1:])
+	configPath, envName := flags.ConfigPath, flags.Environment
 	if configPath == "" {
-		if active, err := config.LoadActiveConfigPath(); err == nil && strings.TrimSpace(active) != "" {
-			configPath = active
+		entries, err := config.LoadActiveConfigs()
+		if err == nil && config.IsComposite(entries) {
+			return bootstrapActiveConfigs(root, deps, loader, entries, envName)
+		}
+		if err == nil && len(entries) > 0 {
+			configPath = entries[0].Path
 		}
 	}
 	normalized, err := config.NormalizeConfigPath(configPath)
 	if err != nil {
 		if strings.TrimSpace(configPath) != "" {
-			fmt.Fprintf(os.Stderr, "配置路径无效，将回退为默认值: %v\n", err)
+			fmt.Fprintf(os.Stderr, i18n.T("root.invalid_config_path"), err)
 		}
 		normalized, err = config.NormalizeConfigPath("")
 		if err != nil {
@@ -192,10 +400,42 @@ func bootstrapCommands(root *cobra.Command, deps commands.Dependencies, loader *
 	if err := commands.RegisterDynamicCommands(root, deps, cfg); err != nil {
 		return false, configPath, err
 	}
+	bindNotifyRules(cfg)
 	return true, configPath, nil
 }
 
-func detectInitialFlags(args []string) (configPath string, environment string) {
+// bootstrapActiveConfigs 组合加载多个激活的配置并注册命令，此时不改写 --config，
+// 以便各命令区分组合加载与显式指定的单个配置
+func bootstrapActiveConfigs(root *cobra.Command, deps commands.Dependencies, loader *config.Loader, entries []config.ActiveConfig, envName string) (bool, string, error) {
+	cfg, err := loader.LoadActive(entries, envName)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, entries[0].Path, nil
+		}
+		return false, entries[0].Path, err
+	}
+	if err := commands.RegisterDynamicCommands(root, deps, cfg); err != nil {
+		return false, entries[0].Path, err
+	}
+	bindNotifyRules(cfg)
+	return true, entries[0].Path, nil
+}
+
+// initialFlags 为 cobra 解析参数前需要读取的全局参数
+type initialFlags struct {
+	ConfigPath  string
+	Environment string
+	Lang        string
+	Quiet       bool
+	Verbose     bool
+	LogFormat   string
+	LogFile     string
+	Color       string
+}
+
+// detectInitialFlags 在 cobra 解析参数前读取加载配置、选择语言与创建日志记录器所需的全局参数
+func detectInitialFlags(args []string) initialFlags {
+	var flags initialFlags
 	stop := len(args)
 	for i, arg := range args {
 		if arg == "--" {
@@ -203,33 +443,37 @@ func detectInitialFlags(args []string) (configPath string, environment string) {
 			break
 		}
 	}
+	values := map[string]*string{
+		"-c":            &flags.ConfigPath,
+		"--config":      &flags.ConfigPath,
+		"--environment": &flags.Environment,
+		"--lang":        &flags.Lang,
+		"--log-format":  &flags.LogFormat,
+		"--log-file":    &flags.LogFile,
+		"--color":       &flags.Color,
+	}
+	switches := map[string]*bool{
+		"-q":        &flags.Quiet,
+		"--quiet":   &flags.Quiet,
+		"--verbose": &flags.Verbose,
+	}
 	for i := 0; i < stop; i++ {
-		arg := args[i]
-		switch {
-		case arg == "-c":
-			if i+1 < stop {
-				configPath = args[i+1]
+		name, value, hasValue := strings.Cut(args[i], "=")
+		if target, ok := values[name]; ok {
+			if hasValue {
+				*target = value
+			} else if i+1 < stop {
+				*target = args[i+1]
 				i++
 			}
-		case strings.HasPrefix(arg, "-c="):
-			configPath = strings.TrimPrefix(arg, "-c=")
-		case arg == "--config":
-			if i+1 < stop {
-				configPath = args[i+1]
-				i++
-			}
-		case strings.HasPrefix(arg, "--config="):
-			configPath = strings.TrimPrefix(arg, "--config=")
-		case arg == "--environment":
-			if i+1 < stop {
-				environment = args[i+1]
-				i++
-			}
-		case strings.HasPrefix(arg, "--environment="):
-			environment = strings.TrimPrefix(arg, "--environment=")
+			continue
+		}
+		if target, ok := switches[name]; ok {
+			enabled, err := strconv.ParseBool(value)
+			*target = !hasValue || (err == nil && enabled)
 		}
 	}
-	return configPath, environment
+	return flags
 }
 
 // showWelcome 显示美化的欢迎页面
@@ -237,11 +481,11 @@ func showWelcome(cmd *cobra.Command) error {
 	w := cmd.OutOrStdout()
 
 	commands := []ui.CommandInfo{
-		{Name: "env", Description: "选择并激活配置文件"},
-		{Name: "ls", Description: "快速查看配置中的命令列表"},
-		{Name: "ui", Description: "交互式命令导航 (推荐)"},
-		{Name: "init", Description: "初始化默认配置文件"},
-		{Name: "version", Description: "查看版本信息"},
+		{Name: "env", Description: i18n.T("root.welcome.env")},
+		{Name: "ls", Description: i18n.T("root.welcome.ls")},
+		{Name: "ui", Description: i18n.T("root.welcome.ui")},
+		{Name: "init", Description: i18n.T("root.welcome.init")},
+		{Name: "version", Description: i18n.T("root.welcome.version")},
 	}
 
 	ui.ShowWelcome(w, commands)
@@ -274,16 +518,16 @@ func translateRootError(err error) error {
 		name = strings.TrimSpace(name)
 		var builder strings.Builder
 		if name != "" {
-			builder.WriteString(fmt.Sprintf("未识别的命令：%s", name))
+			builder.WriteString(i18n.T("root.unknown_command_named", name))
 		} else {
-			builder.WriteString("未识别的命令")
+			builder.WriteString(i18n.T("root.unknown_command"))
 		}
 		suggestions := rootCmd.SuggestionsFor(name)
 		if len(suggestions) > 0 {
-			builder.WriteString("\n  建议尝试：")
-			builder.WriteString(strings.Join(suggestions, "、"))
+			builder.WriteString(i18n.T("root.suggestions"))
+			builder.WriteString(strings.Join(suggestions, i18n.T("common.list_sep")))
 		} else {
-			builder.WriteString("\n  建议执行：alpen ls 查看可用命令")
+			builder.WriteString(i18n.T("root.suggest_ls"))
 		}
 		return errors.New(builder.String())
 	}
@@ -2,11 +2,11 @@ package bootstrap
 
 import (
 	"errors"
-	"fmt"
 	"os"
 	"path/filepath"
 
 	"github.com/alpen/alpen-cli/internal/config"
+	"github.com/alpen/alpen-cli/internal/i18n"
 	"github.com/alpen/alpen-cli/internal/templates"
 )
 
@@ -32,7 +32,7 @@ func EnsureHomeAssets(force bool) (*HomeAssetsResult, error) {
 	}
 	testsDir := filepath.Join(scriptsDir, "tests")
 	if err := os.MkdirAll(testsDir, 0o755); err != nil {
-		return nil, fmt.Errorf("创建脚本目录失败: %w", err)
+		return nil, i18n.Errorf("bootstrap.scripts_dir_failed", err)
 	}
 	if err := WriteFileIfNeeded(filepath.Join(scriptsDir, "demo.sh"), templates.DemoShell(), 0o755, force); err != nil {
 		return nil, err
@@ -79,7 +79,7 @@ func ensureDemoModule(configDir string, force bool) error {
 	moduleDir := filepath.Join(configDir, "demo.conf")
 	scriptsDir := filepath.Join(moduleDir, "scripts")
 	if err := os.MkdirAll(scriptsDir, 0o755); err != nil {
-		return fmt.Errorf("创建演示模块目录失败: %w", err)
+		return i18n.Errorf("bootstrap.demo_dir_failed", err)
 	}
 
 	moduleScriptPath := filepath.Join(scriptsDir, "demo_module.sh")
@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alpen/alpen-cli/internal/config"
+	"github.com/alpen/alpen-cli/internal/i18n"
+)
+
+// activeConfigSet 返回需要组合加载的激活配置；显式指定 --config 时只加载该文件
+func activeConfigSet(cmd *cobra.Command) ([]config.ActiveConfig, bool) {
+	if cmd.Root().PersistentFlags().Changed("config") {
+		return nil, false
+	}
+	entries, err := config.LoadActiveConfigs()
+	if err != nil || !config.IsComposite(entries) {
+		return nil, false
+	}
+	return entries, true
+}
+
+// loadCommandConfig 加载当前生效的命令配置，同时返回用于提示信息的配置描述
+func loadCommandConfig(cmd *cobra.Command, deps Dependencies) (*config.Config, string, error) {
+	configPath, envName, err := resolveConfigFlags(cmd)
+	if err != nil {
+		return nil, "", err
+	}
+	if entries, ok := activeConfigSet(cmd); ok {
+		cfg, err := deps.Loader.LoadActive(entries, envName)
+		return cfg, describeActiveConfigs(entries), err
+	}
+	cfg, err := deps.Loader.Load(configPath, envName)
+	return cfg, configPath, err
+}
+
+func describeActiveConfigs(entries []config.ActiveConfig) string {
+	labels := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		labels = append(labels, entry.Label())
+	}
+	return strings.Join(labels, i18n.T("common.comma_sep"))
+}
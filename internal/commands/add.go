@@ -0,0 +1,366 @@
+package commands
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/spf13/cobra"
+
+	"github.com/alpen/alpen-cli/internal/config"
+	"github.com/alpen/alpen-cli/internal/i18n"
+	"github.com/alpen/alpen-cli/internal/ui"
+)
+
+// addRequest 为 alpen add 要新增的命令或子命令
+type addRequest struct {
+	namespace string
+	command   string
+	action    string
+	entry     config.Entry
+}
+
+// NewAddCommand 创建 add 命令，向配置文件新增命令或子命令，缺少 --command 时进入交互式向导
+func NewAddCommand(deps Dependencies) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "add [namespace] <command> [action]",
+		Short:         i18n.T("add.short"),
+		Long:          i18n.T("add.long"),
+		Example:       "  alpen add deploy --command 'make deploy' --description 发布 --alias dp\n  alpen add deploy rollback --command 'make rollback'\n  alpen add",
+		Args:          cobra.MaximumNArgs(3),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAdd(cmd, deps, args)
+		},
+	}
+	cmd.Flags().String("command", "", i18n.T("add.flag.command"))
+	cmd.Flags().String("description", "", i18n.T("add.flag.description"))
+	cmd.Flags().String("alias", "", i18n.T("add.flag.alias"))
+	cmd.Flags().String("file", "", i18n.T("add.flag.file"))
+	return cmd
+}
+
+// NewRemoveCommand 创建 rm 命令，从定义命令或子命令的配置文件中删除它
+func NewRemoveCommand(deps Dependencies) *cobra.Command {
+	return &cobra.Command{
+		Use:           "rm [namespace] <command> [action]",
+		Short:         i18n.T("rm.short"),
+		Long:          i18n.T("rm.long"),
+		Example:       "  alpen rm deploy rollback\n  alpen rm sys\n  alpen rm work deploy --yes",
+		Args:          cobra.RangeArgs(1, 3),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRemove(cmd, deps, args)
+		},
+	}
+}
+
+func runAdd(cmd *cobra.Command, deps Dependencies, args []string) error {
+	cfg, err := loadEditableConfig(cmd, deps)
+	if err != nil {
+		return err
+	}
+	req := addRequest{}
+	if len(args) > 1 && slices.Contains(cfg.Namespaces, args[0]) {
+		req.namespace, args = args[0], args[1:]
+	}
+	if len(args) > 2 {
+		return i18n.Errorf("explain.too_many_args", strings.Join(args, " "))
+	}
+	if len(args) > 0 {
+		req.command = args[0]
+	}
+	if len(args) > 1 {
+		req.action = args[1]
+	}
+	req.entry.Command, _ = cmd.Flags().GetString("command")
+	req.entry.Description, _ = cmd.Flags().GetString("description")
+	req.entry.Alias, _ = cmd.Flags().GetString("alias")
+	if strings.TrimSpace(req.entry.Command) == "" || req.command == "" {
+		if !isInteractiveInput(cmd.InOrStdin()) {
+			return i18n.Errorf("add.command_required")
+		}
+		if err := promptAddRequest(&req, len(args) > 1); err != nil {
+			return err
+		}
+	}
+
+	qualified := config.QualifiedName(req.namespace, req.command)
+	name, _, exists := lookupCommandName(cfg, qualified)
+	var path string
+	switch {
+	case exists && req.action == "":
+		return i18n.Errorf("add.command_exists", qualified, qualified)
+	case exists:
+		path = filepath.FromSlash(cfg.Commands[name].Origin.File)
+		_, req.command = config.SplitQualifiedName(name)
+	default:
+		file, _ := cmd.Flags().GetString("file")
+		if path, err = newCommandFile(cmd, deps, req.namespace, req.command, file); err != nil {
+			return err
+		}
+	}
+
+	doc, err := config.OpenDocument(path)
+	if err != nil {
+		return err
+	}
+	if req.action == "" {
+		err = doc.AddCommand(req.command, req.entry)
+	} else {
+		if !exists {
+			err = doc.AddCommand(req.command, config.Entry{})
+		}
+		if err == nil {
+			err = doc.AddAction(req.command, req.action, req.entry)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	data, updated, err := previewDocument(cmd, deps, doc)
+	if err != nil {
+		return err
+	}
+	qualified = config.QualifiedName(req.namespace, req.command)
+	label := strings.TrimSpace(qualified + " " + req.action)
+	// --file 指定的文件不在当前配置的加载范围内时，新增的命令不会生效
+	if spec, ok := updated.Commands[qualified]; !ok || (req.action != "" && !hasAction(spec, req.action)) {
+		return i18n.Errorf("add.not_loaded", doc.Path, label)
+	}
+	_, err = saveDocument(cmd, doc, data, i18n.T("add.added", ui.Highlight(label), doc.Path))
+	return err
+}
+
+func hasAction(spec config.CommandSpec, action string) bool {
+	_, ok := spec.Actions[action]
+	return ok
+}
+
+func runRemove(cmd *cobra.Command, deps Dependencies, args []string) error {
+	cfg, err := loadEditableConfig(cmd, deps)
+	if err != nil {
+		return err
+	}
+	name, actionName, err := resolveCommandPath(cfg, args)
+	if err != nil {
+		return err
+	}
+	spec := cfg.Commands[name]
+	origin := spec.Origin
+	if actionName != "" {
+		origin = spec.Actions[actionName].Origin
+	}
+	label := strings.TrimSpace(name + " " + actionName)
+	if origin.File == "" {
+		return i18n.Errorf("rm.source_unknown", label)
+	}
+
+	doc, err := config.OpenDocument(filepath.FromSlash(origin.File))
+	if err != nil {
+		return err
+	}
+	_, short := config.SplitQualifiedName(name)
+	if actionName == "" {
+		err = doc.RemoveCommand(short)
+	} else {
+		err = doc.RemoveAction(short, actionName)
+	}
+	if err != nil {
+		return err
+	}
+
+	writer := cmd.OutOrStdout()
+	if assumeYes, _ := cmd.Root().PersistentFlags().GetBool("yes"); !assumeYes {
+		if !isInteractiveInput(cmd.InOrStdin()) {
+			return i18n.Errorf("rm.not_terminal", label)
+		}
+		confirmed := false
+		prompt := &survey.Confirm{Message: i18n.T("rm.confirm", label, origin.String())}
+		if err := survey.AskOne(prompt, &confirmed, surveyOptions()...); err != nil {
+			return err
+		}
+		if !confirmed {
+			ui.Info(writer, i18n.T("rm.cancelled"))
+			return nil
+		}
+	}
+	data, updated, err := previewDocument(cmd, deps, doc)
+	if err != nil {
+		return err
+	}
+	saved, err := saveDocument(cmd, doc, data, i18n.T("rm.removed", ui.Highlight(label), doc.Path))
+	if err != nil || !saved {
+		return err
+	}
+	// 其他文件中仍有同名定义时（例如环境差异文件或模块中的覆盖），命令不会因此消失
+	remaining, ok := updated.Commands[name]
+	if !ok {
+		return nil
+	}
+	source := remaining.Origin
+	if actionName != "" {
+		action, defined := remaining.Actions[actionName]
+		if !defined {
+			return nil
+		}
+		source = action.Origin
+	}
+	ui.Warning(writer, i18n.T("rm.still_defined"), ui.Highlight(label), source.String())
+	return nil
+}
+
+// resolveCommandPath 将命令名或别名解析为命令及子命令名称，与 explain 不同，没有默认命令的顶层命令也可以解析
+func resolveCommandPath(cfg *config.Config, args []string) (string, string, error) {
+	if len(args) > 1 && slices.Contains(cfg.Namespaces, args[0]) {
+		args = append([]string{config.QualifiedName(args[0], args[1])}, args[2:]...)
+	}
+	if len(args) > 2 {
+		return "", "", i18n.Errorf("explain.too_many_args", strings.Join(args, " "))
+	}
+	name, _, ok := lookupCommandName(cfg, args[0])
+	if !ok {
+		return "", "", i18n.Errorf("explain.not_found", args[0])
+	}
+	if len(args) == 1 {
+		return name, "", nil
+	}
+	actionName, _, ok := lookupActionName(cfg.Commands[name], args[1])
+	if !ok {
+		return "", "", i18n.Errorf("explain.action_not_found", name, args[1])
+	}
+	return name, actionName, nil
+}
+
+// loadEditableConfig 加载当前生效的配置，用于定位要修改的文件；配置不存在时提示先执行 alpen init
+func loadEditableConfig(cmd *cobra.Command, deps Dependencies) (*config.Config, error) {
+	if deps.Loader == nil {
+		return nil, i18n.Errorf("common.loader_missing")
+	}
+	cfg, configPath, err := loadCommandConfig(cmd, deps)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, i18n.Errorf("add.no_config", configPath)
+	}
+	return cfg, err
+}
+
+// newCommandFile 返回新增顶层命令时写入的文件：--file 指定的文件，
+// 否则为命名空间对应的配置文件；配置为模块目录时写入目录下的 <命令名>.yaml
+func newCommandFile(cmd *cobra.Command, deps Dependencies, namespace, command, file string) (string, error) {
+	root, err := configRoot(cmd, deps, namespace)
+	if err != nil {
+		return "", err
+	}
+	info, statErr := os.Stat(root)
+	isDir := statErr == nil && info.IsDir()
+	if file = strings.TrimSpace(file); file != "" {
+		file = config.ExpandPath(file)
+		if !filepath.IsAbs(file) {
+			base := root
+			if !isDir {
+				base = filepath.Dir(root)
+			}
+			file = filepath.Join(base, file)
+		}
+		return file, nil
+	}
+	if isDir {
+		return filepath.Join(root, command+".yaml"), nil
+	}
+	return root, nil
+}
+
+// configRoot 返回命名空间对应的配置文件或模块目录，未组合加载时为 --config 指定的配置
+func configRoot(cmd *cobra.Command, deps Dependencies, namespace string) (string, error) {
+	if entries, ok := activeConfigSet(cmd); ok {
+		for _, entry := range entries {
+			if entry.Namespace == namespace {
+				return entry.Path, nil
+			}
+		}
+		return "", i18n.Errorf("add.namespace_required", describeActiveConfigs(entries))
+	}
+	configPath, _, err := resolveConfigFlags(cmd)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(configPath) {
+		configPath = filepath.Join(deps.BaseDir, configPath)
+	}
+	return configPath, nil
+}
+
+// previewDocument 以修改后的内容按完整的加载流程重新加载配置，未通过校验时返回错误，磁盘上的文件保持不变
+func previewDocument(cmd *cobra.Command, deps Dependencies, doc *config.Document) ([]byte, *config.Config, error) {
+	data, err := doc.Bytes()
+	if err != nil {
+		return nil, nil, err
+	}
+	preview := deps
+	preview.Loader = deps.Loader.WithContent(doc.Path, data)
+	updated, _, err := loadCommandConfig(cmd, preview)
+	if err != nil {
+		return nil, nil, i18n.Errorf("edit.invalid", doc.Path, err)
+	}
+	return data, updated, nil
+}
+
+// saveDocument 原子写入配置文件，输出 summary 与 .bak 备份的位置；指定 --dry-run 时只提示不写入，返回是否已写入
+func saveDocument(cmd *cobra.Command, doc *config.Document, data []byte, summary string) (bool, error) {
+	writer := cmd.OutOrStdout()
+	if dryRun, _ := cmd.Root().PersistentFlags().GetBool("dry-run"); dryRun {
+		ui.Info(writer, i18n.T("edit.dry_run"), doc.Path)
+		return false, nil
+	}
+	backup, err := doc.Save(data)
+	if err != nil {
+		return false, err
+	}
+	ui.Success(writer, "%s", summary)
+	if backup != "" {
+		ui.Info(writer, i18n.T("edit.backup"), backup)
+	}
+	return true, nil
+}
+
+// promptAddRequest 逐项询问新增命令所需的字段，已通过参数或选项给出的值作为默认值
+func promptAddRequest(req *addRequest, hasAction bool) error {
+	questions := []*survey.Question{}
+	if req.command == "" {
+		questions = append(questions, &survey.Question{
+			Name:     "command",
+			Prompt:   &survey.Input{Message: i18n.T("add.prompt.name")},
+			Validate: survey.Required,
+		})
+	}
+	if !hasAction {
+		questions = append(questions, &survey.Question{
+			Name:   "action",
+			Prompt: &survey.Input{Message: i18n.T("add.prompt.action")},
+		})
+	}
+	questions = append(questions,
+		&survey.Question{Name: "description", Prompt: &survey.Input{Message: i18n.T("add.prompt.description"), Default: req.entry.Description}},
+		&survey.Question{Name: "alias", Prompt: &survey.Input{Message: i18n.T("add.prompt.alias"), Default: req.entry.Alias}},
+		&survey.Question{Name: "script", Prompt: &survey.Input{Message: i18n.T("add.prompt.command"), Default: req.entry.Command}, Validate: survey.Required},
+	)
+	answers := struct {
+		Command     string `survey:"command"`
+		Action      string `survey:"action"`
+		Description string `survey:"description"`
+		Alias       string `survey:"alias"`
+		Script      string `survey:"script"`
+	}{Command: req.command, Action: req.action}
+	if err := survey.Ask(questions, &answers, surveyOptions()...); err != nil {
+		return err
+	}
+	req.command = strings.TrimSpace(answers.Command)
+	req.action = strings.TrimSpace(answers.Action)
+	req.entry = config.Entry{Description: answers.Description, Alias: answers.Alias, Command: answers.Script}
+	return nil
+}
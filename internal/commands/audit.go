@@ -0,0 +1,183 @@
+package commands
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alpen/alpen-cli/internal/config"
+	"github.com/alpen/alpen-cli/internal/i18n"
+	"github.com/alpen/alpen-cli/internal/plugins/audit"
+	"github.com/alpen/alpen-cli/internal/ui"
+)
+
+// NewAuditCommand 创建 audit 子命令，用于校验与导出执行审计日志
+func NewAuditCommand(_ Dependencies) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "audit",
+		Short:         i18n.T("audit.short"),
+		Long:          i18n.T("audit.long"),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.AddCommand(newAuditVerifyCommand())
+	cmd.AddCommand(newAuditExportCommand())
+	return cmd
+}
+
+func newAuditVerifyCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:           "verify",
+		Short:         i18n.T("audit.verify.short"),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			path, err := auditLogPath()
+			if err != nil {
+				return err
+			}
+			writer := cmd.OutOrStdout()
+			ui.KeyValue(writer, i18n.T("audit.log"), path)
+
+			report, err := audit.Verify(path)
+			if errors.Is(err, os.ErrNotExist) {
+				ui.Info(writer, i18n.T("audit.empty"))
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if report.OK() {
+				ui.Success(writer, i18n.T("audit.intact"), report.Entries)
+				return nil
+			}
+			for _, problem := range report.Problems {
+				ui.Error(writer, i18n.T("audit.problem"), problem.Line, problem.Message)
+			}
+			return wrapReportedError(i18n.Errorf("audit.verify_failed", len(report.Problems)))
+		},
+	}
+}
+
+func newAuditExportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "export",
+		Short:         i18n.T("audit.export.short"),
+		Example:       "  alpen audit export --from 2026-01-01 --to 2026-01-31\n  alpen audit export --from 2026-01-01 --format csv > audit.csv",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			fromRaw, _ := cmd.Flags().GetString("from")
+			toRaw, _ := cmd.Flags().GetString("to")
+			format, _ := cmd.Flags().GetString("format")
+
+			var filter audit.Filter
+			var err error
+			if filter.From, err = parseAuditTime(fromRaw, false); err != nil {
+				return err
+			}
+			if filter.To, err = parseAuditTime(toRaw, true); err != nil {
+				return err
+			}
+			path, err := auditLogPath()
+			if err != nil {
+				return err
+			}
+			entries, err := audit.Read(path, filter)
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			switch strings.ToLower(strings.TrimSpace(format)) {
+			case "", "jsonl":
+				return writeAuditJSONL(cmd.OutOrStdout(), entries)
+			case "csv":
+				return writeAuditCSV(cmd.OutOrStdout(), entries)
+			default:
+				return i18n.Errorf("audit.format_unsupported", format)
+			}
+		},
+	}
+	cmd.Flags().String("from", "", i18n.T("audit.flag.from"))
+	cmd.Flags().String("to", "", i18n.T("audit.flag.to"))
+	cmd.Flags().String("format", "jsonl", i18n.T("audit.flag.format"))
+	return cmd
+}
+
+func auditLogPath() (string, error) {
+	return config.StatePath(audit.DefaultFileName)
+}
+
+// parseAuditTime 解析日期或 RFC3339 时间，endOfRange 为 true 时日期格式取次日零点作为开区间上界
+func parseAuditTime(raw string, endOfRange bool) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", raw, time.Local)
+	if err != nil {
+		return time.Time{}, i18n.Errorf("audit.time_invalid", raw)
+	}
+	if endOfRange {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+func writeAuditJSONL(writer io.Writer, entries []audit.Entry) error {
+	encoder := json.NewEncoder(writer)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeAuditCSV(writer io.Writer, entries []audit.Entry) error {
+	w := csv.NewWriter(writer)
+	if err := w.Write([]string{"time", "event", "user", "host", "command", "args", "config", "origin", "environment", "exit_code", "duration_ms", "error"}); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		exitCode := ""
+		if entry.ExitCode != nil {
+			exitCode = strconv.Itoa(*entry.ExitCode)
+		}
+		duration := ""
+		if entry.DurationMS > 0 {
+			duration = strconv.FormatInt(entry.DurationMS, 10)
+		}
+		record := []string{
+			entry.Time.Format(time.RFC3339),
+			entry.Event,
+			entry.User,
+			entry.Host,
+			strings.Join(entry.CommandPath, " "),
+			strings.Join(entry.Args, " "),
+			entry.ConfigFile,
+			entry.Origin,
+			entry.Environment,
+			exitCode,
+			duration,
+			entry.Error,
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,411 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alpen/alpen-cli/internal/config"
+	"github.com/alpen/alpen-cli/internal/executor"
+	"github.com/alpen/alpen-cli/internal/i18n"
+	"github.com/alpen/alpen-cli/internal/ui"
+)
+
+// batchStatus 为批量执行中单条命令的结果
+type batchStatus string
+
+const (
+	batchSucceeded   batchStatus = "succeeded"
+	batchFailed      batchStatus = "failed"
+	batchInterrupted batchStatus = "interrupted"
+	batchSkipped     batchStatus = "skipped"
+)
+
+// Label 返回状态在当前语言下的描述
+func (s batchStatus) Label() string {
+	switch s {
+	case batchSucceeded:
+		return i18n.T("batch.status.succeeded")
+	case batchFailed:
+		return i18n.T("batch.status.failed")
+	case batchInterrupted:
+		return i18n.T("batch.status.interrupted")
+	default:
+		return i18n.T("batch.status.skipped")
+	}
+}
+
+// batchItem 为批量执行中的一条命令，Request 在开始执行前已构建完成
+type batchItem struct {
+	Target  config.Target
+	Request executor.ScriptRequest
+	Args    []string
+	Params  map[string]string
+}
+
+// batchResult 为单条命令的执行结果
+type batchResult struct {
+	Label    string
+	Status   batchStatus
+	Duration time.Duration
+	Err      error
+}
+
+// batchRunner 按并发上限执行多条命令，Jobs 为 1 时依次执行；
+// KeepGoing 为 false 时遇到第一条失败的命令即中断正在执行的命令，并跳过尚未开始的命令
+type batchRunner struct {
+	Executor  *executor.Executor
+	Jobs      int
+	KeepGoing bool
+	// Start 在每条命令开始前调用，可以设置 req.Output 或输出开始提示
+	Start func(index int, req *executor.ScriptRequest)
+	// Finish 在每条命令结束后调用
+	Finish func(index int, result batchResult)
+}
+
+// Run 执行全部命令并按输入顺序返回结果
+func (r batchRunner) Run(ctx context.Context, items []batchItem) []batchResult {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]batchResult, len(items))
+	for i, item := range items {
+		results[i] = batchResult{Label: item.Target.Label(), Status: batchSkipped}
+	}
+	slots := make(chan struct{}, max(r.Jobs, 1))
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		failed bool
+	)
+	for i := range items {
+		slots <- struct{}{}
+		mu.Lock()
+		stop := failed && !r.KeepGoing
+		mu.Unlock()
+		if stop || ctx.Err() != nil {
+			<-slots
+			break
+		}
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			item := items[index]
+			req := item.Request
+			if r.Start != nil {
+				r.Start(index, &req)
+			}
+			started := time.Now()
+			outcome, err := r.Executor.Execute(ctx, req)
+			result := batchResult{Label: item.Target.Label(), Status: batchSucceeded, Duration: outcome.Duration, Err: err}
+			if result.Duration == 0 {
+				result.Duration = time.Since(started)
+			}
+			mu.Lock()
+			switch {
+			case err == nil:
+			case ctx.Err() != nil:
+				result.Status = batchInterrupted
+			default:
+				result.Status = batchFailed
+				failed = true
+				if !r.KeepGoing {
+					cancel()
+				}
+			}
+			results[index] = result
+			mu.Unlock()
+			recordHistory(r.Executor.Redactor(), item.Target, item.Args, item.Params)
+			if r.Finish != nil {
+				r.Finish(index, result)
+			}
+		}(i)
+	}
+	wg.Wait()
+	return results
+}
+
+// batchFailures 返回未成功的命令数
+func batchFailures(results []batchResult) int {
+	count := 0
+	for _, result := range results {
+		if result.Status != batchSucceeded {
+			count++
+		}
+	}
+	return count
+}
+
+// renderBatchSummary 以表格输出每条命令的状态与耗时
+func renderBatchSummary(writer io.Writer, results []batchResult) {
+	headers := []string{i18n.T("batch.col.command"), i18n.T("batch.col.status"), i18n.T("batch.col.duration"), i18n.T("batch.col.error")}
+	rows := make([][]string, 0, len(results))
+	for _, result := range results {
+		duration := "-"
+		if result.Status != batchSkipped {
+			duration = formatStatDuration(result.Duration)
+		}
+		message := ""
+		if result.Err != nil {
+			message = strings.ReplaceAll(strings.TrimSpace(result.Err.Error()), "\n", " ")
+		}
+		rows = append(rows, []string{result.Label, result.Status.Label(), duration, message})
+	}
+
+	widths := make([]int, len(headers))
+	for i, header := range headers {
+		widths[i] = displayWidth(header)
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], displayWidth(cell))
+		}
+	}
+
+	ui.MenuTitle(writer, i18n.T("batch.title"))
+	headerCells := make([]string, len(headers))
+	for i, header := range headers {
+		headerCells[i] = padRight(header, widths[i])
+	}
+	fmt.Fprintln(writer, ui.Gray("  "+strings.TrimRight(strings.Join(headerCells, "  "), " ")))
+	for index, row := range rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = padRight(cell, widths[i])
+		}
+		cells[0] = ui.Cyan(cells[0])
+		switch results[index].Status {
+		case batchSucceeded:
+			cells[1] = ui.Green(cells[1])
+		case batchFailed:
+			cells[1] = ui.Red(cells[1])
+		default:
+			cells[1] = ui.Yellow(cells[1])
+		}
+		cells[3] = ""
+		if row[3] != "" {
+			cells[3] = ui.Red(row[3])
+		}
+		fmt.Fprintln(writer, strings.TrimRight("  "+strings.Join(cells, "  "), " "))
+	}
+	fmt.Fprintln(writer, "")
+	if failures := batchFailures(results); failures > 0 {
+		ui.Error(writer, i18n.T("batch.summary_failed"), len(results), failures)
+	} else {
+		ui.Success(writer, i18n.T("batch.summary_ok"), len(results))
+	}
+}
+
+// prefixWriter 为并行执行的每条命令输出加上命令名前缀，按行写入共享的输出，避免不同命令的输出交错在同一行
+type prefixWriter struct {
+	mu      *sync.Mutex
+	out     io.Writer
+	prefix  string
+	pending bytes.Buffer
+}
+
+func newPrefixWriter(out io.Writer, mu *sync.Mutex, label string) *prefixWriter {
+	return &prefixWriter{mu: mu, out: out, prefix: ui.Cyan("["+label+"]") + " "}
+}
+
+func (w *prefixWriter) Write(data []byte) (int, error) {
+	w.pending.Write(data)
+	for {
+		line, err := w.pending.ReadString('\n')
+		if err != nil {
+			// 未换行的内容放回缓冲区，等待后续输出或 Flush
+			rest := line
+			w.pending.Reset()
+			w.pending.WriteString(rest)
+			return len(data), nil
+		}
+		w.writeLine(line)
+	}
+}
+
+// Flush 写出末尾未换行的内容
+func (w *prefixWriter) Flush() {
+	if w.pending.Len() == 0 {
+		return
+	}
+	w.writeLine(w.pending.String() + "\n")
+	w.pending.Reset()
+}
+
+func (w *prefixWriter) writeLine(line string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	io.WriteString(w.out, w.prefix+line)
+}
+
+// NewRunCommand 创建 run 命令，一次执行多条配置命令
+func NewRunCommand(deps Dependencies) *cobra.Command {
+	var (
+		jobs      int
+		keepGoing bool
+	)
+	cmd := &cobra.Command{
+		Use:           "run <command>...",
+		Short:         i18n.T("batch.short"),
+		Long:          i18n.T("batch.long"),
+		Example:       "  alpen run lint \"test unit\" build\n  alpen run -j 3 --keep-going lint \"test unit\" \"test e2e\"",
+		Args:          cobra.MinimumNArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if jobs < 1 {
+				return i18n.Errorf("batch.jobs_invalid")
+			}
+			return runBatchCommand(cmd, deps, args, batchRunner{Executor: deps.Executor, Jobs: jobs, KeepGoing: keepGoing})
+		},
+	}
+	cmd.Flags().IntVarP(&jobs, "jobs", "j", 1, i18n.T("batch.flag.jobs"))
+	cmd.Flags().BoolVarP(&keepGoing, "keep-going", "k", false, i18n.T("batch.flag.keep_going"))
+	return cmd
+}
+
+func runBatchCommand(cmd *cobra.Command, deps Dependencies, args []string, runner batchRunner) error {
+	if deps.Loader == nil {
+		return i18n.Errorf("common.loader_missing")
+	}
+	if deps.Executor == nil {
+		return i18n.Errorf("common.executor_missing")
+	}
+	cfg, _, err := loadCommandConfig(cmd, deps)
+	if err != nil {
+		return err
+	}
+	writer := cmd.OutOrStdout()
+
+	targets := make([]config.Target, 0, len(args))
+	for _, arg := range args {
+		path, _, err := resolveExplainPath(cfg, strings.Fields(arg))
+		if err != nil {
+			return err
+		}
+		targets = append(targets, targetFor(cfg, path))
+	}
+	items, err := prepareBatch(cmd, deps, targets)
+	if err != nil {
+		return err
+	}
+	if dryRunRequested(cmd) {
+		for _, item := range items {
+			if err := showPlan(cmd, deps, item.Target, item.Request, writer); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	reader := bufio.NewReader(cmd.InOrStdin())
+	for _, item := range items {
+		if err := confirmGuard(cmd, item.Target, item.Request.Environment, reader, writer); err != nil {
+			return err
+		}
+	}
+	if err := renderBatch(cmd, items); err != nil {
+		return err
+	}
+
+	attachBatchOutput(&runner, writer, items)
+	results := runner.Run(cmd.Context(), items)
+	failures := batchFailures(results)
+	if ui.Decorations() {
+		// 不输出执行框架时汇总表也一并省略，失败的命令已由执行器记录日志
+		fmt.Fprintln(writer, "")
+		renderBatchSummary(writer, results)
+	}
+	if failures > 0 {
+		return wrapReportedError(i18n.Errorf("batch.failed", failures))
+	}
+	return nil
+}
+
+// attachBatchOutput 设置批量执行的输出方式：依次执行时与单条命令的输出一致；
+// 并行执行时每行输出加上命令名前缀
+func attachBatchOutput(runner *batchRunner, writer io.Writer, items []batchItem) {
+	if runner.Jobs > 1 && len(items) > 1 {
+		var mu sync.Mutex
+		outputs := make([]*prefixWriter, len(items))
+		runner.Start = func(index int, req *executor.ScriptRequest) {
+			outputs[index] = newPrefixWriter(writer, &mu, items[index].Target.Label())
+			req.Output = outputs[index]
+			if !ui.Decorations() {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			ui.Executing(writer, items[index].Target.Label())
+		}
+		runner.Finish = func(index int, _ batchResult) {
+			outputs[index].Flush()
+		}
+		return
+	}
+	runner.Start = func(index int, _ *executor.ScriptRequest) {
+		ui.BeginExecution(writer, items[index].Target.Label())
+	}
+	runner.Finish = func(_ int, result batchResult) {
+		ui.EndExecution(writer)
+		ui.ExecutionSummary(writer, result.Err == nil, result.Duration, result.Err)
+	}
+}
+
+// prepareBatch 检查前置条件与参数并以预览方式构建全部执行请求，任一命令无法执行时不执行任何命令；
+// 预览不执行 sh 变量，执行确认通过后由 renderBatch 重新构建
+func prepareBatch(cmd *cobra.Command, deps Dependencies, targets []config.Target) ([]batchItem, error) {
+	checker := requirementChecker(deps)
+	items := make([]batchItem, 0, len(targets))
+	for _, target := range targets {
+		if strings.TrimSpace(target.Command) == "" {
+			return nil, i18n.Errorf("batch.no_script", target.Label())
+		}
+		if missing := requiredParams(target); len(missing) > 0 {
+			return nil, i18n.Errorf("batch.params_required", target.Label(), strings.Join(missing, ", "))
+		}
+		if !dryRunRequested(cmd) {
+			if err := checker.Check(target).Err(target.Label()); err != nil {
+				return nil, err
+			}
+		}
+		req, err := previewScriptRequest(cmd, target, nil, nil)
+		if err != nil {
+			return nil, i18n.Errorf("batch.command_error", target.Label(), err)
+		}
+		items = append(items, batchItem{Target: target, Request: req})
+	}
+	return items, nil
+}
+
+// renderBatch 在执行确认通过后重新构建执行请求，此时才执行模板引用的 sh 变量
+func renderBatch(cmd *cobra.Command, items []batchItem) error {
+	for i, item := range items {
+		req, err := buildScriptRequest(cmd, item.Target, item.Args, item.Params)
+		if err != nil {
+			return i18n.Errorf("batch.command_error", item.Target.Label(), err)
+		}
+		items[i].Request = req
+	}
+	return nil
+}
+
+// requiredParams 返回命令中没有默认值的参数，批量执行只使用参数默认值，无法为它们传值
+func requiredParams(target config.Target) []string {
+	var missing []string
+	for name, spec := range target.Params {
+		if spec.Required() {
+			missing = append(missing, name)
+		}
+	}
+	slices.Sort(missing)
+	return missing
+}
@@ -0,0 +1,174 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alpen/alpen-cli/internal/config"
+	"github.com/alpen/alpen-cli/internal/executor"
+	"github.com/alpen/alpen-cli/internal/plugins"
+)
+
+func newBatchItems(commands map[string]string, order ...string) []batchItem {
+	items := make([]batchItem, 0, len(order))
+	for _, name := range order {
+		target := config.Target{Path: []string{name}, Command: commands[name]}
+		items = append(items, batchItem{
+			Target:  target,
+			Request: executor.ScriptRequest{CommandPath: target.Path, Command: target.Command, Output: &bytes.Buffer{}},
+		})
+	}
+	return items
+}
+
+func batchStatuses(results []batchResult) []batchStatus {
+	statuses := make([]batchStatus, 0, len(results))
+	for _, result := range results {
+		statuses = append(statuses, result.Status)
+	}
+	return statuses
+}
+
+func TestBatchRunnerStopsOnFirstFailure(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	commands := map[string]string{"lint": "true", "test": "exit 3", "build": "true"}
+	runner := batchRunner{Executor: executor.NewExecutor(plugins.NewRegistry(), nil), Jobs: 1}
+
+	results := runner.Run(context.Background(), newBatchItems(commands, "lint", "test", "build"))
+	want := []batchStatus{batchSucceeded, batchFailed, batchSkipped}
+	if got := batchStatuses(results); !slices.Equal(got, want) {
+		t.Fatalf("unexpected statuses: %v", got)
+	}
+	if results[1].Err == nil || batchFailures(results) != 2 {
+		t.Fatalf("expected failure to be reported, got %+v", results)
+	}
+
+	runner.KeepGoing = true
+	results = runner.Run(context.Background(), newBatchItems(commands, "lint", "test", "build"))
+	if got := batchStatuses(results); !slices.Equal(got, []batchStatus{batchSucceeded, batchFailed, batchSucceeded}) {
+		t.Fatalf("keep going should run remaining commands, got %v", got)
+	}
+}
+
+func TestBatchRunnerParallelInterruptsOnFailure(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	commands := map[string]string{"slow": "sleep 5", "fail": "exit 1", "later": "true"}
+	var (
+		mu      sync.Mutex
+		started []string
+	)
+	runner := batchRunner{
+		Executor: executor.NewExecutor(plugins.NewRegistry(), nil),
+		Jobs:     2,
+		Start: func(index int, req *executor.ScriptRequest) {
+			mu.Lock()
+			defer mu.Unlock()
+			started = append(started, strings.Join(req.CommandPath, " "))
+		},
+	}
+
+	results := runner.Run(context.Background(), newBatchItems(commands, "slow", "fail", "later"))
+	want := []batchStatus{batchInterrupted, batchFailed, batchSkipped}
+	if got := batchStatuses(results); !slices.Equal(got, want) {
+		t.Fatalf("unexpected statuses: %v", got)
+	}
+	if len(started) != 2 {
+		t.Fatalf("expected two commands to start in parallel, got %v", started)
+	}
+	if results[0].Duration.Seconds() >= 5 {
+		t.Fatalf("slow command should be interrupted, took %s", results[0].Duration)
+	}
+}
+
+func TestPrefixWriterKeepsLinesTogether(t *testing.T) {
+	var out bytes.Buffer
+	var mu sync.Mutex
+	a := newPrefixWriter(&out, &mu, "a")
+	b := newPrefixWriter(&out, &mu, "b")
+	a.Write([]byte("one "))
+	b.Write([]byte("two\nthree"))
+	a.Write([]byte("done\n"))
+	b.Flush()
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || !strings.HasSuffix(lines[0], "two") || !strings.HasSuffix(lines[1], "one done") || !strings.HasSuffix(lines[2], "three") {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+}
+
+func TestBatchFormSubmit(t *testing.T) {
+	options := []*menuOption{
+		{Path: []string{"lint"}, Target: config.Target{Path: []string{"lint"}}},
+		{Path: []string{"db", "drop"}, Target: config.Target{Path: []string{"db", "drop"}, Confirm: config.ConfirmSpec{Enabled: true}}},
+	}
+	form := newBatchForm(newGuardTestCommand("", false), options, "")
+	if len(form.fields) != 4 || form.fields[3].Expected != "db drop" {
+		t.Fatalf("expected a confirm field for db drop, got %+v", form.fields)
+	}
+	form.fields[3].Value = "db drop"
+
+	jobs, keepGoing, err := form.submitBatch()
+	if err != nil || jobs != 1 || keepGoing {
+		t.Fatalf("expected sequential stop-on-failure defaults, got %d %v %v", jobs, keepGoing, err)
+	}
+
+	form.focus = 0
+	form.cycle(1)
+	form.fields[1].Value = "3"
+	form.focus = 2
+	form.cycle(-1)
+	jobs, keepGoing, err = form.submitBatch()
+	if err != nil || jobs != 3 || !keepGoing {
+		t.Fatalf("expected parallel keep-going with 3 jobs, got %d %v %v", jobs, keepGoing, err)
+	}
+
+	form.fields[1].Value = "0"
+	if _, _, err := form.submitBatch(); err == nil {
+		t.Fatalf("expected invalid job count to fail")
+	}
+	form.fields[1].Value = ""
+	form.fields[3].Value = "lint"
+	if _, _, err := form.submitBatch(); err != errGuardDeclined {
+		t.Fatalf("expected guard mismatch, got %v", err)
+	}
+}
+
+func TestPrepareBatchDefersShellVarsAndRejectsRequiredParams(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	root := &cobra.Command{Use: "alpen"}
+	root.PersistentFlags().String("config", "", "")
+	root.PersistentFlags().String("environment", "", "")
+	root.PersistentFlags().Bool("dry-run", false, "")
+	child := &cobra.Command{Use: "run"}
+	root.AddCommand(child)
+
+	marker := filepath.Join(t.TempDir(), "evaluated")
+	build := config.Target{
+		Path:    []string{"build"},
+		Command: "echo {{ .vars.rev }}",
+		Vars:    map[string]config.VarSpec{"rev": {Sh: "touch " + marker + " && echo abc"}},
+	}
+	items, err := prepareBatch(child, Dependencies{}, []config.Target{build})
+	if err != nil {
+		t.Fatalf("prepare failed: %v", err)
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Fatalf("sh vars must not run before the confirmation")
+	}
+	if err := renderBatch(child, items); err != nil || items[0].Request.Command != "echo abc" {
+		t.Fatalf("render failed: %q %v", items[0].Request.Command, err)
+	}
+
+	deploy := config.Target{Path: []string{"deploy"}, Command: "echo {{ .param.tag }}", Params: map[string]config.ParamSpec{"tag": {}}}
+	if _, err := prepareBatch(child, Dependencies{}, []config.Target{build, deploy}); err == nil || !strings.Contains(err.Error(), "tag") {
+		t.Fatalf("expected required param error, got %v", err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/alpen/alpen-cli/internal/config"
+	"github.com/alpen/alpen-cli/internal/i18n"
 	"github.com/alpen/alpen-cli/internal/ui"
 )
 
@@ -16,7 +17,7 @@ func renderDiagnostics(writer io.Writer, diags []config.Diagnostic) {
 	}
 
 	fmt.Fprintln(writer, "")
-	ui.Warning(writer, "检测到 %d 项配置提示: ", len(diags))
+	ui.Warning(writer, i18n.T("diagnostics.title"), len(diags))
 	for _, diag := range diags {
 		level := strings.ToLower(strings.TrimSpace(diag.Level))
 		switch level {
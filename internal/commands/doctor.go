@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alpen/alpen-cli/internal/config"
+	"github.com/alpen/alpen-cli/internal/i18n"
+	"github.com/alpen/alpen-cli/internal/prereq"
+	"github.com/alpen/alpen-cli/internal/ui"
+)
+
+// NewDoctorCommand 创建 doctor 命令，一次性检查全部命令的平台与前置条件
+func NewDoctorCommand(deps Dependencies) *cobra.Command {
+	return &cobra.Command{
+		Use:           "doctor",
+		Short:         i18n.T("doctor.short"),
+		Long:          i18n.T("doctor.long"),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if deps.Loader == nil {
+				return i18n.Errorf("common.loader_missing")
+			}
+			cfg, _, err := loadCommandConfig(cmd, deps)
+			if err != nil {
+				return err
+			}
+			writer := cmd.OutOrStdout()
+			ui.MenuTitle(writer, i18n.T("doctor.title"))
+			failed := renderDoctorReport(writer, cfg, requirementChecker(deps))
+			if failed > 0 {
+				return wrapReportedError(i18n.Errorf("doctor.failed", failed))
+			}
+			return nil
+		},
+	}
+}
+
+// renderDoctorReport 输出每条命令的检查结果，返回不满足条件的命令数
+func renderDoctorReport(writer io.Writer, cfg *config.Config, checker *prereq.Checker) int {
+	var passed, skipped, failed int
+	for _, target := range cfg.Targets() {
+		report := checker.Check(target)
+		switch {
+		case !report.Supported():
+			skipped++
+			fmt.Fprintf(writer, "  %s %s %s\n", ui.Gray("-"), ui.Gray(target.Label()), ui.Gray(i18n.T("doctor.skipped")+report.Platform))
+		case report.OK():
+			passed++
+			fmt.Fprintf(writer, "  %s %s\n", ui.Green(ui.Icons().Done), target.Label())
+		default:
+			failed++
+			fmt.Fprintf(writer, "  %s %s\n", ui.Yellow(ui.Icons().Failed), target.Label())
+			for _, problem := range report.Problems() {
+				fmt.Fprintf(writer, "      %s\n", ui.Yellow(problem))
+			}
+		}
+	}
+	fmt.Fprintln(writer, "")
+	summary := i18n.T("doctor.summary", passed, failed, skipped)
+	if failed > 0 {
+		ui.Warning(writer, "%s", summary)
+	} else {
+		ui.Success(writer, "%s", summary)
+	}
+	return failed
+}
+
+// requirementChecker 返回按当前版本检查前置条件的检查器
+func requirementChecker(deps Dependencies) *prereq.Checker {
+	return prereq.New(deps.Version)
+}
+
+// requirementPlanStep 返回 --dry-run 执行计划中的前置条件检查步骤，未声明条件时为空
+func requirementPlanStep(deps Dependencies, target config.Target) string {
+	if len(target.Platforms) == 0 && target.Requires.IsEmpty() && target.MinAlpenVersion == "" {
+		return ""
+	}
+	report := requirementChecker(deps).Check(target)
+	if report.OK() {
+		return i18n.T("doctor.plan.ok")
+	}
+	return i18n.T("doctor.plan.fail", report.Summary())
+}
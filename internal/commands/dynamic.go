@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"bufio"
 	"fmt"
 	"io"
 	"strings"
@@ -9,6 +10,8 @@ import (
 
 	"github.com/alpen/alpen-cli/internal/config"
 	"github.com/alpen/alpen-cli/internal/executor"
+	"github.com/alpen/alpen-cli/internal/i18n"
+	"github.com/alpen/alpen-cli/internal/templating"
 	"github.com/alpen/alpen-cli/internal/ui"
 )
 
@@ -19,22 +22,49 @@ func RegisterDynamicCommands(root *cobra.Command, deps Dependencies, cfg *config
 	if cfg == nil || cfg.Commands == nil {
 		return nil
 	}
+	groups := map[string]*cobra.Command{}
 	for _, name := range cfg.SortedCommandNames() {
-		spec := cfg.Commands[name]
-		cmd := buildTopLevelCommand(name, spec, deps)
-		replaceCommand(root, cmd)
+		cmd := buildTopLevelCommand(cfg, name, deps)
+		parent := root
+		if namespace, _ := config.SplitQualifiedName(name); namespace != "" {
+			if groups[namespace] == nil {
+				groups[namespace] = buildNamespaceCommand(namespace)
+				replaceCommand(root, groups[namespace])
+			}
+			parent = groups[namespace]
+		}
+		replaceCommand(parent, cmd)
 	}
 	return nil
 }
 
-func buildTopLevelCommand(name string, spec config.CommandSpec, deps Dependencies) *cobra.Command {
+// buildNamespaceCommand 创建命名空间对应的分组命令，其下挂载该配置中的全部命令
+func buildNamespaceCommand(namespace string) *cobra.Command {
+	return &cobra.Command{
+		Use:           namespace,
+		Short:         i18n.T("dynamic.namespace.short", namespace),
+		Long:          i18n.T("dynamic.namespace.long", namespace, namespace),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Annotations: map[string]string{
+			annotationDynamic: "true",
+		},
+		RunE: func(c *cobra.Command, _ []string) error {
+			return c.Help()
+		},
+	}
+}
+
+func buildTopLevelCommand(cfg *config.Config, name string, deps Dependencies) *cobra.Command {
+	spec := cfg.Commands[name]
 	description := strings.TrimSpace(spec.Description)
 	if description == "" {
-		description = fmt.Sprintf("命令 %s", name)
+		description = i18n.T("config.label.command", name)
 	}
 
+	_, shortName := config.SplitQualifiedName(name)
 	cmd := &cobra.Command{
-		Use:           name,
+		Use:           shortName,
 		Short:         description,
 		Long:          buildCommandLongDescription(name, spec, description),
 		SilenceUsage:  true,
@@ -50,18 +80,20 @@ func buildTopLevelCommand(name string, spec config.CommandSpec, deps Dependencie
 		cmd.Example = examples
 	}
 
-	cmd.AddCommand(buildCommandListSubcommand(name, spec))
+	cmd.AddCommand(buildCommandListSubcommand(name, spec, deps))
 
-	defaultCommand := strings.TrimSpace(spec.Command)
-	if defaultCommand == "" {
+	target := targetFor(cfg, []string{name})
+	// 不适用于当前平台的命令不在帮助中展示，但仍可显式调用以获得明确的错误提示
+	cmd.Hidden = !requirementChecker(deps).Check(target).Supported()
+	if target.Command == "" {
 		cmd.RunE = func(c *cobra.Command, _ []string) error {
 			return c.Help()
 		}
 	} else {
-		commandLine := defaultCommand
 		cmd.RunE = func(c *cobra.Command, _ []string) error {
-			return executeDynamic(c, deps, []string{name}, commandLine, c.Flags().Args())
+			return executeDynamic(c, deps, target, c.Flags().Args())
 		}
+		addParamFlag(cmd, target)
 	}
 
 	for _, actionName := range spec.SortedActionNames() {
@@ -69,19 +101,19 @@ func buildTopLevelCommand(name string, spec config.CommandSpec, deps Dependencie
 			continue
 		}
 		action := spec.Actions[actionName]
-		child := buildActionCommand(name, actionName, action, deps)
+		child := buildActionCommand(targetFor(cfg, []string{name, actionName}), action, deps)
 		cmd.AddCommand(child)
 	}
 
 	return cmd
 }
 
-func buildActionCommand(parent string, name string, spec config.ActionSpec, deps Dependencies) *cobra.Command {
-	description := strings.TrimSpace(spec.Description)
+func buildActionCommand(target config.Target, spec config.ActionSpec, deps Dependencies) *cobra.Command {
+	parent, name := target.Path[0], target.Path[1]
+	description := target.Description
 	if description == "" {
-		description = fmt.Sprintf("命令 %s %s", parent, name)
+		description = i18n.T("dynamic.action_description", parent, name)
 	}
-	commandLine := strings.TrimSpace(spec.Command)
 
 	cmd := &cobra.Command{
 		Use:           name,
@@ -90,7 +122,7 @@ func buildActionCommand(parent string, name string, spec config.ActionSpec, deps
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		RunE: func(c *cobra.Command, _ []string) error {
-			return executeDynamic(c, deps, []string{parent, name}, commandLine, c.Flags().Args())
+			return executeDynamic(c, deps, target, c.Flags().Args())
 		},
 	}
 
@@ -98,29 +130,48 @@ func buildActionCommand(parent string, name string, spec config.ActionSpec, deps
 		cmd.Aliases = []string{alias}
 	}
 	cmd.Example = buildActionExamples(parent, name)
+	cmd.Hidden = !requirementChecker(deps).Check(target).Supported()
+	addParamFlag(cmd, target)
 	return cmd
 }
 
-func executeDynamic(cmd *cobra.Command, deps Dependencies, path []string, command string, args []string) error {
+func executeDynamic(cmd *cobra.Command, deps Dependencies, target config.Target, args []string) error {
 	if deps.Executor == nil {
-		return fmt.Errorf("执行器未初始化")
+		return i18n.Errorf("common.executor_missing")
 	}
-	if strings.TrimSpace(command) == "" {
-		return fmt.Errorf("命令 %s 未配置可执行脚本", strings.Join(path, " "))
+	if target.Command == "" {
+		return i18n.Errorf("batch.no_script", target.Label())
 	}
 
-	displayName := strings.Join(path, " ")
 	writer := cmd.OutOrStdout()
 
-	ui.BeginExecution(writer, displayName)
-
-	req := executor.ScriptRequest{
-		CommandPath: path,
-		Command:     command,
-		ExtraArgs:   args,
+	params, err := paramsFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	// 先以预览方式渲染：参数或模板有误时在执行确认之前报错，sh 变量在确认通过后才求值
+	preview, err := previewScriptRequest(cmd, target, args, params)
+	if err != nil {
+		return err
+	}
+	if preview.DryRun {
+		return showPlan(cmd, deps, target, preview, writer)
+	}
+	if err := requirementChecker(deps).Check(target).Err(target.Label()); err != nil {
+		return err
+	}
+	if err := confirmGuard(cmd, target, preview.Environment, bufio.NewReader(cmd.InOrStdin()), writer); err != nil {
+		return err
+	}
+	req, err := buildScriptRequest(cmd, target, args, params)
+	if err != nil {
+		return err
 	}
 
+	ui.BeginExecution(writer, target.Label())
+
 	result, err := deps.Executor.Execute(cmd.Context(), req)
+	recordHistory(deps.Executor.Redactor(), target, args, params)
 
 	ui.EndExecution(writer)
 	if err != nil {
@@ -131,6 +182,101 @@ func executeDynamic(cmd *cobra.Command, deps Dependencies, path []string, comman
 	return nil
 }
 
+// buildScriptRequest 将执行目标与当前的 --config/--environment 组合为执行请求，
+// 会执行模板中引用的 sh 变量，须在执行确认之后调用；--dry-run 时与 previewScriptRequest 相同
+func buildScriptRequest(cmd *cobra.Command, target config.Target, args []string, params map[string]string) (executor.ScriptRequest, error) {
+	return newScriptRequest(cmd, target, args, params, dryRunRequested(cmd))
+}
+
+// previewScriptRequest 构建不执行 sh 变量的执行请求，sh 变量以 <sh: 命令> 占位，用于执行计划与执行确认之前的校验
+func previewScriptRequest(cmd *cobra.Command, target config.Target, args []string, params map[string]string) (executor.ScriptRequest, error) {
+	return newScriptRequest(cmd, target, args, params, true)
+}
+
+func newScriptRequest(cmd *cobra.Command, target config.Target, args []string, params map[string]string, preview bool) (executor.ScriptRequest, error) {
+	configPath, envName, err := resolveConfigFlags(cmd)
+	if err != nil {
+		return executor.ScriptRequest{}, err
+	}
+	rendered, err := templating.Render(target, templating.Input{Args: args, Params: params, Preview: preview})
+	if err != nil {
+		return executor.ScriptRequest{}, err
+	}
+	if rendered.ArgsConsumed {
+		// command 已通过 {{ .args }} 使用了额外参数，不再追加到末尾
+		args = nil
+	}
+	if _, composite := activeConfigSet(cmd); composite {
+		// 组合加载多个配置时记录命令实际所在的配置文件
+		configPath = target.Origin.File
+	}
+	return executor.ScriptRequest{
+		CommandPath:  target.Path,
+		Command:      rendered.Command,
+		BaseEnv:      rendered.Env,
+		ExtraArgs:    args,
+		WorkingDir:   rendered.WorkDir,
+		Interpreter:  target.Interpreter,
+		Inline:       target.Inline,
+		ConfigPath:   configPath,
+		Environment:  envName,
+		Source:       target.Origin.String(),
+		FieldOrigins: target.FieldOrigins,
+		DryRun:       dryRunRequested(cmd),
+	}, nil
+}
+
+// dryRunRequested 判断是否指定了全局 --dry-run
+func dryRunRequested(cmd *cobra.Command) bool {
+	dryRun, _ := cmd.Root().PersistentFlags().GetBool("dry-run")
+	return dryRun
+}
+
+// showPlan 输出 --dry-run 的执行计划，执行确认只展示不询问
+func showPlan(cmd *cobra.Command, deps Dependencies, target config.Target, req executor.ScriptRequest, writer io.Writer) error {
+	plan, err := deps.Executor.Plan(cmd.Context(), req)
+	if err != nil {
+		return err
+	}
+	if step := guardPlanStep(cmd, target, req.Environment); step != "" {
+		plan.Steps = append([]string{step}, plan.Steps...)
+	}
+	if step := requirementPlanStep(deps, target); step != "" {
+		plan.Steps = append([]string{step}, plan.Steps...)
+	}
+	executor.WritePlan(writer, plan)
+	return nil
+}
+
+// paramFlagName 为动态命令传入 params 的 flag 名称
+const paramFlagName = "param"
+
+// addParamFlag 为声明了 params 的动态命令注册 --param
+func addParamFlag(cmd *cobra.Command, target config.Target) {
+	if len(target.Params) == 0 {
+		return
+	}
+	cmd.Flags().StringArray(paramFlagName, nil, i18n.T("dynamic.flag.param"))
+}
+
+// paramsFromFlags 读取 --param 传入的参数
+func paramsFromFlags(cmd *cobra.Command) (map[string]string, error) {
+	if cmd.Flags().Lookup(paramFlagName) == nil {
+		return nil, nil
+	}
+	values, err := cmd.Flags().GetStringArray(paramFlagName)
+	if err != nil {
+		return nil, err
+	}
+	return templating.ParseParamFlags(values)
+}
+
+// targetFor 返回配置中已知命令路径的执行目标
+func targetFor(cfg *config.Config, path []string) config.Target {
+	target, _ := cfg.ResolveTarget(path)
+	return target
+}
+
 func replaceCommand(root *cobra.Command, cmd *cobra.Command) {
 	for _, existing := range root.Commands() {
 		if existing.Name() == cmd.Name() {
@@ -152,15 +298,15 @@ func buildCommandLongDescription(name string, spec config.CommandSpec, fallback
 		sections = append(sections, fallback)
 	}
 	if alias := strings.TrimSpace(spec.Alias); alias != "" {
-		sections = append(sections, fmt.Sprintf("别名：%s", alias))
+		sections = append(sections, i18n.T("dynamic.aliases", alias))
 	}
 	if strings.TrimSpace(spec.Command) != "" {
-		sections = append(sections, "默认执行：直接运行 `alpen "+name+"` 即可触发")
+		sections = append(sections, i18n.T("dynamic.default_run", name))
 	}
 	if len(spec.Actions) > 0 {
-		sections = append(sections, "子命令：使用 `alpen "+name+" <action>` 调用具体动作")
+		sections = append(sections, i18n.T("dynamic.actions", name))
 	}
-	sections = append(sections, "参数透传：使用 `--` 之后追加原生命令参数，例如 `alpen "+name+" -- --flag value`")
+	sections = append(sections, i18n.T("dynamic.passthrough", name))
 	return strings.Join(sections, "\n\n")
 }
 
@@ -184,10 +330,10 @@ func buildActionLongDescription(parent, name string, spec config.ActionSpec, fal
 		sections = append(sections, fallback)
 	}
 	if alias := strings.TrimSpace(spec.Alias); alias != "" {
-		sections = append(sections, fmt.Sprintf("别名：%s", alias))
+		sections = append(sections, i18n.T("dynamic.aliases", alias))
 	}
-	sections = append(sections, fmt.Sprintf("调用方式：`alpen %s %s`", parent, name))
-	sections = append(sections, fmt.Sprintf("参数透传：`alpen %s %s -- --flag value`", parent, name))
+	sections = append(sections, i18n.T("dynamic.action_usage", parent, name))
+	sections = append(sections, i18n.T("dynamic.action_passthrough", parent, name))
 	return strings.Join(sections, "\n\n")
 }
 
@@ -195,25 +341,28 @@ func buildActionExamples(parent, name string) string {
 	return fmt.Sprintf("  alpen %s %s\n  alpen %s %s -- --flag value", parent, name, parent, name)
 }
 
-func buildCommandListSubcommand(name string, spec config.CommandSpec) *cobra.Command {
+func buildCommandListSubcommand(name string, spec config.CommandSpec, deps Dependencies) *cobra.Command {
 	return &cobra.Command{
 		Use:           "ls",
-		Short:         fmt.Sprintf("查看 %s 下的命令列表", name),
-		Long:          fmt.Sprintf("列出命令 %s 及其子命令的名称、别名与简介。", name),
+		Short:         i18n.T("dynamic.list.short", name),
+		Long:          i18n.T("dynamic.list.long", name),
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, _ []string) error {
-			return runCommandList(cmd.OutOrStdout(), name, spec)
+			return runCommandList(cmd.OutOrStdout(), name, spec, listAvailability{checker: requirementChecker(deps), showAll: true})
 		},
 	}
 }
 
-func runCommandList(writer io.Writer, name string, spec config.CommandSpec) error {
+func runCommandList(writer io.Writer, name string, spec config.CommandSpec, avail listAvailability) error {
 	ui.MenuTitle(writer, name)
 	fmt.Fprintln(writer, "")
 
-	commandWidth := displayWidth(strings.TrimSpace(name))
-	actionWidth := maxActionNameWidth(spec)
-	writeCommandSummary(writer, name, spec, commandWidth, actionWidth)
+	layout := listLayout{
+		commandWidth: displayWidth(strings.TrimSpace(name)),
+		actionWidth:  maxActionNameWidth(spec),
+		columns:      ui.Columns(writer),
+	}
+	writeCommandSummary(writer, name, spec, layout, avail)
 	return nil
 }
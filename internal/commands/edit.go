@@ -0,0 +1,203 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/spf13/cobra"
+
+	"github.com/alpen/alpen-cli/internal/config"
+	"github.com/alpen/alpen-cli/internal/i18n"
+	"github.com/alpen/alpen-cli/internal/scripts"
+	"github.com/alpen/alpen-cli/internal/ui"
+)
+
+// waitFlags 为图形界面编辑器等待文件关闭后再退出的参数，未指定时自动加上，以便退出后校验配置
+var waitFlags = map[string]string{
+	"code":          "--wait",
+	"code-insiders": "--wait",
+	"codium":        "--wait",
+	"cursor":        "--wait",
+	"subl":          "--wait",
+	"zed":           "--wait",
+}
+
+// NewEditCommand 创建 edit 命令，用 $VISUAL 或 $EDITOR 打开定义命令的文件并定位到所在行
+func NewEditCommand(deps Dependencies) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "edit [namespace] <command> [action]",
+		Short:         i18n.T("edit.short"),
+		Long:          i18n.T("edit.long"),
+		Example:       "  alpen edit deploy release\n  alpen edit sys up\n  alpen edit --script deploy release",
+		Args:          cobra.RangeArgs(1, 3),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runEdit(cmd, deps, args)
+		},
+	}
+	cmd.Flags().Bool("script", false, i18n.T("edit.flag.script"))
+	return cmd
+}
+
+func runEdit(cmd *cobra.Command, deps Dependencies, args []string) error {
+	cfg, err := loadEditableConfig(cmd, deps)
+	if err != nil {
+		return err
+	}
+	name, actionName, err := resolveCommandPath(cfg, args)
+	if err != nil {
+		return err
+	}
+	script, _ := cmd.Flags().GetBool("script")
+	location, err := editLocation(cfg, name, actionName, script)
+	if err != nil {
+		return err
+	}
+	file := location.File
+	original, err := os.ReadFile(file)
+	if err != nil {
+		return i18n.Errorf("edit.read_failed", file, err)
+	}
+
+	editor := editorCommand()
+	argv := editorArgs(editor, file, location.Line)
+	writer := cmd.OutOrStdout()
+	if dryRun, _ := cmd.Root().PersistentFlags().GetBool("dry-run"); dryRun {
+		ui.Info(writer, i18n.T("edit.dry_run_editor"), strings.Join(append([]string{editor[0]}, argv...), " "))
+		return nil
+	}
+	for {
+		process := exec.Command(editor[0], argv...)
+		process.Stdin, process.Stdout, process.Stderr = cmd.InOrStdin(), writer, cmd.ErrOrStderr()
+		if err := process.Run(); err != nil {
+			return i18n.Errorf("edit.editor_failed", editor[0], err)
+		}
+
+		current, err := os.ReadFile(file)
+		if err != nil {
+			return i18n.Errorf("edit.read_failed", file, err)
+		}
+		if bytes.Equal(current, original) {
+			ui.Info(writer, i18n.T("edit.unchanged"), file)
+			return nil
+		}
+		// 脚本文件不属于配置，修改后无需重新加载
+		if !location.Config {
+			ui.Success(writer, i18n.T("edit.saved"), file)
+			return nil
+		}
+		_, _, err = loadCommandConfig(cmd, deps)
+		if err == nil {
+			renderDiagnostics(writer, deps.Loader.Diagnostics())
+			ui.Success(writer, i18n.T("edit.validated"), file)
+			return nil
+		}
+		err = i18n.Errorf("edit.invalid", file, err)
+		// 配置无效时之后的 alpen edit 也无法加载配置，在终端中询问是否重新编辑
+		if !isInteractiveInput(cmd.InOrStdin()) {
+			return err
+		}
+		ui.Error(writer, "%v", err)
+		reopen := true
+		if askErr := survey.AskOne(&survey.Confirm{Message: i18n.T("edit.reopen"), Default: true}, &reopen, surveyOptions()...); askErr != nil || !reopen {
+			return err
+		}
+	}
+}
+
+// editTarget 为 alpen edit 打开的文件，Line 未知时为 0，Config 表示文件为配置文件
+type editTarget struct {
+	File   string
+	Line   int
+	Config bool
+}
+
+// editLocation 返回要打开的位置；script 为 true 时返回命令调用的脚本文件，
+// 内联脚本返回 script 字段在配置文件中的位置
+func editLocation(cfg *config.Config, name, actionName string, script bool) (editTarget, error) {
+	label := strings.TrimSpace(name + " " + actionName)
+	spec := cfg.Commands[name]
+	origin := spec.Origin
+	if actionName != "" {
+		origin = spec.Actions[actionName].Origin
+	}
+	if !script {
+		if origin.File == "" {
+			return editTarget{}, i18n.Errorf("rm.source_unknown", label)
+		}
+		return editTarget{File: filepath.FromSlash(origin.File), Line: origin.Line, Config: true}, nil
+	}
+
+	path := []string{name}
+	if actionName != "" {
+		path = append(path, actionName)
+	}
+	target, _ := cfg.ResolveTarget(path)
+	if strings.TrimSpace(target.Command) == "" {
+		return editTarget{}, i18n.Errorf("batch.no_script", label)
+	}
+	if target.Inline {
+		source, ok := target.FieldOrigins[config.OriginCommand]
+		if !ok || source.File == "" {
+			return editTarget{}, i18n.Errorf("rm.source_unknown", label)
+		}
+		return editTarget{File: filepath.FromSlash(source.File), Line: source.Line, Config: true}, nil
+	}
+	workDir := target.WorkDir
+	if config.HasTemplate(workDir) {
+		workDir = ""
+	}
+	file, ok, err := scripts.ResolveCommandScript(target.Command, workDir)
+	if err != nil {
+		return editTarget{}, err
+	}
+	if !ok {
+		return editTarget{}, i18n.Errorf("edit.not_script", label, target.Command)
+	}
+	return editTarget{File: file}, nil
+}
+
+// editorCommand 返回 $VISUAL、$EDITOR 指定的编辑器及其参数，均未设置时使用平台默认的编辑器
+func editorCommand() []string {
+	for _, key := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(key)); len(fields) > 0 {
+			return fields
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"notepad"}
+	}
+	return []string{"vi"}
+}
+
+// editorArgs 按编辑器的约定生成打开 file 并定位到 line 的参数，不含编辑器本身
+func editorArgs(editor []string, file string, line int) []string {
+	program := strings.TrimSuffix(strings.ToLower(filepath.Base(editor[0])), ".exe")
+	args := slices.Clone(editor[1:])
+	if flag, ok := waitFlags[program]; ok && !slices.Contains(args, flag) && !slices.Contains(args, "-w") {
+		args = append(args, flag)
+	}
+	if line <= 0 {
+		return append(args, file)
+	}
+	position := fmt.Sprintf("%s:%d", file, line)
+	switch program {
+	case "code", "code-insiders", "codium", "cursor":
+		return append(args, "--goto", position)
+	case "subl", "zed", "hx", "helix":
+		return append(args, position)
+	case "notepad":
+		return append(args, file)
+	default:
+		// vi、vim、nvim、nano、emacs、micro 等终端编辑器均支持 +<行号>
+		return append(args, fmt.Sprintf("+%d", line), file)
+	}
+}
@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/alpen/alpen-cli/internal/config"
+)
+
+func TestEditorArgsFollowEditorConventions(t *testing.T) {
+	cases := []struct {
+		editor []string
+		line   int
+		want   []string
+	}{
+		{[]string{"vim"}, 12, []string{"+12", "demo.yaml"}},
+		{[]string{"/usr/bin/nano"}, 0, []string{"demo.yaml"}},
+		{[]string{"code"}, 3, []string{"--wait", "--goto", "demo.yaml:3"}},
+		{[]string{"code", "-w"}, 3, []string{"-w", "--goto", "demo.yaml:3"}},
+		{[]string{"subl"}, 7, []string{"--wait", "demo.yaml:7"}},
+		{[]string{"notepad.exe"}, 7, []string{"demo.yaml"}},
+	}
+	for _, tc := range cases {
+		if got := editorArgs(tc.editor, "demo.yaml", tc.line); !slices.Equal(got, tc.want) {
+			t.Errorf("editorArgs(%q, %d) = %q, want %q", tc.editor, tc.line, got, tc.want)
+		}
+	}
+}
+
+func TestEditLocationFindsDefinitionAndScript(t *testing.T) {
+	dir := t.TempDir()
+	moduleDir := filepath.Join(dir, "ops.conf")
+	if err := os.MkdirAll(moduleDir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	script := filepath.Join(dir, "release.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\necho release\n"), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(moduleDir, "base.yaml"), []byte("commands:\n  build:\n    command: make\n"), 0o644); err != nil {
+		t.Fatalf("write base: %v", err)
+	}
+	deploy := `commands:
+  deploy:
+    description: 发布
+    actions:
+      release:
+        alias: rel
+        command: ` + script + ` --fast
+      check:
+        script: |
+          echo check
+`
+	deployFile := filepath.Join(moduleDir, "deploy.yaml")
+	if err := os.WriteFile(deployFile, []byte(deploy), 0o644); err != nil {
+		t.Fatalf("write deploy: %v", err)
+	}
+	cfg, err := config.NewLoader(dir).Load("ops.conf", "")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	name, action, err := resolveCommandPath(cfg, []string{"deploy", "rel"})
+	if err != nil || action != "release" {
+		t.Fatalf("resolve: %s %s %v", name, action, err)
+	}
+	location, err := editLocation(cfg, name, action, false)
+	if err != nil || location.File != deployFile || location.Line != 5 || !location.Config {
+		t.Fatalf("unexpected definition location %+v: %v", location, err)
+	}
+	if location, err = editLocation(cfg, "deploy", "", false); err != nil || location.Line != 2 {
+		t.Fatalf("commands without a default command should be editable, got %+v: %v", location, err)
+	}
+	if location, err = editLocation(cfg, name, action, true); err != nil || location.File != script || location.Config {
+		t.Fatalf("unexpected script location %+v: %v", location, err)
+	}
+	if location, err = editLocation(cfg, "deploy", "check", true); err != nil || location.File != deployFile || location.Line != 9 {
+		t.Fatalf("inline scripts should open the script field, got %+v: %v", location, err)
+	}
+	if _, err := editLocation(cfg, "build", "", true); err == nil {
+		t.Fatalf("expected error for a command that does not invoke a script file")
+	}
+}
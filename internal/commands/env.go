@@ -15,6 +15,7 @@ import (
 
 	"github.com/alpen/alpen-cli/internal/bootstrap"
 	"github.com/alpen/alpen-cli/internal/config"
+	"github.com/alpen/alpen-cli/internal/i18n"
 	"github.com/alpen/alpen-cli/internal/ui"
 )
 
@@ -25,15 +26,15 @@ func NewEnvCommand(deps Dependencies) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:           "env",
 		Aliases:       []string{"environment"},
-		Short:         "选择并激活配置文件",
-		Long:          "列出 ~/.alpen/config 目录中的配置文件，选择后写入状态目录，之后执行的命令都会使用该配置。",
+		Short:         i18n.T("env.short"),
+		Long:          i18n.T("env.long"),
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			return runEnvSelector(cmd, deps)
 		},
 	}
-	cmd.Flags().Bool("reset", false, "重建 ~/.alpen/config 下的示例内容")
+	cmd.Flags().Bool("reset", false, i18n.T("env.flag.reset"))
 	return cmd
 }
 
@@ -55,38 +56,46 @@ func runEnvSelector(cmd *cobra.Command, deps Dependencies) error {
 
 	writer := cmd.OutOrStdout()
 	fmt.Fprintln(writer, "")
-	if strings.TrimSpace(ctx.activePath) != "" {
-		display := filepath.Base(ctx.activePath)
-		if strings.TrimSpace(display) == "" || display == "." {
-			display = ctx.activePath
-		}
-		ui.KeyValue(writer, "当前激活配置", display)
+	if len(ctx.active) > 0 {
+		ui.KeyValue(writer, i18n.T("env.current"), describeActiveSelection(ctx.active))
 	}
 	fmt.Fprintln(writer, "")
 
-	choice, err := promptConfigSelection(ctx.groups, ctx.activePath)
+	choices, err := promptConfigSelection(ctx.groups, ctx.active)
+	var entries []config.ActiveConfig
+	switch {
+	case err != nil:
+	case len(choices) == 1:
+		entries = []config.ActiveConfig{{Path: choices[0].AbsolutePath}}
+	case len(choices) > 1:
+		entries, err = promptNamespaces(choices, ctx.active)
+	}
 	if err != nil {
 		if errors.Is(err, io.EOF) || err.Error() == "interrupt" {
 			fmt.Fprintln(writer, "")
 			fmt.Fprintln(writer, "")
-			fmt.Fprintln(writer, ui.Yellow("    已取消"))
+			fmt.Fprintln(writer, ui.Yellow(i18n.T("env.cancelled")))
 			return nil
 		}
 		return err
 	}
-	if err := persistEnvSelection(choice); err != nil {
+	if len(entries) == 0 {
+		ui.Warning(writer, i18n.T("env.none_selected"))
+		return nil
+	}
+	if err := persistEnvSelection(entries); err != nil {
 		return err
 	}
 
 	envName, _ := cmd.Root().PersistentFlags().GetString("environment")
-	renderSelectionResult(writer, choice, deps.Loader, envName)
+	renderSelectionResult(writer, entries, deps.Loader, envName)
 	return nil
 }
 
 type envSelectionContext struct {
-	options    []configCandidate
-	groups     []configGroup
-	activePath string
+	options []configCandidate
+	groups  []configGroup
+	active  []config.ActiveConfig
 }
 
 type configGroup struct {
@@ -102,13 +111,13 @@ func handleEnvReset(cmd *cobra.Command) error {
 		return err
 	}
 	if err := bootstrap.EnsureHomeReadme(filepath.Dir(result.ConfigPath), true); err != nil {
-		ui.Warning(writer, "写入 README 失败: %v", err)
+		ui.Warning(writer, i18n.T("init.readme_failed"), err)
 	}
 	if err := config.SaveActiveConfigPath(result.ConfigPath); err != nil {
 		return err
 	}
 
-	ui.Success(writer, "已重置默认配置路径")
+	ui.Success(writer, i18n.T("env.reset_done"))
 	return nil
 }
 
@@ -133,13 +142,20 @@ func buildEnvSelectionContext(cmd *cobra.Command, deps Dependencies) (*envSelect
 		return &envSelectionContext{}, nil
 	}
 
-	activePath, err := config.LoadActiveConfigPath()
+	active, err := config.LoadActiveConfigs()
 	if err != nil {
 		return nil, err
 	}
-	if strings.TrimSpace(activePath) == "" {
-		activePath = defaultPath
+	if len(active) > 0 {
+		options, groups := buildConfigGroups(candidates)
+		return &envSelectionContext{
+			options: options,
+			groups:  groups,
+			active:  active,
+		}, nil
 	}
+
+	activePath := defaultPath
 	if strings.TrimSpace(activePath) == "" {
 		activePath = configFlagPath
 	}
@@ -152,23 +168,24 @@ func buildEnvSelectionContext(cmd *cobra.Command, deps Dependencies) (*envSelect
 
 	options, groups := buildConfigGroups(candidates)
 	return &envSelectionContext{
-		options:    options,
-		groups:     groups,
-		activePath: activePath,
+		options: options,
+		groups:  groups,
+		active:  []config.ActiveConfig{{Path: activePath}},
 	}, nil
 }
 
-func promptConfigSelection(groups []configGroup, activePath string) (configCandidate, error) {
+// promptConfigSelection 以多选列表展示候选配置，当前激活的配置默认选中
+func promptConfigSelection(groups []configGroup, active []config.ActiveConfig) ([]configCandidate, error) {
 	var options []string
 	var mapping []configCandidate
 	var metas []selectOptionTemplateMeta
-	defaultIndex := -1
+	var defaults []int
 	previousGroup := ""
 	for _, group := range groups {
 		for _, option := range group.Options {
-			isActive := samePath(activePath, option.AbsolutePath)
+			entry, isActive := findActiveConfig(active, option.AbsolutePath)
 			if isActive {
-				defaultIndex = len(options)
+				defaults = append(defaults, len(options))
 			}
 			isNewGroup := previousGroup != group.Title
 			metaGroup := group.Title
@@ -183,6 +200,7 @@ func promptConfigSelection(groups []configGroup, activePath string) (configCandi
 				Group:     metaGroup,
 				Name:      option.DisplayName,
 				Path:      option.AbsolutePath,
+				Namespace: entry.Namespace,
 				Active:    isActive,
 				First:     first,
 				GapBefore: gap,
@@ -194,29 +212,81 @@ func promptConfigSelection(groups []configGroup, activePath string) (configCandi
 		}
 	}
 	if len(mapping) == 0 {
-		return configCandidate{}, errors.New("无可用配置可供选择")
+		return nil, i18n.Errorf("env.no_choices")
 	}
 	ensureEnvSelectTemplate()
 	cleanupMeta := setSelectOptionMeta(metas)
 	defer cleanupMeta()
 
-	prompt := &survey.Select{
-		Message:  "选择配置文件 (↑/↓ 导航 | / 搜索 | Enter 确认)",
+	prompt := &survey.MultiSelect{
+		Message:  i18n.T("env.select_prompt"),
 		Options:  options,
+		Default:  defaults,
 		PageSize: minInt(15, len(options)),
 		Filter:   buildEnvFilter(mapping, metas),
 	}
-	if defaultIndex >= 0 {
-		prompt.Default = defaultIndex
+	var selected []int
+	if err := survey.AskOne(prompt, &selected, surveyOptions()...); err != nil {
+		return nil, err
 	}
-	var selected int
-	if err := survey.AskOne(prompt, &selected); err != nil {
-		return configCandidate{}, err
+	choices := make([]configCandidate, 0, len(selected))
+	for _, index := range selected {
+		if index < 0 || index >= len(mapping) {
+			return nil, i18n.Errorf("env.index_out_of_range")
+		}
+		choices = append(choices, mapping[index])
 	}
-	if selected < 0 || selected >= len(mapping) {
-		return configCandidate{}, fmt.Errorf("选择索引超出范围")
+	return choices, nil
+}
+
+// promptNamespaces 为选中的多个配置逐一输入命名空间，默认沿用已激活时的命名空间
+func promptNamespaces(choices []configCandidate, active []config.ActiveConfig) ([]config.ActiveConfig, error) {
+	entries := make([]config.ActiveConfig, 0, len(choices))
+	for _, choice := range choices {
+		namespace := defaultNamespace(choice.AbsolutePath)
+		if entry, ok := findActiveConfig(active, choice.AbsolutePath); ok {
+			namespace = entry.Namespace
+		}
+		prompt := &survey.Input{
+			Message: i18n.T("env.namespace_prompt", choice.DisplayName),
+			Default: namespace,
+		}
+		if err := survey.AskOne(prompt, &namespace, surveyOptions()...); err != nil {
+			return nil, err
+		}
+		entries = append(entries, config.ActiveConfig{Path: choice.AbsolutePath, Namespace: strings.TrimSpace(namespace)})
 	}
-	return mapping[selected], nil
+	return entries, nil
+}
+
+// defaultNamespace 以去掉扩展名的文件名作为默认命名空间，例如 work.yaml → work
+func defaultNamespace(path string) string {
+	name := filepath.Base(path)
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+func findActiveConfig(active []config.ActiveConfig, path string) (config.ActiveConfig, bool) {
+	for _, entry := range active {
+		if samePath(entry.Path, path) {
+			return entry, true
+		}
+	}
+	return config.ActiveConfig{}, false
+}
+
+func describeActiveSelection(entries []config.ActiveConfig) string {
+	labels := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		label := filepath.Base(entry.Path)
+		if strings.TrimSpace(label) == "" || label == "." {
+			label = entry.Path
+		}
+		if entry.Namespace != "" {
+			label = fmt.Sprintf("%s %s %s", label, ui.Icons().Arrow, entry.Namespace)
+		}
+		labels = append(labels, label)
+	}
+	return strings.Join(labels, i18n.T("common.comma_sep"))
 }
 
 func buildEnvFilter(options []configCandidate, metas []selectOptionTemplateMeta) func(filter string, value string, index int) bool {
@@ -314,27 +384,33 @@ func collectEnvPrefixTokens(candidate configCandidate, metas []selectOptionTempl
 	return tokens
 }
 
-func persistEnvSelection(chosen configCandidate) error {
-	return config.SaveActiveConfigPath(chosen.AbsolutePath)
+func persistEnvSelection(entries []config.ActiveConfig) error {
+	return config.SaveActiveConfigs(entries)
 }
 
-func renderSelectionResult(writer io.Writer, chosen configCandidate, loader *config.Loader, envName string) {
-	ui.KeyValueSuccess(writer, "已激活配置", filepath.Base(chosen.AbsolutePath))
+func renderSelectionResult(writer io.Writer, entries []config.ActiveConfig, loader *config.Loader, envName string) {
+	ui.KeyValueSuccess(writer, i18n.T("env.activated"), describeActiveSelection(entries))
 	if loader == nil {
 		return
 	}
-	cfg, err := loader.Load(chosen.AbsolutePath, envName)
+	var cfg *config.Config
+	var err error
+	if config.IsComposite(entries) {
+		cfg, err = loader.LoadActive(entries, envName)
+	} else {
+		cfg, err = loader.Load(entries[0].Path, envName)
+	}
 	if err != nil {
 		fmt.Fprintln(writer, "")
-		ui.Warning(writer, "解析配置时出现问题: %v", err)
+		ui.Warning(writer, i18n.T("env.parse_problem"), err)
 		return
 	}
 	renderDiagnostics(writer, cfg.Diagnostics)
 }
 
 func renderNoConfigHint(writer io.Writer) {
-	ui.Warning(writer, "未在目录中找到可用的配置文件")
-	ui.Info(writer, "可执行 %s 创建默认配置", ui.Highlight("alpen init"))
+	ui.Warning(writer, i18n.T("env.none_found"))
+	ui.Info(writer, i18n.T("env.init_hint"), ui.Highlight("alpen init"))
 }
 
 func currentConfigPath(cmd *cobra.Command, deps Dependencies) (string, error) {
@@ -401,6 +477,9 @@ func listConfigFiles(dirs []string) ([]configCandidate, error) {
 
 func isConfigFile(entry fs.DirEntry) bool {
 	name := strings.ToLower(entry.Name())
+	if config.IsReservedConfigName(name) {
+		return false
+	}
 	return strings.HasSuffix(name, ".yaml") || strings.HasSuffix(name, ".yml")
 }
 
@@ -0,0 +1,319 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alpen/alpen-cli/internal/config"
+	"github.com/alpen/alpen-cli/internal/i18n"
+	"github.com/alpen/alpen-cli/internal/redact"
+	"github.com/alpen/alpen-cli/internal/scripts"
+	"github.com/alpen/alpen-cli/internal/ui"
+)
+
+// inheritedFields 为子命令未配置时从顶层命令继承的字段
+var inheritedFields = []string{"notify", "confirm", "protected_environments", "workdir", config.OriginEnv, config.OriginVars, config.OriginParams}
+
+// NewExplainCommand 创建 explain 命令，展示命令合并后的定义及其来源
+func NewExplainCommand(deps Dependencies) *cobra.Command {
+	return &cobra.Command{
+		Use:           "explain [namespace] <command> [action]",
+		Aliases:       []string{"which"},
+		Short:         i18n.T("explain.short"),
+		Long:          i18n.T("explain.long"),
+		Example:       "  alpen explain system update\n  alpen which sys up\n  alpen explain work deploy rollback",
+		Args:          cobra.RangeArgs(1, 3),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if deps.Loader == nil {
+				return i18n.Errorf("common.loader_missing")
+			}
+			cfg, _, err := loadCommandConfig(cmd, deps)
+			if err != nil {
+				return err
+			}
+			path, aliases, err := resolveExplainPath(cfg, args)
+			if err != nil {
+				return err
+			}
+			target, _ := cfg.ResolveTarget(path)
+			writer := cmd.OutOrStdout()
+			renderExplainHeader(writer, target, aliases)
+			renderExplainDefinition(writer, target, explainRedactor(deps))
+			renderExplainLayers(writer, cfg.Commands[path[0]], path)
+			renderExplainScript(writer, target, deps)
+			return nil
+		},
+	}
+}
+
+// resolveExplainPath 将命令名或别名解析为命令路径，同时返回经过的别名映射
+func resolveExplainPath(cfg *config.Config, args []string) ([]string, []string, error) {
+	if len(args) > 1 && slices.Contains(cfg.Namespaces, args[0]) {
+		args = append([]string{config.QualifiedName(args[0], args[1])}, args[2:]...)
+	}
+	if len(args) > 2 {
+		return nil, nil, i18n.Errorf("explain.too_many_args", strings.Join(args, " "))
+	}
+	var aliases []string
+	name, alias, ok := lookupCommandName(cfg, args[0])
+	if !ok {
+		return nil, nil, i18n.Errorf("explain.not_found", args[0])
+	}
+	if alias {
+		aliases = append(aliases, fmt.Sprintf("%s %s %s", args[0], ui.Icons().Arrow, name))
+	}
+	path := []string{name}
+	spec := cfg.Commands[name]
+	if len(args) == 1 {
+		if strings.TrimSpace(spec.Command) == "" {
+			return nil, nil, i18n.Errorf("explain.no_default", name, strings.Join(spec.SortedActionNames(), ", "))
+		}
+		return path, aliases, nil
+	}
+	actionName, alias, ok := lookupActionName(spec, args[1])
+	if !ok {
+		return nil, nil, i18n.Errorf("explain.action_not_found", name, args[1])
+	}
+	if alias {
+		aliases = append(aliases, fmt.Sprintf("%s %s %s", args[1], ui.Icons().Arrow, actionName))
+	}
+	return append(path, actionName), aliases, nil
+}
+
+func lookupCommandName(cfg *config.Config, token string) (string, bool, bool) {
+	if _, ok := cfg.Commands[token]; ok {
+		return token, false, true
+	}
+	namespace, short := config.SplitQualifiedName(token)
+	for _, name := range cfg.SortedCommandNames() {
+		if ns, _ := config.SplitQualifiedName(name); ns == namespace && strings.TrimSpace(cfg.Commands[name].Alias) == short {
+			return name, true, true
+		}
+	}
+	return "", false, false
+}
+
+func lookupActionName(spec config.CommandSpec, token string) (string, bool, bool) {
+	if _, ok := spec.Actions[token]; ok {
+		return token, false, true
+	}
+	for _, name := range spec.SortedActionNames() {
+		if strings.TrimSpace(spec.Actions[name].Alias) == token {
+			return name, true, true
+		}
+	}
+	return "", false, false
+}
+
+func explainRedactor(deps Dependencies) *redact.Redactor {
+	if deps.Settings == nil {
+		return nil
+	}
+	redactor, err := redact.New(redact.Options{Keys: deps.Settings.Redact.Env, Patterns: deps.Settings.Redact.Patterns})
+	if err != nil {
+		return nil
+	}
+	return redactor
+}
+
+func renderExplainHeader(writer io.Writer, target config.Target, aliases []string) {
+	ui.MenuTitle(writer, "alpen "+target.Label())
+	if target.Description != "" {
+		ui.KeyValue(writer, i18n.T("ui.detail.description"), target.Description)
+	}
+	if len(aliases) > 0 {
+		ui.KeyValue(writer, i18n.T("explain.aliases"), strings.Join(aliases, i18n.T("common.comma_sep")))
+	}
+	ui.KeyValue(writer, i18n.T("explain.origin"), target.Origin.String())
+}
+
+func renderExplainDefinition(writer io.Writer, target config.Target, redactor *redact.Redactor) {
+	fmt.Fprintln(writer, "")
+	ui.Title(writer, i18n.T("explain.definition"))
+	origin := func(key string) string {
+		if source, ok := target.FieldOrigins[key]; ok {
+			return "  " + ui.Gray(ui.Icons().Origin+" "+source.String())
+		}
+		return ""
+	}
+	if target.Inline {
+		ui.KeyValue(writer, "script", origin(config.OriginCommand))
+		for _, line := range strings.Split(strings.TrimRight(target.Command, "\n"), "\n") {
+			fmt.Fprintf(writer, "    %s\n", ui.Cyan(line))
+		}
+	} else {
+		ui.KeyValue(writer, "command", target.Command+origin(config.OriginCommand))
+	}
+	if len(target.Interpreter) > 0 {
+		ui.KeyValue(writer, "interpreter", strings.Join(target.Interpreter, " "))
+	}
+	if target.WorkDir != "" {
+		ui.KeyValue(writer, "workdir", target.WorkDir+origin(config.OriginWorkDir))
+	}
+	if target.Confirm.Enabled {
+		ui.KeyValue(writer, "confirm", firstNonEmpty(target.Confirm.Message, "true"))
+	}
+	if len(target.Protected) > 0 {
+		ui.KeyValue(writer, "protected_environments", strings.Join(target.Protected, ", "))
+	}
+	if target.Notify != nil {
+		ui.KeyValue(writer, "notify", strings.Join(target.Notify.Triggers(), ", "))
+	}
+	if len(target.Env) > 0 {
+		fmt.Fprintln(writer, ui.Gray("  env:"))
+		masked := redactor.Env(target.Env)
+		for _, key := range sortedMapKeys(target.Env) {
+			fmt.Fprintf(writer, "    %s=%s%s\n", ui.Yellow(key), masked[key], origin(config.OriginEnv+key))
+		}
+	}
+	if len(target.Vars) > 0 {
+		fmt.Fprintln(writer, ui.Gray("  vars:"))
+		for _, name := range sortedMapKeys(target.Vars) {
+			fmt.Fprintf(writer, "    %s %s%s\n", ui.Yellow(name), ui.Gray("("+target.Vars[name].Source()+")"), origin(config.OriginVars+name))
+		}
+	}
+	if len(target.Params) > 0 {
+		fmt.Fprintln(writer, ui.Gray("  params:"))
+		for _, name := range sortedMapKeys(target.Params) {
+			spec := target.Params[name]
+			detail := i18n.T("ui.detail.required")
+			if !spec.Required() {
+				detail = i18n.T("form.default", *spec.Default)
+			}
+			fmt.Fprintf(writer, "    %s %s%s\n", ui.Yellow(name), ui.Gray("("+detail+")"), origin(config.OriginParams+name))
+		}
+	}
+}
+
+// renderExplainLayers 按加载顺序列出参与合并的配置文件，子命令会先列出可继承字段所在的顶层命令层
+func renderExplainLayers(writer io.Writer, spec config.CommandSpec, path []string) {
+	fmt.Fprintln(writer, "")
+	ui.Title(writer, i18n.T("explain.layers"))
+	type labeledLayer struct {
+		scope string
+		layer config.Layer
+	}
+	var layers []labeledLayer
+	for _, layer := range spec.Layers {
+		if len(path) > 1 {
+			layer.Fields = filterInherited(layer.Fields)
+			if len(layer.Fields) == 0 {
+				continue
+			}
+		}
+		layers = append(layers, labeledLayer{scope: i18n.T("config.label.command", path[0]), layer: layer})
+	}
+	if len(path) > 1 {
+		for _, layer := range spec.Actions[path[1]].Layers {
+			layers = append(layers, labeledLayer{scope: i18n.T("explain.action_scope", path[1]), layer: layer})
+		}
+	}
+	seen := map[string]bool{}
+	for i, item := range layers {
+		var added, overridden []string
+		for _, field := range item.layer.Fields {
+			if seen[field] {
+				overridden = append(overridden, field)
+			} else {
+				added = append(added, field)
+			}
+			seen[field] = true
+		}
+		fmt.Fprintf(writer, "  %d. %s %s\n", i+1, item.layer.Source.String(), ui.Gray("["+item.scope+"]"))
+		if len(added) > 0 {
+			fmt.Fprintf(writer, "     %s %s\n", ui.Gray(i18n.T("explain.set")), strings.Join(added, ", "))
+		}
+		if len(overridden) > 0 {
+			fmt.Fprintf(writer, "     %s %s\n", ui.Yellow(i18n.T("explain.overrode")), strings.Join(overridden, ", "))
+		}
+	}
+}
+
+func filterInherited(fields []string) []string {
+	var result []string
+	for _, field := range fields {
+		for _, inherited := range inheritedFields {
+			if field == inherited || (strings.HasSuffix(inherited, ".") && strings.HasPrefix(field, inherited)) {
+				result = append(result, field)
+				break
+			}
+		}
+	}
+	return result
+}
+
+func renderExplainScript(writer io.Writer, target config.Target, deps Dependencies) {
+	fmt.Fprintln(writer, "")
+	ui.Title(writer, i18n.T("executor.plan.script"))
+	if target.Inline {
+		ui.KeyValue(writer, i18n.T("explain.type"), i18n.T("explain.type.inline"))
+		return
+	}
+	if config.HasTemplate(target.Command) {
+		ui.Info(writer, i18n.T("explain.template_hint"), ui.Highlight("--dry-run"))
+	}
+	workDir := target.WorkDir
+	if config.HasTemplate(workDir) {
+		workDir = ""
+	}
+	path, ok, err := scripts.ResolveCommandScript(target.Command, workDir)
+	if err != nil {
+		ui.Error(writer, "%v", err)
+		return
+	}
+	if !ok {
+		ui.KeyValue(writer, i18n.T("explain.type"), i18n.T("explain.type.command"))
+		return
+	}
+	ui.KeyValue(writer, i18n.T("explain.path"), path)
+	info, err := os.Stat(path)
+	if err != nil {
+		ui.Error(writer, i18n.T("explain.read_failed"), err)
+		return
+	}
+	ui.KeyValue(writer, i18n.T("explain.mode"), info.Mode().Perm().String())
+	if err := scripts.VerifyExecutable(path); err != nil {
+		ui.Error(writer, "%v", err)
+	} else {
+		ui.KeyValueSuccess(writer, i18n.T("explain.check"), i18n.T("explain.check_ok"))
+	}
+	policy := ""
+	if deps.Settings != nil {
+		policy = deps.Settings.Scripts.TrustPolicy
+	}
+	checker, err := scripts.NewTrustChecker(policy)
+	if err != nil {
+		return
+	}
+	if status, err := checker.Status(path); err != nil {
+		ui.Error(writer, i18n.T("explain.trust_read_failed"), err)
+	} else if status != "" {
+		ui.KeyValue(writer, i18n.T("explain.trust"), status.Label())
+	}
+}
+
+func sortedMapKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alpen/alpen-cli/internal/config"
+)
+
+func TestExplainResolvesAliasesAndLayers(t *testing.T) {
+	dir := t.TempDir()
+	base := `commands:
+  system:
+    alias: sys
+    notify: {command: "true"}
+    actions:
+      update:
+        alias: up
+        command: echo base
+`
+	overlay := `commands:
+  system:
+    env:
+      LOG_LEVEL: debug
+    actions:
+      update:
+        command: echo dev
+`
+	if err := os.WriteFile(filepath.Join(dir, "demo.yaml"), []byte(base), 0o644); err != nil {
+		t.Fatalf("write base failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "demo.dev.yaml"), []byte(overlay), 0o644); err != nil {
+		t.Fatalf("write overlay failed: %v", err)
+	}
+	cfg, err := config.NewLoader(dir).Load("demo.yaml", "dev")
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+
+	path, aliases, err := resolveExplainPath(cfg, []string{"sys", "up"})
+	if err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+	if strings.Join(path, " ") != "system update" || strings.Join(aliases, ",") != "sys → system,up → update" {
+		t.Fatalf("unexpected resolution: %v %v", path, aliases)
+	}
+	if _, _, err := resolveExplainPath(cfg, []string{"system"}); err == nil {
+		t.Fatalf("expected error for command without default command")
+	}
+
+	var buf bytes.Buffer
+	renderExplainLayers(&buf, cfg.Commands["system"], path)
+	output := buf.String()
+	for _, want := range []string{"notify", "env.LOG_LEVEL", "覆盖", "@env:dev"} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("layers output missing %q:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "alias") && strings.Index(output, "alias") < strings.Index(output, "子命令 update") {
+		t.Fatalf("non-inherited command fields should be hidden for actions:\n%s", output)
+	}
+}
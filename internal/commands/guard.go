@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/alpen/alpen-cli/internal/config"
+	"github.com/alpen/alpen-cli/internal/i18n"
+	"github.com/alpen/alpen-cli/internal/ui"
+)
+
+// errGuardDeclined 表示用户未通过执行确认
+var errGuardDeclined = i18n.NewError("guard.declined")
+
+// confirmGuard 在执行需要确认或处于受保护环境的命令前，要求输入环境名或命令名
+func confirmGuard(cmd *cobra.Command, target config.Target, environment string, reader *bufio.Reader, writer io.Writer) error {
+	required, protectedEnv := target.GuardFor(environment)
+	if !required {
+		return nil
+	}
+	assumeYes, _ := cmd.Root().PersistentFlags().GetBool("yes")
+	if assumeYes {
+		ui.Info(writer, i18n.T("guard.skipped_by_yes"), ui.Highlight(target.Label()))
+		return nil
+	}
+	if !isInteractiveInput(cmd.InOrStdin()) {
+		return i18n.Errorf("guard.not_terminal", target.Label())
+	}
+
+	expected := target.Label()
+	fmt.Fprintln(writer, "")
+	if protectedEnv {
+		ui.Warning(writer, i18n.T("guard.protected_env"), ui.Highlight(environment), ui.Highlight(target.Label()))
+		expected = environment
+	} else {
+		ui.Warning(writer, i18n.T("guard.required"), ui.Highlight(target.Label()))
+	}
+	if message := strings.TrimSpace(target.Confirm.Message); message != "" {
+		ui.Info(writer, "%s", message)
+	}
+	if protectedEnv {
+		ui.Prompt(writer, i18n.T("guard.prompt_env", expected))
+	} else {
+		ui.Prompt(writer, i18n.T("guard.prompt_command", expected))
+	}
+
+	line, err := reader.ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		fmt.Fprintln(writer, "")
+		return errGuardDeclined
+	}
+	if strings.TrimSpace(line) != expected {
+		return errGuardDeclined
+	}
+	return nil
+}
+
+// guardPlanStep 返回执行计划中的确认步骤描述，无需确认时返回空字符串
+func guardPlanStep(cmd *cobra.Command, target config.Target, environment string) string {
+	required, protectedEnv := target.GuardFor(environment)
+	if !required {
+		return ""
+	}
+	if assumeYes, _ := cmd.Root().PersistentFlags().GetBool("yes"); assumeYes {
+		return i18n.T("guard.plan.skipped")
+	}
+	if protectedEnv {
+		return i18n.T("guard.plan.env", environment)
+	}
+	return i18n.T("guard.plan.command", target.Label())
+}
+
+// isInteractiveInput 判断输入是否来自终端
+func isInteractiveInput(input io.Reader) bool {
+	file, ok := input.(*os.File)
+	if !ok {
+		return false
+	}
+	return term.IsTerminal(int(file.Fd()))
+}
@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alpen/alpen-cli/internal/config"
+)
+
+func newGuardTestCommand(input string, yes bool) *cobra.Command {
+	root := &cobra.Command{Use: "alpen"}
+	root.PersistentFlags().Bool("yes", yes, "")
+	child := &cobra.Command{Use: "drop"}
+	root.AddCommand(child)
+	child.SetIn(strings.NewReader(input))
+	return child
+}
+
+func TestConfirmGuardSkipsUnguardedTargets(t *testing.T) {
+	cmd := newGuardTestCommand("", false)
+	target := config.Target{Path: []string{"db", "status"}, Protected: []string{"prod"}}
+	if err := confirmGuard(cmd, target, "dev", bufio.NewReader(cmd.InOrStdin()), &bytes.Buffer{}); err != nil {
+		t.Fatalf("expected unguarded target to pass, got %v", err)
+	}
+}
+
+func TestConfirmGuardRefusesNonInteractiveInput(t *testing.T) {
+	cmd := newGuardTestCommand("prod\n", false)
+	target := config.Target{Path: []string{"db", "drop"}, Protected: []string{"prod"}}
+	err := confirmGuard(cmd, target, "prod", bufio.NewReader(cmd.InOrStdin()), &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "--yes") {
+		t.Fatalf("expected refusal mentioning --yes, got %v", err)
+	}
+}
+
+func TestConfirmGuardAcceptsYesFlag(t *testing.T) {
+	cmd := newGuardTestCommand("", true)
+	target := config.Target{Path: []string{"db", "drop"}, Confirm: config.ConfirmSpec{Enabled: true}}
+	if err := confirmGuard(cmd, target, "", bufio.NewReader(cmd.InOrStdin()), &bytes.Buffer{}); err != nil {
+		t.Fatalf("expected --yes to bypass confirmation, got %v", err)
+	}
+}
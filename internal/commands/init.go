@@ -10,6 +10,7 @@ import (
 
 	"github.com/alpen/alpen-cli/internal/bootstrap"
 	"github.com/alpen/alpen-cli/internal/config"
+	"github.com/alpen/alpen-cli/internal/i18n"
 	"github.com/alpen/alpen-cli/internal/ui"
 )
 
@@ -17,13 +18,13 @@ import (
 func NewInitCommand(deps Dependencies) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "init",
-		Short: "初始化默认命令配置文件",
+		Short: i18n.T("init.short"),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			force, _ := cmd.Flags().GetBool("force")
 			return initHomeConfig(force, cmd)
 		},
 	}
-	cmd.Flags().Bool("force", false, "若文件已存在则覆盖")
+	cmd.Flags().Bool("force", false, i18n.T("init.flag.force"))
 	return cmd
 }
 
@@ -43,31 +44,31 @@ func initHomeConfig(force bool, cmd *cobra.Command) error {
 	}
 
 	if err := bootstrap.EnsureHomeReadme(filepath.Dir(result.ConfigPath), force); err != nil {
-		ui.Warning(writer, "写入 README 失败: %v", err)
+		ui.Warning(writer, i18n.T("init.readme_failed"), err)
 	}
 
 	if err := config.SaveActiveConfigPath(result.ConfigPath); err != nil {
-		ui.Warning(writer, "无法记录激活配置: %v", err)
+		ui.Warning(writer, i18n.T("init.activate_failed"), err)
 	}
 
 	// 输出美化的结果
 	fmt.Fprintln(writer, "")
 	if alreadyExists {
-		ui.Info(writer, "检测到示例配置已存在，未执行覆盖操作")
+		ui.Info(writer, i18n.T("init.exists"))
 		fmt.Fprintln(writer, "")
-		ui.KeyValue(writer, "配置文件", result.ConfigPath)
-		ui.KeyValue(writer, "脚本目录", result.ScriptsDir)
+		ui.KeyValue(writer, i18n.T("init.config_file"), result.ConfigPath)
+		ui.KeyValue(writer, i18n.T("init.scripts_dir"), result.ScriptsDir)
 		fmt.Fprintln(writer, "")
-		ui.Info(writer, "如需覆盖示例配置，可使用 %s", ui.Highlight("alpen init --force"))
+		ui.Info(writer, i18n.T("init.force_hint"), ui.Highlight("alpen init --force"))
 	} else {
-		ui.Success(writer, "已在用户目录生成示例配置")
+		ui.Success(writer, i18n.T("init.created"))
 		fmt.Fprintln(writer, "")
-		ui.KeyValueSuccess(writer, "配置文件", result.ConfigPath)
-		ui.KeyValueSuccess(writer, "脚本目录", result.ScriptsDir)
+		ui.KeyValueSuccess(writer, i18n.T("init.config_file"), result.ConfigPath)
+		ui.KeyValueSuccess(writer, i18n.T("init.scripts_dir"), result.ScriptsDir)
 		fmt.Fprintln(writer, "")
 	}
 
-	ui.Info(writer, "后续可通过 %s 或 %s 浏览命令", ui.Highlight("alpen ls"), ui.Highlight("alpen ui"))
+	ui.Info(writer, i18n.T("init.next"), ui.Highlight("alpen ls"), ui.Highlight("alpen ui"))
 	fmt.Fprintln(writer, "")
 	return nil
 }
@@ -6,43 +6,42 @@ import (
 	"io"
 	"os"
 	"strings"
-	"unicode/utf8"
 
 	"github.com/spf13/cobra"
 
 	"github.com/alpen/alpen-cli/internal/config"
+	"github.com/alpen/alpen-cli/internal/i18n"
+	"github.com/alpen/alpen-cli/internal/prereq"
 	"github.com/alpen/alpen-cli/internal/ui"
+	"github.com/alpen/alpen-cli/internal/ui/width"
 )
 
 // NewListCommand 创建 ls 子命令，用于浏览配置文件中定义的命令
 func NewListCommand(deps Dependencies) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:           "ls",
-		Short:         "查看配置中定义的命令列表",
-		Long:          "列出当前配置文件中的所有顶层命令，展示名称、别名与简介，便于快速浏览自定义命令。",
+		Short:         i18n.T("list.short"),
+		Long:          i18n.T("list.long"),
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runRootList(cmd, deps)
 		},
 	}
+	cmd.Flags().Bool("all", false, i18n.T("list.flag.all"))
 	return cmd
 }
 
 func runRootList(cmd *cobra.Command, deps Dependencies) error {
 	if deps.Loader == nil {
-		return fmt.Errorf("配置加载器未初始化")
+		return i18n.Errorf("common.loader_missing")
 	}
-	configPath, envName, err := resolveConfigFlags(cmd)
-	if err != nil {
-		return err
-	}
-	cfg, err := deps.Loader.Load(configPath, envName)
+	cfg, configPath, err := loadCommandConfig(cmd, deps)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
 			writer := cmd.OutOrStdout()
-			ui.Warning(writer, "未检测到命令配置文件 %s", ui.Highlight(configPath))
-			ui.Info(writer, "执行 %s 可生成示例结构", ui.Highlight("alpen init"))
+			ui.Warning(writer, i18n.T("root.no_config"), ui.Highlight(configPath))
+			ui.Info(writer, i18n.T("list.init_hint"), ui.Highlight("alpen init"))
 			return nil
 		}
 		return err
@@ -51,91 +50,216 @@ func runRootList(cmd *cobra.Command, deps Dependencies) error {
 	writer := cmd.OutOrStdout()
 
 	if cfg == nil || len(cfg.Commands) == 0 {
-		ui.Warning(writer, "当前配置未包含自定义命令")
+		ui.Warning(writer, i18n.T("list.empty"))
 		return nil
 	}
 
 	renderDiagnostics(writer, cfg.Diagnostics)
 
-	renderConfigSummary(writer, cfg, configPath)
+	showAll, _ := cmd.Flags().GetBool("all")
+	renderConfigSummary(writer, cfg, listAvailability{checker: requirementChecker(deps), showAll: showAll})
 	return nil
 }
 
-func renderConfigSummary(writer io.Writer, cfg *config.Config, configPath string) {
+// listAvailability 决定 ls 中的条目是否展示，以及缺少前置条件时的提示
+type listAvailability struct {
+	checker *prereq.Checker
+	// showAll 为 true 时同时展示不适用于当前平台的命令
+	showAll bool
+}
+
+// check 返回条目是否展示与不可用原因（可用时为空）
+func (a listAvailability) check(target config.Target) (bool, string) {
+	if a.checker == nil {
+		return true, ""
+	}
+	report := a.checker.Check(target)
+	if !report.Supported() && !a.showAll {
+		return false, ""
+	}
+	return true, report.Summary()
+}
+
+func renderConfigSummary(writer io.Writer, cfg *config.Config, avail listAvailability) {
 	// 输出命令列表标题（带分隔线）
-	ui.MenuTitle(writer, "命令列表")
+	ui.MenuTitle(writer, i18n.T("list.title"))
 
-	printCommandSummaries(writer, cfg)
+	printCommandSummaries(writer, cfg, avail)
 	fmt.Fprintln(writer, "")
 }
 
-func printCommandSummaries(writer io.Writer, cfg *config.Config) {
+func printCommandSummaries(writer io.Writer, cfg *config.Config, avail listAvailability) {
 	names := cfg.SortedCommandNames()
 	if len(names) == 0 {
 		return
 	}
-	maxCommandWidth := maxNameWidth(names)
-	maxActionWidth := 0
+	layout := listLayout{commandWidth: maxNameWidth(names), columns: ui.Columns(writer)}
 	for _, name := range names {
 		spec := cfg.Commands[name]
-		if w := maxActionNameWidth(spec); w > maxActionWidth {
-			maxActionWidth = w
+		if w := maxActionNameWidth(spec); w > layout.actionWidth {
+			layout.actionWidth = w
 		}
 	}
-	for idx, name := range names {
-		spec := cfg.Commands[name]
-		writeCommandSummary(writer, name, spec, maxCommandWidth, maxActionWidth)
-		if idx < len(names)-1 {
+	printed := false
+	for _, name := range names {
+		var buf strings.Builder
+		if !writeCommandSummary(&buf, name, cfg.Commands[name], layout, avail) {
+			continue
+		}
+		if printed {
 			fmt.Fprintln(writer, "")
 		}
+		fmt.Fprint(writer, buf.String())
+		printed = true
 	}
 }
 
-// writeCommandSummary 输出单个命令及其子命令的简介，缩进展示层级结构
-func writeCommandSummary(writer io.Writer, name string, spec config.CommandSpec, commandWidth int, actionWidth int) {
-	fmt.Fprintln(writer, formatEntry("  ", name, spec.Alias, spec.Description, commandWidth))
-	if len(spec.Actions) == 0 {
-		return
-	}
+// listLayout 为 ls 的排版参数
+type listLayout struct {
+	// commandWidth 与 actionWidth 为命令名与子命令名列的显示宽度
+	commandWidth int
+	actionWidth  int
+	// columns 为终端宽度，超出时说明文字折行，0 表示不限制
+	columns int
+}
+
+const (
+	// maxDescriptionLines 为说明文字折行后最多显示的行数，超出部分截断
+	maxDescriptionLines = 3
+	// minDescriptionWidth 为说明列的最小宽度，终端过窄时仍按该宽度折行
+	minDescriptionWidth = 20
+)
+
+// writeCommandSummary 输出单个命令及其子命令的简介，缩进展示层级结构，
+// 命令与全部子命令均被隐藏时不输出并返回 false
+func writeCommandSummary(writer io.Writer, name string, spec config.CommandSpec, layout listLayout, avail listAvailability) bool {
+	var lines []string
 	for _, actionName := range spec.SortedActionNames() {
 		if actionName == "ls" {
 			continue
 		}
 		action := spec.Actions[actionName]
-		fmt.Fprintln(writer, formatEntry("    ", actionName, action.Alias, action.Description, actionWidth))
+		visible, unavailable := avail.check(spec.ActionTarget(name, actionName, action))
+		if visible {
+			lines = append(lines, formatEntry("    ", actionName, action.Alias, action.Description, layout.actionWidth, layout.columns, unavailable))
+		}
 	}
+	visible, unavailable := avail.check(spec.Target(name))
+	if !visible && len(lines) == 0 {
+		return false
+	}
+	fmt.Fprintln(writer, formatEntry("  ", name, spec.Alias, spec.Description, layout.commandWidth, layout.columns, unavailable))
+	for _, line := range lines {
+		fmt.Fprintln(writer, line)
+	}
+	return true
 }
 
-func formatEntry(prefix, name, alias, description string, width int) string {
-	// 命令名用青色显示
-	nameCell := ui.Cyan(padRight(strings.TrimSpace(name), width))
+// metaPart 为名称后的一段附加信息（别名、说明或不可用原因）及其颜色
+type metaPart struct {
+	text  string
+	color func(string) string
+}
+
+// formatEntry 格式化 ls 中的一个条目，unavailable 非空时以灰色显示名称并注明原因；
+// columns 大于 0 时附加信息超出终端宽度的部分折行并与首行对齐，说明过长时截断
+func formatEntry(prefix, name, alias, description string, nameWidth, columns int, unavailable string) string {
+	name = strings.TrimSpace(name)
+	// 命令名用青色显示，不可用时为灰色
+	nameCell := ui.Cyan(padRight(name, nameWidth))
+	if unavailable != "" {
+		nameCell = ui.Gray(padRight(name, nameWidth))
+	}
+
+	separator := " " + ui.Icons().Bullet + " "
+	indent := displayWidth(prefix) + max(nameWidth, displayWidth(name)) + 2 + displayWidth(separator)
+	limit := 0
+	if columns > 0 {
+		limit = max(columns-indent, minDescriptionWidth)
+	}
 
-	var meta []string
+	var parts []metaPart
 	if aliasText := strings.TrimSpace(alias); aliasText != "" {
-		meta = append(meta, ui.Gray(fmt.Sprintf("别名：%s", aliasText)))
+		parts = append(parts, metaPart{i18n.T("list.aliases", aliasText), ui.Gray})
 	}
 	if desc := strings.TrimSpace(description); desc != "" {
-		meta = append(meta, ui.Gray(desc))
+		parts = append(parts, metaPart{clampDescription(desc, limit), ui.Gray})
+	}
+	if unavailable != "" {
+		parts = append(parts, metaPart{i18n.T("list.unavailable") + unavailable, ui.Yellow})
 	}
-	if len(meta) == 0 {
+	if len(parts) == 0 {
 		return fmt.Sprintf("%s%s", prefix, nameCell)
 	}
-	return fmt.Sprintf("%s%s  %s", prefix, nameCell, ui.Gray(" · ")+strings.Join(meta, ui.Gray(" · ")))
+
+	var b strings.Builder
+	b.WriteString(prefix + nameCell + "  " + ui.Gray(separator))
+	for i, line := range layoutMeta(parts, displayWidth(separator), limit) {
+		if i > 0 {
+			b.WriteString("\n" + strings.Repeat(" ", indent))
+		}
+		for j, part := range line {
+			if j > 0 {
+				b.WriteString(ui.Gray(separator))
+			}
+			b.WriteString(part.color(part.text))
+		}
+	}
+	return b.String()
+}
+
+// layoutMeta 将附加信息排成多行：放得下时以分隔符连接在同一行，否则换行并按 limit 折行；limit 为 0 时不折行
+func layoutMeta(parts []metaPart, separatorWidth, limit int) [][]metaPart {
+	if limit <= 0 {
+		return [][]metaPart{parts}
+	}
+	var (
+		lines [][]metaPart
+		line  []metaPart
+		used  int
+	)
+	for _, part := range parts {
+		cells := displayWidth(part.text)
+		if len(line) > 0 {
+			if used+separatorWidth+cells <= limit {
+				line = append(line, part)
+				used += separatorWidth + cells
+				continue
+			}
+			lines = append(lines, line)
+		}
+		chunks := width.Wrap(part.text, limit)
+		for _, chunk := range chunks[:len(chunks)-1] {
+			lines = append(lines, []metaPart{{chunk, part.color}})
+		}
+		last := chunks[len(chunks)-1]
+		line, used = []metaPart{{last, part.color}}, displayWidth(last)
+	}
+	return append(lines, line)
 }
 
-func padRight(value string, width int) string {
-	if width <= 0 {
-		return value
+// clampDescription 将说明限制在按 limit 折行后的 maxDescriptionLines 行以内，超出时以省略号结尾
+func clampDescription(text string, limit int) string {
+	if limit <= 0 {
+		return text
 	}
-	length := displayWidth(value)
-	if length >= width {
-		return value
+	lines := width.Wrap(text, limit)
+	if len(lines) <= maxDescriptionLines {
+		return text
 	}
-	return value + strings.Repeat(" ", width-length)
+	lines = lines[:maxDescriptionLines]
+	last := lines[len(lines)-1]
+	lines[len(lines)-1] = width.Truncate(last+"…", limit)
+	return strings.Join(lines, "\n")
+}
+
+func padRight(value string, columns int) string {
+	return width.PadRight(value, columns)
 }
 
+// displayWidth 返回纯文本在终端中的显示宽度，中文等宽字符占两列
 func displayWidth(value string) int {
-	return utf8.RuneCountInString(value)
+	return width.String(value)
 }
 
 func maxNameWidth(names []string) int {
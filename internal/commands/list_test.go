@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alpen/alpen-cli/internal/ui"
+	"github.com/alpen/alpen-cli/internal/ui/width"
+)
+
+func TestFormatEntryAlignsWideNames(t *testing.T) {
+	if err := ui.SetColorMode(ui.ColorNever); err != nil {
+		t.Fatalf("set color mode: %v", err)
+	}
+	t.Cleanup(func() { _ = ui.SetColorMode(ui.ColorAuto) })
+
+	names := []string{"部署", "db"}
+	nameWidth := maxNameWidth(names)
+	first := formatEntry("  ", "部署", "", "发布到生产环境", nameWidth, 0, "")
+	second := formatEntry("  ", "db", "", "数据库", nameWidth, 0, "")
+	bullet := " " + ui.Icons().Bullet + " "
+	column := func(line string) int {
+		return width.String(line[:strings.Index(line, bullet)])
+	}
+	if column(first) != column(second) {
+		t.Fatalf("descriptions should start in the same column:\n%s\n%s", first, second)
+	}
+}
+
+func TestFormatEntryWrapsAndTruncatesLongDescriptions(t *testing.T) {
+	if err := ui.SetColorMode(ui.ColorNever); err != nil {
+		t.Fatalf("set color mode: %v", err)
+	}
+	t.Cleanup(func() { _ = ui.SetColorMode(ui.ColorAuto) })
+
+	description := strings.Repeat("同步预发布环境的数据库快照并重建索引，", 6)
+	entry := formatEntry("    ", "sync", "s", description, 8, 60, "")
+	lines := strings.Split(entry, "\n")
+	if len(lines) < 2 || len(lines) > maxDescriptionLines+1 {
+		t.Fatalf("unexpected line count %d:\n%s", len(lines), entry)
+	}
+	bullet := " " + ui.Icons().Bullet + " "
+	indent := width.String(lines[0][:strings.Index(lines[0], bullet)+len(bullet)])
+	for i, line := range lines {
+		if width.String(line) > 60 {
+			t.Fatalf("line exceeds 60 columns: %q", line)
+		}
+		if i > 0 && strings.TrimLeft(line, " ") != line[indent:] {
+			t.Fatalf("continuation lines should align with the first line:\n%s", entry)
+		}
+	}
+	if !strings.HasSuffix(lines[len(lines)-1], "…") {
+		t.Fatalf("long descriptions should be truncated:\n%s", entry)
+	}
+
+	if single := formatEntry("    ", "sync", "", description, 8, 0, ""); strings.Contains(single, "\n") {
+		t.Fatalf("descriptions should not wrap without a terminal width")
+	}
+}
@@ -0,0 +1,171 @@
+package commands
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alpen/alpen-cli/internal/config"
+	"github.com/alpen/alpen-cli/internal/history"
+	"github.com/alpen/alpen-cli/internal/i18n"
+	"github.com/alpen/alpen-cli/internal/redact"
+	"github.com/alpen/alpen-cli/internal/ui"
+)
+
+// NewPinCommand 创建 pin 命令，将常用命令置顶到交互菜单
+func NewPinCommand(deps Dependencies) *cobra.Command {
+	return &cobra.Command{
+		Use:           "pin [namespace] <command> [action]",
+		Short:         i18n.T("pin.short"),
+		Long:          i18n.T("pin.long"),
+		Example:       "  alpen pin deploy\n  alpen pin sys up\n  alpen pin",
+		Args:          cobra.MaximumNArgs(3),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := historyPath()
+			if err != nil {
+				return err
+			}
+			writer := cmd.OutOrStdout()
+			if len(args) == 0 {
+				store, err := history.Load(path)
+				if err != nil {
+					return err
+				}
+				if len(store.Pins) == 0 {
+					ui.Info(writer, i18n.T("pin.empty"), ui.Highlight("alpen pin <command>"))
+					return nil
+				}
+				ui.Title(writer, i18n.T("pin.title"))
+				for _, label := range store.Pins {
+					fmt.Fprintf(writer, "  %s %s\n", ui.Cyan(ui.Icons().Pinned), label)
+				}
+				return nil
+			}
+
+			label, err := resolvePinLabel(cmd, deps, args)
+			if err != nil {
+				return err
+			}
+			added := false
+			if err := history.Update(path, func(store *history.Store) error {
+				added = store.Pin(label)
+				return nil
+			}); err != nil {
+				return i18n.Errorf("pin.save_failed", err)
+			}
+			if !added {
+				ui.Info(writer, i18n.T("pin.already"), ui.Highlight(label))
+				return nil
+			}
+			ui.Success(writer, i18n.T("pin.pinned"), ui.Highlight(label))
+			return nil
+		},
+	}
+}
+
+// NewUnpinCommand 创建 unpin 命令，取消命令置顶
+func NewUnpinCommand(deps Dependencies) *cobra.Command {
+	return &cobra.Command{
+		Use:           "unpin [namespace] <command> [action]",
+		Short:         i18n.T("unpin.short"),
+		Long:          i18n.T("unpin.long"),
+		Example:       "  alpen unpin deploy\n  alpen unpin work deploy rollback",
+		Args:          cobra.RangeArgs(1, 3),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := historyPath()
+			if err != nil {
+				return err
+			}
+			labels := []string{strings.Join(args, " ")}
+			if label, err := resolvePinLabel(cmd, deps, args); err == nil {
+				labels = append([]string{label}, labels...)
+			}
+			removed := ""
+			if err := history.Update(path, func(store *history.Store) error {
+				for _, label := range labels {
+					if store.Unpin(label) {
+						removed = label
+						return nil
+					}
+				}
+				return nil
+			}); err != nil {
+				return i18n.Errorf("pin.save_failed", err)
+			}
+			writer := cmd.OutOrStdout()
+			if removed == "" {
+				ui.Info(writer, i18n.T("unpin.not_pinned"), ui.Highlight(labels[0]))
+				return nil
+			}
+			ui.Success(writer, i18n.T("unpin.unpinned"), ui.Highlight(removed))
+			return nil
+		},
+	}
+}
+
+// resolvePinLabel 将命令名或别名解析为以空格连接的完整命令路径
+func resolvePinLabel(cmd *cobra.Command, deps Dependencies, args []string) (string, error) {
+	if deps.Loader == nil {
+		return "", i18n.Errorf("common.loader_missing")
+	}
+	cfg, _, err := loadCommandConfig(cmd, deps)
+	if err != nil {
+		return "", err
+	}
+	path, _, err := resolveExplainPath(cfg, args)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(path, " "), nil
+}
+
+func historyPath() (string, error) {
+	return config.StatePath(history.DefaultFileName)
+}
+
+// recordHistory 记录一次执行及其参数，供交互菜单排序与以上次参数再次执行；
+// 额外参数先经过与审计日志相同的遮蔽，名称敏感或含敏感值的声明参数不保存；记录失败不影响命令本身的结果
+func recordHistory(redactor *redact.Redactor, target config.Target, args []string, params map[string]string) {
+	path, err := historyPath()
+	if err != nil {
+		return
+	}
+	lastArgs := redactor.Args(args)
+	redacted := !slices.Equal(lastArgs, args)
+	lastParams := make(map[string]string, len(params))
+	for name, value := range params {
+		if redactor.IsSensitiveKey(name) || redactor.String(value) != value {
+			redacted = true
+			continue
+		}
+		lastParams[name] = value
+	}
+	_ = history.Update(path, func(store *history.Store) error {
+		if redacted {
+			store.RecordRedacted(target.Label(), lastArgs, lastParams, time.Now())
+		} else {
+			store.Record(target.Label(), lastArgs, lastParams, time.Now())
+		}
+		return nil
+	})
+}
+
+// loadHistory 读取使用历史，读取失败时返回 nil，菜单按名称排序
+func loadHistory() *history.Store {
+	path, err := historyPath()
+	if err != nil {
+		return nil
+	}
+	store, err := history.Load(path)
+	if err != nil {
+		return nil
+	}
+	return store
+}
@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/alpen/alpen-cli/internal/config"
+	"github.com/alpen/alpen-cli/internal/history"
+)
+
+func TestRecordHistoryRedactsSensitiveArguments(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	target := config.Target{Path: []string{"deploy"}}
+	recordHistory(nil, target, []string{"--token=ghp_secret", "--region", "eu"}, map[string]string{"tag": "v1", "api_token": "abc123"})
+
+	path, err := historyPath()
+	if err != nil {
+		t.Fatalf("history path: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read history: %v", err)
+	}
+	if strings.Contains(string(data), "ghp_secret") || strings.Contains(string(data), "abc123") {
+		t.Fatalf("history leaks sensitive values: %s", data)
+	}
+	store, err := history.Load(path)
+	if err != nil {
+		t.Fatalf("load history: %v", err)
+	}
+	entry := store.Commands["deploy"]
+	if !entry.Redacted || !slices.Equal(entry.LastArgs, []string{"--token=***", "--region", "eu"}) || len(entry.LastParams) != 1 || entry.LastParams["tag"] != "v1" {
+		t.Fatalf("unexpected entry: %+v", entry)
+	}
+
+	recordHistory(nil, target, []string{"--region", "us"}, nil)
+	if store, _ = history.Load(path); store.Commands["deploy"].Redacted {
+		t.Fatalf("a later run without sensitive arguments can be rerun directly")
+	}
+}
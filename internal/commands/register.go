@@ -1,13 +1,13 @@
 package commands
 
 import (
-	"log"
-	"os"
+	"log/slog"
 
 	"github.com/spf13/cobra"
 
 	"github.com/alpen/alpen-cli/internal/config"
 	"github.com/alpen/alpen-cli/internal/executor"
+	"github.com/alpen/alpen-cli/internal/logging"
 	"github.com/alpen/alpen-cli/internal/plugins"
 )
 
@@ -16,18 +16,32 @@ type Dependencies struct {
 	Loader   *config.Loader
 	Executor *executor.Executor
 	Registry *plugins.Registry
-	Logger   *log.Logger
+	Logger   *slog.Logger
+	Settings *config.Settings
 	BaseDir  string
+	// Version 为当前 alpen 版本，用于检查 min_alpen_version
+	Version string
 }
 
 // Register 将所有子命令挂载到根命令
 func Register(root *cobra.Command, deps Dependencies) {
 	if deps.Logger == nil {
-		deps.Logger = log.New(os.Stdout, "[alpen] ", log.LstdFlags)
+		deps.Logger = logging.Stderr()
 	}
 	root.AddCommand(NewInitCommand(deps))
 	root.AddCommand(NewEnvCommand(deps))
 	root.AddCommand(NewUICommand(deps))
 	root.AddCommand(NewListCommand(deps))
 	root.AddCommand(NewScriptCommand(deps))
+	root.AddCommand(NewAuditCommand(deps))
+	root.AddCommand(NewStatsCommand(deps))
+	root.AddCommand(NewSecretCommand(deps))
+	root.AddCommand(NewExplainCommand(deps))
+	root.AddCommand(NewDoctorCommand(deps))
+	root.AddCommand(NewPinCommand(deps))
+	root.AddCommand(NewUnpinCommand(deps))
+	root.AddCommand(NewRunCommand(deps))
+	root.AddCommand(NewAddCommand(deps))
+	root.AddCommand(NewRemoveCommand(deps))
+	root.AddCommand(NewEditCommand(deps))
 }
@@ -10,27 +10,30 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/alpen/alpen-cli/internal/i18n"
 	"github.com/alpen/alpen-cli/internal/scripts"
 	"github.com/alpen/alpen-cli/internal/ui"
 )
 
 // NewScriptCommand 创建 script 子命令
-func NewScriptCommand(_ Dependencies) *cobra.Command {
+func NewScriptCommand(deps Dependencies) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:           "script",
-		Short:         "脚本仓库辅助工具",
+		Short:         i18n.T("script.short"),
 		SilenceUsage:  true,
 		SilenceErrors: true,
 	}
 	cmd.AddCommand(newScriptListCommand())
 	cmd.AddCommand(newScriptDoctorCommand())
+	cmd.AddCommand(newScriptTrustCommand(deps))
+	cmd.AddCommand(newScriptVerifyCommand(deps))
 	return cmd
 }
 
 func newScriptListCommand() *cobra.Command {
 	return &cobra.Command{
 		Use:           "ls",
-		Short:         "列出脚本仓库中的脚本",
+		Short:         i18n.T("script.ls.short"),
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, _ []string) error {
@@ -39,7 +42,7 @@ func newScriptListCommand() *cobra.Command {
 				return err
 			}
 			writer := cmd.OutOrStdout()
-			ui.KeyValue(writer, "脚本目录", root)
+			ui.KeyValue(writer, i18n.T("init.scripts_dir"), root)
 
 			var scripts []string
 			err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
@@ -54,14 +57,14 @@ func newScriptListCommand() *cobra.Command {
 				return nil
 			})
 			if errors.Is(err, os.ErrNotExist) {
-				ui.Warning(writer, "尚未创建脚本目录，可执行 %s 初始化", ui.Highlight("alpen init"))
+				ui.Warning(writer, i18n.T("script.no_dir"), ui.Highlight("alpen init"))
 				return nil
 			}
 			if err != nil {
 				return err
 			}
 			if len(scripts) == 0 {
-				ui.Info(writer, "当前目录下没有脚本文件")
+				ui.Info(writer, i18n.T("script.empty"))
 				return nil
 			}
 			for _, s := range scripts {
@@ -75,7 +78,7 @@ func newScriptListCommand() *cobra.Command {
 func newScriptDoctorCommand() *cobra.Command {
 	return &cobra.Command{
 		Use:           "doctor",
-		Short:         "检查脚本仓库安全性",
+		Short:         i18n.T("script.check.short"),
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, _ []string) error {
@@ -84,9 +87,13 @@ func newScriptDoctorCommand() *cobra.Command {
 				return err
 			}
 			writer := cmd.OutOrStdout()
-			ui.KeyValue(writer, "脚本目录", root)
+			ui.KeyValue(writer, i18n.T("init.scripts_dir"), root)
 
-			var issues int
+			store, err := loadTrustStore()
+			if err != nil {
+				return err
+			}
+			var issues, untrusted int
 			err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
 				if err != nil {
 					return err
@@ -101,25 +108,39 @@ func newScriptDoctorCommand() *cobra.Command {
 				rel, _ := filepath.Rel(root, path)
 				if info.Mode()&0o111 == 0 {
 					issues++
-					ui.Warning(writer, "脚本缺少可执行权限: %s", rel)
+					ui.Warning(writer, i18n.T("script.not_executable"), rel)
 				}
 				if err := checkShebang(path); err != nil {
 					issues++
-					ui.Warning(writer, "脚本缺少 Shebang: %s (%v)", rel, err)
+					ui.Warning(writer, i18n.T("script.no_shebang"), rel, err)
+				}
+				status, _, err := store.Check(path)
+				if err != nil {
+					return err
+				}
+				switch status {
+				case scripts.TrustStatusChanged:
+					issues++
+					ui.Warning(writer, i18n.T("script.changed"), rel)
+				case scripts.TrustStatusUntrusted:
+					untrusted++
 				}
 				return nil
 			})
 			if errors.Is(err, os.ErrNotExist) {
-				ui.Warning(writer, "尚未创建脚本目录，可执行 %s 初始化", ui.Highlight("alpen init"))
+				ui.Warning(writer, i18n.T("script.no_dir"), ui.Highlight("alpen init"))
 				return nil
 			}
 			if err != nil {
 				return err
 			}
+			if untrusted > 0 {
+				ui.Info(writer, i18n.T("script.untrusted"), untrusted, ui.Highlight("alpen script trust"))
+			}
 			if issues == 0 {
-				ui.Success(writer, "脚本仓库检查通过")
+				ui.Success(writer, i18n.T("script.check_ok"))
 			} else {
-				ui.Info(writer, "共发现 %d 个可改进项", issues)
+				ui.Info(writer, i18n.T("script.issues"), issues)
 			}
 			return nil
 		},
@@ -147,7 +168,7 @@ func checkShebang(path string) error {
 		return err
 	}
 	if n < 2 || buf[0] != '#' || buf[1] != '!' {
-		return fmt.Errorf("缺少 Shebang")
+		return i18n.Errorf("script.shebang_missing")
 	}
 	return nil
 }
@@ -0,0 +1,155 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alpen/alpen-cli/internal/config"
+	"github.com/alpen/alpen-cli/internal/fsutil"
+	"github.com/alpen/alpen-cli/internal/i18n"
+	"github.com/alpen/alpen-cli/internal/scripts"
+	"github.com/alpen/alpen-cli/internal/ui"
+)
+
+func newScriptTrustCommand(deps Dependencies) *cobra.Command {
+	return &cobra.Command{
+		Use:           "trust [path]",
+		Short:         i18n.T("script.trust.short"),
+		Long:          i18n.T("script.trust.long"),
+		Args:          cobra.MaximumNArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			writer := cmd.OutOrStdout()
+			var paths []string
+			if len(args) == 1 {
+				path, err := filepath.Abs(config.ExpandPath(args[0]))
+				if err != nil {
+					return err
+				}
+				paths = append(paths, path)
+			} else {
+				refs, err := loadTrustedScopeScripts(cmd, deps)
+				if err != nil {
+					return err
+				}
+				for _, ref := range refs {
+					paths = append(paths, ref.Path)
+				}
+			}
+			if len(paths) == 0 {
+				ui.Info(writer, i18n.T("script.no_scripts"))
+				return nil
+			}
+
+			storePath, err := scripts.TrustStorePath()
+			if err != nil {
+				return err
+			}
+			unlock, err := fsutil.AcquireLock(storePath + ".lock")
+			if err != nil {
+				return err
+			}
+			defer unlock()
+			store, err := scripts.LoadTrustStore(storePath)
+			if err != nil {
+				return err
+			}
+			for _, path := range paths {
+				hash, err := scripts.HashFile(path)
+				if err != nil {
+					if errors.Is(err, os.ErrNotExist) {
+						return i18n.Errorf("scripts.not_found", path)
+					}
+					return err
+				}
+				store.Trust(path, hash)
+				ui.Success(writer, i18n.T("script.trusted"), path)
+				ui.KeyValue(writer, "SHA-256", hash)
+			}
+			return store.Save(storePath)
+		},
+	}
+}
+
+func newScriptVerifyCommand(deps Dependencies) *cobra.Command {
+	return &cobra.Command{
+		Use:           "verify",
+		Short:         i18n.T("script.verify.short"),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			writer := cmd.OutOrStdout()
+			refs, err := loadTrustedScopeScripts(cmd, deps)
+			if err != nil {
+				return err
+			}
+			if len(refs) == 0 {
+				ui.Info(writer, i18n.T("script.no_scripts"))
+				return nil
+			}
+			store, err := loadTrustStore()
+			if err != nil {
+				return err
+			}
+
+			var failed, untrusted int
+			for _, ref := range refs {
+				status, _, err := store.Check(ref.Path)
+				if err != nil {
+					return err
+				}
+				label := fmt.Sprintf("%s %s", ref.Path, ui.Gray("("+strings.Join(ref.Commands, ", ")+")"))
+				switch status {
+				case scripts.TrustStatusTrusted:
+					ui.Success(writer, "%s", label)
+				case scripts.TrustStatusUntrusted:
+					untrusted++
+					ui.Warning(writer, "%s: %s", status.Label(), label)
+				default:
+					failed++
+					ui.Error(writer, "%s: %s", status.Label(), label)
+				}
+			}
+			if untrusted > 0 {
+				ui.Info(writer, i18n.T("script.untrusted_hint"), untrusted, ui.Highlight("alpen script trust"))
+			}
+			if failed > 0 {
+				return wrapReportedError(i18n.Errorf("script.verify_failed", failed))
+			}
+			return nil
+		},
+	}
+}
+
+// loadTrustedScopeScripts 返回当前配置引用的、位于 ~/.alpen/config 下的脚本
+func loadTrustedScopeScripts(cmd *cobra.Command, deps Dependencies) ([]scripts.ReferencedScript, error) {
+	cfg, _, err := loadCommandConfig(cmd, deps)
+	if err != nil {
+		return nil, err
+	}
+	root, err := config.ConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	var result []scripts.ReferencedScript
+	for _, ref := range scripts.ReferencedScripts(cfg) {
+		if scripts.IsUnderRoot(ref.Path, root) {
+			result = append(result, ref)
+		}
+	}
+	return result, nil
+}
+
+func loadTrustStore() (*scripts.TrustStore, error) {
+	storePath, err := scripts.TrustStorePath()
+	if err != nil {
+		return nil, err
+	}
+	return scripts.LoadTrustStore(storePath)
+}
@@ -0,0 +1,169 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/alpen/alpen-cli/internal/i18n"
+	"github.com/alpen/alpen-cli/internal/secrets"
+	"github.com/alpen/alpen-cli/internal/ui"
+)
+
+// NewSecretCommand 创建 secret 子命令，管理加密存储的本地密钥
+func NewSecretCommand(deps Dependencies) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "secret",
+		Short:         i18n.T("secret.short"),
+		Long:          i18n.T("secret.long"),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.AddCommand(newSecretSetCommand(deps))
+	cmd.AddCommand(newSecretGetCommand(deps))
+	cmd.AddCommand(newSecretListCommand(deps))
+	cmd.AddCommand(newSecretRemoveCommand(deps))
+	return cmd
+}
+
+func newSecretSetCommand(deps Dependencies) *cobra.Command {
+	return &cobra.Command{
+		Use:           "set <name> [value]",
+		Short:         i18n.T("secret.set.short"),
+		Long:          i18n.T("secret.set.long"),
+		Example:       "  alpen secret set github/token\n  echo -n \"$TOKEN\" | alpen secret set github/token",
+		Args:          cobra.RangeArgs(1, 2),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			if err := secrets.ValidateName(name); err != nil {
+				return err
+			}
+			vault, err := openSecretVault(cmd, deps)
+			if err != nil {
+				return err
+			}
+			var value string
+			if len(args) == 2 {
+				value = args[1]
+			} else {
+				value, err = readSecretValue(cmd, name)
+				if err != nil {
+					return err
+				}
+			}
+			if err := vault.Set(name, value); err != nil {
+				return err
+			}
+			if err := vault.Save(); err != nil {
+				return err
+			}
+			ui.Success(cmd.ErrOrStderr(), i18n.T("secret.saved"), ui.Highlight(name))
+			return nil
+		},
+	}
+}
+
+func newSecretGetCommand(deps Dependencies) *cobra.Command {
+	return &cobra.Command{
+		Use:           "get <name>",
+		Short:         i18n.T("secret.get.short"),
+		Args:          cobra.ExactArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			vault, err := openSecretVault(cmd, deps)
+			if err != nil {
+				return err
+			}
+			value, ok := vault.Get(args[0])
+			if !ok {
+				return i18n.Errorf("secret.not_found", args[0])
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), value)
+			return nil
+		},
+	}
+}
+
+func newSecretListCommand(deps Dependencies) *cobra.Command {
+	return &cobra.Command{
+		Use:           "ls",
+		Short:         i18n.T("secret.ls.short"),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			vault, err := openSecretVault(cmd, deps)
+			if err != nil {
+				return err
+			}
+			writer := cmd.OutOrStdout()
+			names := vault.Names()
+			if len(names) == 0 {
+				ui.Info(writer, i18n.T("secret.empty"), ui.Highlight("alpen secret set <name>"))
+				return nil
+			}
+			for _, name := range names {
+				fmt.Fprintf(writer, "  %s\n", name)
+			}
+			return nil
+		},
+	}
+}
+
+func newSecretRemoveCommand(deps Dependencies) *cobra.Command {
+	return &cobra.Command{
+		Use:           "rm <name>",
+		Short:         i18n.T("secret.rm.short"),
+		Args:          cobra.ExactArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			vault, err := openSecretVault(cmd, deps)
+			if err != nil {
+				return err
+			}
+			if !vault.Delete(args[0]) {
+				return i18n.Errorf("secret.not_found", args[0])
+			}
+			if err := vault.Save(); err != nil {
+				return err
+			}
+			ui.Success(cmd.ErrOrStderr(), i18n.T("secret.removed"), ui.Highlight(args[0]))
+			return nil
+		},
+	}
+}
+
+func openSecretVault(cmd *cobra.Command, deps Dependencies) (*secrets.Vault, error) {
+	keyFile := ""
+	if deps.Settings != nil {
+		keyFile = deps.Settings.Secrets.KeyFile
+	}
+	return secrets.OpenDefault(keyFile, cmd.InOrStdin(), cmd.ErrOrStderr())
+}
+
+// readSecretValue 在终端中隐藏输入密钥值，非终端时读取全部标准输入
+func readSecretValue(cmd *cobra.Command, name string) (string, error) {
+	input := cmd.InOrStdin()
+	if file, ok := input.(*os.File); ok && term.IsTerminal(int(file.Fd())) {
+		writer := cmd.ErrOrStderr()
+		ui.Prompt(writer, i18n.T("secret.prompt", name))
+		value, err := term.ReadPassword(int(file.Fd()))
+		fmt.Fprintln(writer)
+		if err != nil {
+			return "", i18n.Errorf("secret.read_failed", err)
+		}
+		return string(value), nil
+	}
+	data, err := io.ReadAll(input)
+	if err != nil {
+		return "", i18n.Errorf("secret.read_failed", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
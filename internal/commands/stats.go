@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alpen/alpen-cli/internal/config"
+	"github.com/alpen/alpen-cli/internal/i18n"
+	"github.com/alpen/alpen-cli/internal/plugins/metrics"
+	"github.com/alpen/alpen-cli/internal/ui"
+)
+
+// NewStatsCommand 创建 stats 子命令，展示各命令的执行统计
+func NewStatsCommand(_ Dependencies) *cobra.Command {
+	return &cobra.Command{
+		Use:           "stats",
+		Short:         i18n.T("stats.short"),
+		Long:          i18n.T("stats.long"),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			path, err := config.StatePath(metrics.DefaultFileName)
+			if err != nil {
+				return err
+			}
+			store, err := metrics.Load(path)
+			if err != nil {
+				return err
+			}
+			writer := cmd.OutOrStdout()
+			if len(store.Commands) == 0 {
+				ui.Info(writer, i18n.T("stats.empty"))
+				return nil
+			}
+			renderStatsTable(writer, store)
+			return nil
+		},
+	}
+}
+
+func renderStatsTable(writer io.Writer, store *metrics.Store) {
+	headers := []string{i18n.T("stats.col.command"), i18n.T("stats.col.runs"), i18n.T("stats.col.failures"), i18n.T("stats.col.failure_rate"), i18n.T("stats.col.retries"), "p50", "p95", i18n.T("stats.col.last_run")}
+	var rows [][]string
+	for _, name := range store.SortedCommands() {
+		stats := store.Commands[name]
+		rows = append(rows, []string{
+			name,
+			strconv.FormatInt(stats.Runs, 10),
+			strconv.FormatInt(stats.Failures, 10),
+			fmt.Sprintf("%.1f%%", stats.FailureRate()*100),
+			strconv.FormatInt(stats.Retries, 10),
+			formatStatDuration(stats.Percentile(0.5)),
+			formatStatDuration(stats.Percentile(0.95)),
+			stats.LastRun.Local().Format("2006-01-02 15:04"),
+		})
+	}
+
+	widths := make([]int, len(headers))
+	for i, header := range headers {
+		widths[i] = displayWidth(header)
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			if w := displayWidth(cell); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+
+	ui.MenuTitle(writer, i18n.T("stats.title"))
+	headerCells := make([]string, len(headers))
+	for i, header := range headers {
+		headerCells[i] = padRight(header, widths[i])
+	}
+	fmt.Fprintln(writer, ui.Gray("  "+strings.Join(headerCells, "  ")))
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = padRight(cell, widths[i])
+		}
+		cells[0] = ui.Cyan(cells[0])
+		fmt.Fprintln(writer, "  "+strings.Join(cells, "  "))
+	}
+	fmt.Fprintln(writer, "")
+}
+
+func formatStatDuration(d time.Duration) string {
+	switch {
+	case d <= 0:
+		return "-"
+	case d < time.Second:
+		return d.Round(time.Millisecond).String()
+	default:
+		return d.Round(100 * time.Millisecond).String()
+	}
+}
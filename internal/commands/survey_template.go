@@ -1,35 +1,16 @@
 package commands
 
 import (
+	"os"
 	"sync"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/AlecAivazis/survey/v2/core"
+
+	"github.com/alpen/alpen-cli/internal/ui"
 )
 
 var (
-	menuSelectTemplate = `
-{{- define "option"}}
-    {{- $desc := $.GetDescription .CurrentOpt }}
-    {{- if eq .SelectedIndex .CurrentIndex }}{{color "cyan"}}{{ .Config.Icons.SelectFocus.Text }} {{else}}{{color "default"}}  {{end}}
-    {{- .CurrentOpt.Value}}{{color "reset"}}
-    {{- if ne $desc ""}} {{color "240"}}- {{$desc}}{{color "reset"}}{{end}}
-{{end}}
-{{- if .ShowHelp }}{{- color .Config.Icons.Help.Format }}{{ .Config.Icons.Help.Text }} {{ .Help }}{{color "reset"}}{{"\n"}}{{end}}
-{{- color "cyan"}}▸ {{color "reset"}}
-{{- color "default+hb"}}{{ .Message }}{{color "reset"}}
-{{- if .ShowAnswer}}{{color "reset"}}{{"\n"}}
-{{- else}}
-{{- if .FilterMessage}}
-{{"\n"}}{{color "cyan"}}{{ .FilterMessage }}{{color "reset"}}
-{{- end}}
-{{"\n"}}
-  {{- range $ix, $option := .PageEntries}}
-    {{- if gt $ix 0}}{{"\n"}}{{- end}}
-    {{- template "option" $.IterateOption $ix $option}}
-  {{- end}}
-{{- end}}`
-
 	envSelectTemplate = `
 {{- define "envOption"}}
     {{- $meta := optionMeta .CurrentOpt.Index -}}
@@ -37,31 +18,29 @@ var (
         {{- template "defaultOption" . }}
     {{- else }}
         {{- if $meta.First }}
-{{- if $meta.GapBefore }}{{else}}{{end}}  {{color "cyan"}}{{ $meta.Group }}{{color "reset"}}
-        {{- end }}
-        {{- if eq .SelectedIndex .CurrentIndex }}
-   {{color "cyan"}}{{ .Config.Icons.SelectFocus.Text }}{{color "reset"}}  {{if $meta.Active}}{{color "green"}}{{end}}{{$meta.Name}}{{color "reset"}}
-        {{- else if $meta.Active }}
-      {{color "green"}}{{$meta.Name}}{{color "reset"}}
-        {{- else }}
-      {{$meta.Name}}
+{{- if $meta.GapBefore }}{{else}}{{end}}  {{themeColor "primary"}}{{ $meta.Group }}{{color "reset"}}
         {{- end }}
+        {{- "   " }}{{ if eq .SelectedIndex .CurrentIndex }}{{themeColor "primary"}}{{ .Config.Icons.SelectFocus.Text }}{{color "reset"}}{{else}} {{end}}
+        {{- if index .Checked .CurrentOpt.Index }} {{themeColor "success"}}{{themeIcon "checked"}}{{color "reset"}}{{else}} {{themeIcon "unchecked"}}{{end}}
+        {{- " "}}{{if $meta.Active}}{{themeColor "success"}}{{end}}{{$meta.Name}}{{color "reset"}}
+        {{- if ne $meta.Namespace "" }} {{themeColor "muted"}}{{themeIcon "arrow"}} {{$meta.Namespace}}{{color "reset"}}{{end}}
     {{- end }}
 {{- end }}
 {{- define "defaultOption"}}
-    {{- if eq .SelectedIndex .CurrentIndex }}{{color "cyan"}}{{ .Config.Icons.SelectFocus.Text }} {{else}}{{color "default"}}  {{end}}
-    {{- .CurrentOpt.Value}}{{ if ne ($.GetDescription .CurrentOpt) "" }} - {{color "240"}}{{ $.GetDescription .CurrentOpt }}{{end}}
+    {{- if eq .SelectedIndex .CurrentIndex }}{{themeColor "primary"}}{{ .Config.Icons.SelectFocus.Text }} {{else}}{{color "default"}}  {{end}}
+    {{- if index .Checked .CurrentOpt.Index }}{{themeIcon "checked"}} {{else}}{{themeIcon "unchecked"}} {{end}}
+    {{- .CurrentOpt.Value}}{{ if ne ($.GetDescription .CurrentOpt) "" }} - {{themeColor "muted"}}{{ $.GetDescription .CurrentOpt }}{{end}}
     {{- color "reset"}}
 {{- end }}
 {{- define "option"}}
     {{- if metaEnabled }}{{template "envOption" .}}{{else}}{{template "defaultOption" .}}{{end}}
 {{- end }}
 {{- if .ShowHelp }}{{- color .Config.Icons.Help.Format }}{{ .Config.Icons.Help.Text }} {{ .Help }}{{color "reset"}}{{end}}
-{{- color "cyan"}}▸ {{color "reset"}}
-{{- color "default+hb"}}{{ .Message }}{{color "reset"}}{{"\n"}}
-{{- if .ShowAnswer}}{{color "reset"}}{{"\n"}}
-{{- else}}
-{{- if .FilterMessage }}{{"\n"}}{{color "cyan"}}{{ .FilterMessage }}{{color "reset"}}{{end}}
+{{- themeColor "primary"}}{{themeIcon "prompt"}} {{color "reset"}}
+{{- color "default+hb"}}{{ .Message }}{{color "reset"}}
+{{- if .ShowAnswer}} {{themeColor "primary"}}{{ .Answer }}{{color "reset"}}{{"\n"}}
+{{- else}}{{"\n"}}
+{{- if .FilterMessage }}{{"\n"}}{{themeColor "primary"}}{{ .FilterMessage }}{{color "reset"}}{{end}}
 {{- range $ix, $option := .PageEntries}}
 {{- if eq $ix 0 }}  {{template "option" $.IterateOption $ix $option}}
 {{- else }}{{"\n"}}  {{template "option" $.IterateOption $ix $option}}
@@ -81,39 +60,16 @@ type selectOptionTemplateMeta struct {
 	Group     string
 	Name      string
 	Path      string
+	Namespace string
 	Active    bool
 	First     bool
 	GapBefore bool
 }
 
-func ensureMenuSelectTemplate() {
-	survey.SelectQuestionTemplate = menuSelectTemplate
-
-	// 注册模板函数
-	core.TemplateFuncsWithColor["sub"] = func(a, b int) int { return a - b }
-	core.TemplateFuncsNoColor["sub"] = func(a, b int) int { return a - b }
-	core.TemplateFuncsWithColor["len"] = func(v interface{}) int {
-		if slice, ok := v.([]interface{}); ok {
-			return len(slice)
-		}
-		return 0
-	}
-	core.TemplateFuncsNoColor["len"] = func(v interface{}) int {
-		if slice, ok := v.([]interface{}); ok {
-			return len(slice)
-		}
-		return 0
-	}
-
-	selectMetaMu.Lock()
-	selectMetaStore = nil
-	selectMetaEnabled = false
-	selectMetaMu.Unlock()
-}
-
+// ensureEnvSelectTemplate 为 env 的多选列表设置模板
 func ensureEnvSelectTemplate() {
 	ensureEnvTemplateFuncs()
-	survey.SelectQuestionTemplate = envSelectTemplate
+	survey.MultiSelectQuestionTemplate = envSelectTemplate
 }
 
 func ensureEnvTemplateFuncs() {
@@ -122,9 +78,26 @@ func ensureEnvTemplateFuncs() {
 		core.TemplateFuncsNoColor["optionMeta"] = lookupSelectOptionMeta
 		core.TemplateFuncsWithColor["metaEnabled"] = isOptionMetaEnabled
 		core.TemplateFuncsNoColor["metaEnabled"] = isOptionMetaEnabled
+		// 模板中的颜色与图标取自当前主题，未启用彩色时 survey 使用 NoColor 版本
+		core.TemplateFuncsWithColor["themeColor"] = ui.ThemeColor
+		core.TemplateFuncsNoColor["themeColor"] = func(string) string { return "" }
+		core.TemplateFuncsWithColor["themeIcon"] = ui.ThemeIcon
+		core.TemplateFuncsNoColor["themeIcon"] = ui.ThemeIcon
 	})
 }
 
+// surveyOptions 返回按当前主题与彩色设置提问的选项，survey 的提示写到标准输出
+func surveyOptions() []survey.AskOpt {
+	ensureEnvTemplateFuncs()
+	core.DisableColor = !ui.ColorEnabled(os.Stdout)
+	icons := ui.Icons()
+	return []survey.AskOpt{survey.WithIcons(func(set *survey.IconSet) {
+		set.SelectFocus.Text = icons.Focus
+		set.MarkedOption.Text = icons.Checked
+		set.UnmarkedOption.Text = icons.Unchecked
+	})}
+}
+
 func setSelectOptionMeta(meta []selectOptionTemplateMeta) func() {
 	selectMetaMu.Lock()
 	selectMetaStore = append([]selectOptionTemplateMeta(nil), meta...)
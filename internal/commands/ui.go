@@ -1,29 +1,33 @@
 package commands
 
 import (
-	"bufio"
 	"errors"
 	"fmt"
-	"io"
 	"os"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 
-	"github.com/AlecAivazis/survey/v2"
-	"github.com/kballard/go-shellquote"
 	"github.com/spf13/cobra"
 
 	"github.com/alpen/alpen-cli/internal/config"
-	"github.com/alpen/alpen-cli/internal/executor"
+	"github.com/alpen/alpen-cli/internal/history"
+	"github.com/alpen/alpen-cli/internal/i18n"
+	"github.com/alpen/alpen-cli/internal/logging"
+	"github.com/alpen/alpen-cli/internal/prereq"
+	"github.com/alpen/alpen-cli/internal/tui"
 	"github.com/alpen/alpen-cli/internal/ui"
 )
 
 // NewUICommand 创建 UI 命令入口
 func NewUICommand(deps Dependencies) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:     "ui",
-		Aliases: []string{"menu", "interactive"},
-		Short:   "交互式命令导航",
-		Long:    "以交互式列表的方式浏览命令树，选择后直接执行脚本，适合新成员快速上手。",
+		Use:          "ui",
+		Aliases:      []string{"menu", "interactive"},
+		SilenceUsage: true,
+		Short:        i18n.T("ui.short"),
+		Long:         i18n.T("ui.long"),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runUI(cmd, deps)
 		},
@@ -31,19 +35,65 @@ func NewUICommand(deps Dependencies) *cobra.Command {
 	return cmd
 }
 
+// menuOption 为菜单中一条可执行的命令
 type menuOption struct {
-	Label       string
-	Description string
 	Path        []string
-	Command     string
+	Alias       string
+	Description string
+	Target      config.Target
+	// Unavailable 记录缺少的前置条件，非空时菜单中以灰色显示
+	Unavailable string
+}
+
+// Label 返回以空格连接的命令路径
+func (o menuOption) Label() string {
+	return strings.Join(o.Path, " ")
 }
 
 func runUI(cmd *cobra.Command, deps Dependencies) error {
-	session, err := newUISession(cmd, deps)
-	if err != nil || session == nil {
+	if deps.Loader == nil {
+		return i18n.Errorf("common.loader_missing")
+	}
+	if deps.Executor == nil {
+		return i18n.Errorf("common.executor_missing")
+	}
+	writer := cmd.OutOrStdout()
+
+	cfg, configLabel, err := loadCommandConfig(cmd, deps)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			ui.Warning(writer, i18n.T("root.no_config"), ui.Highlight(configLabel))
+			ui.Info(writer, i18n.T("ui.init_hint"), ui.Highlight("alpen init"))
+			return nil
+		}
 		return err
 	}
-	return session.run()
+
+	options := buildMenuOptions(cfg, requirementChecker(deps))
+	if len(options) == 0 {
+		renderDiagnostics(writer, cfg.Diagnostics)
+		ui.Warning(writer, i18n.T("ui.no_commands"))
+		return nil
+	}
+
+	in, inOK := cmd.InOrStdin().(*os.File)
+	out, outOK := writer.(*os.File)
+	if !inOK || !outOK {
+		return fmt.Errorf("alpen ui %w", tui.ErrNotTerminal)
+	}
+	term, err := tui.Open(in, out)
+	if err != nil {
+		return fmt.Errorf("alpen ui %w", err)
+	}
+	// 全屏界面占用终端期间不向标准错误输出日志，避免打乱画面
+	restoreLogs := logging.MuteConsole()
+	defer restoreLogs()
+	app := newUIApp(cmd, deps, term, cfg, configLabel, options)
+	err = app.loop()
+	if closeErr := term.Close(); err == nil {
+		err = closeErr
+	}
+	return err
 }
 
 func resolveConfigFlags(cmd *cobra.Command) (string, string, error) {
@@ -59,271 +109,236 @@ func resolveConfigFlags(cmd *cobra.Command) (string, string, error) {
 	return configPath, envName, nil
 }
 
-func buildMenuOptions(cfg *config.Config) []menuOption {
+// buildMenuOptions 生成菜单选项，不适用于当前平台的命令会被隐藏，缺少前置条件的命令标记为不可用
+func buildMenuOptions(cfg *config.Config, checker *prereq.Checker) []menuOption {
 	if cfg == nil || cfg.Commands == nil {
 		return nil
 	}
 	var options []menuOption
+	appendOption := func(option menuOption) {
+		if checker != nil {
+			report := checker.Check(option.Target)
+			if !report.Supported() {
+				return
+			}
+			option.Unavailable = report.Summary()
+		}
+		options = append(options, option)
+	}
 	for _, name := range cfg.SortedCommandNames() {
 		spec := cfg.Commands[name]
-		path := []string{name}
-		if command := strings.TrimSpace(spec.Command); command != "" {
-			label := name
-			description := strings.TrimSpace(spec.Description)
-			if alias := strings.TrimSpace(spec.Alias); alias != "" {
-				label = fmt.Sprintf("%s (%s)", name, alias)
-			}
-			options = append(options, menuOption{
-				Label:       label,
-				Description: strings.TrimSpace(description),
-				Path:        path,
-				Command:     command,
+		if strings.TrimSpace(spec.Command) != "" {
+			appendOption(menuOption{
+				Path:        []string{name},
+				Alias:       strings.TrimSpace(spec.Alias),
+				Description: strings.TrimSpace(spec.Description),
+				Target:      targetFor(cfg, []string{name}),
 			})
 		}
 		for _, actionName := range spec.SortedActionNames() {
 			action := spec.Actions[actionName]
-			label := fmt.Sprintf("%s %s", name, actionName)
-			description := strings.TrimSpace(action.Description)
-			if alias := strings.TrimSpace(action.Alias); alias != "" {
-				label = fmt.Sprintf("%s %s (%s)", name, actionName, alias)
-			}
-			options = append(options, menuOption{
-				Label:       label,
-				Description: strings.TrimSpace(description),
+			appendOption(menuOption{
 				Path:        []string{name, actionName},
-				Command:     action.Command,
+				Alias:       strings.TrimSpace(action.Alias),
+				Description: strings.TrimSpace(action.Description),
+				Target:      targetFor(cfg, []string{name, actionName}),
 			})
 		}
 	}
 	return options
 }
 
-// buildSurveyChoices 将 menuOption 转换为 survey 显示的选项
-func buildSurveyChoices(options []menuOption) []string {
-	choices := make([]string, 0, len(options)+1)
-
-	var lastTopLevel string
-	for _, option := range options {
-		topLevel := option.Path[0]
-
-		// 如果是新的主命令分组，添加分组标题（仅用于子命令）
-		if len(option.Path) > 1 && topLevel != lastTopLevel {
-			lastTopLevel = topLevel
-		}
-
-		// 根据命令类型添加不同前缀
-		var prefix string
-		if len(option.Path) == 1 {
-			// 主命令默认动作
-			prefix = "▪"
-		} else {
-			// 子命令
-			prefix = "  ·"
-		}
+// menuNode 为命令树中的节点，Option 为空表示仅用于分组（命名空间或只有子命令的命令）
+type menuNode struct {
+	Name        string
+	Path        []string
+	Alias       string
+	Description string
+	Option      *menuOption
+	Children    []*menuNode
+	Expanded    bool
+	// Section 非空表示节点属于顶部的置顶或最近使用分组
+	Section string
+}
 
-		choiceText := fmt.Sprintf("%s %s", prefix, option.Label)
-		choices = append(choices, choiceText)
-	}
+// 顶部快捷分组
+const (
+	sectionPinned = "pinned"
+	sectionRecent = "recent"
+)
 
-	// 添加退出选项
-	choices = append(choices, "✘ 退出")
-	return choices
-}
+// recentLimit 为最近使用分组展示的命令数
+const recentLimit = 5
 
-func promptExtraArgs(reader *bufio.Reader, writer io.Writer) ([]string, error) {
-	ui.Prompt(writer, "额外参数（可选，可使用引号保留空格）: ")
-	line, err := reader.ReadString('\n')
-	if err != nil {
-		return nil, err
-	}
-	line = strings.TrimSpace(line)
-	if line == "" {
-		return nil, nil
-	}
-	parts, err := shellquote.Split(line)
-	if err != nil {
-		return nil, fmt.Errorf("参数格式错误: %w", err)
-	}
-	return parts, nil
+// key 返回节点在重建命令树前后保持不变的标识
+func (n *menuNode) key() string {
+	return n.Section + "/" + strings.Join(n.Path, " ")
 }
 
-type uiSession struct {
-	cmd     *cobra.Command
-	deps    Dependencies
-	reader  *bufio.Reader
-	writer  io.Writer
-	options []menuOption
+// menuRow 为命令树展开后可见的一行
+type menuRow struct {
+	Node  *menuNode
+	Depth int
 }
 
-func newUISession(cmd *cobra.Command, deps Dependencies) (*uiSession, error) {
-	ensureMenuSelectTemplate()
-	if deps.Loader == nil {
-		return nil, fmt.Errorf("配置加载器未初始化")
-	}
-	if deps.Executor == nil {
-		return nil, fmt.Errorf("执行器未初始化")
+// buildMenuTree 按命名空间、命令、子命令的层级组织菜单选项
+func buildMenuTree(cfg *config.Config, options []menuOption) []*menuNode {
+	var roots []*menuNode
+	index := map[string]*menuNode{}
+	// ensure 返回树路径对应的节点，不存在时按层级依次创建
+	var ensure func(path []string) *menuNode
+	ensure = func(path []string) *menuNode {
+		key := strings.Join(path, " ")
+		if node, ok := index[key]; ok {
+			return node
+		}
+		node := &menuNode{Name: path[len(path)-1], Path: path}
+		if spec, ok := cfg.Commands[key]; ok {
+			node.Alias = strings.TrimSpace(spec.Alias)
+			node.Description = strings.TrimSpace(spec.Description)
+		} else if len(path) == 1 && slices.Contains(cfg.Namespaces, key) {
+			node.Description = i18n.T("ui.namespace", key)
+		}
+		index[key] = node
+		if len(path) == 1 {
+			roots = append(roots, node)
+		} else {
+			parent := ensure(path[:len(path)-1])
+			parent.Children = append(parent.Children, node)
+		}
+		return node
 	}
-
-	writer := cmd.OutOrStdout()
-
-	configPath, envName, err := resolveConfigFlags(cmd)
-	if err != nil {
-		return nil, err
+	for i := range options {
+		option := &options[i]
+		path := append(strings.Fields(option.Path[0]), option.Path[1:]...)
+		node := ensure(path)
+		node.Option = option
+		node.Alias = option.Alias
+		node.Description = option.Description
 	}
-
-	cfg, err := deps.Loader.Load(configPath, envName)
-	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			ui.Warning(writer, "未检测到命令配置文件 %s", ui.Highlight(configPath))
-			ui.Info(writer, "执行 %s 可生成默认配置", ui.Highlight("alpen init"))
-			return nil, nil
+	// 命令较少时默认全部展开，否则只展示顶层
+	expand := len(options) <= 20
+	var walk func(nodes []*menuNode)
+	walk = func(nodes []*menuNode) {
+		for _, node := range nodes {
+			node.Expanded = expand
+			walk(node.Children)
 		}
-		return nil, err
-	}
-
-	renderDiagnostics(writer, cfg.Diagnostics)
-
-	options := buildMenuOptions(cfg)
-	if len(options) == 0 {
-		ui.Warning(writer, "当前配置未包含可执行命令")
-		return nil, nil
 	}
-
-	return &uiSession{
-		cmd:     cmd,
-		deps:    deps,
-		reader:  bufio.NewReader(cmd.InOrStdin()),
-		writer:  writer,
-		options: options,
-	}, nil
+	walk(roots)
+	return roots
 }
 
-func (s *uiSession) run() error {
-	s.renderIntro()
-
-	for {
-		index, err := s.promptMenuSelection()
-		if err != nil {
-			if errors.Is(err, io.EOF) || err.Error() == "interrupt" {
-				s.renderExitMessage()
-				return nil
-			}
-			ui.Warning(s.writer, "%v", err)
-			continue
+// rankMenuTree 按使用历史的 frecency 分数对同级节点降序排序，分数相同时保持原有的名称顺序；
+// 分组节点取其下最高的分数
+func rankMenuTree(nodes []*menuNode, hist *history.Store, now time.Time) {
+	if hist == nil {
+		return
+	}
+	scores := map[*menuNode]float64{}
+	var score func(node *menuNode) float64
+	score = func(node *menuNode) float64 {
+		best := 0.0
+		if node.Option != nil {
+			best = hist.Score(node.Option.Label(), now)
 		}
-
-		// 退出选项的索引是 len(options)
-		if index >= len(s.options) {
-			s.renderExitMessage()
-			return nil
+		for _, child := range node.Children {
+			best = max(best, score(child))
 		}
-
-		cont, err := s.executeOption(s.options[index])
-		if err != nil {
-			return err
+		scores[node] = best
+		return best
+	}
+	var sortLevel func(nodes []*menuNode)
+	sortLevel = func(nodes []*menuNode) {
+		for _, node := range nodes {
+			score(node)
 		}
-		if !cont {
-			return nil
+		sort.SliceStable(nodes, func(i, j int) bool {
+			return scores[nodes[i]] > scores[nodes[j]]
+		})
+		for _, node := range nodes {
+			sortLevel(node.Children)
 		}
 	}
+	sortLevel(nodes)
 }
 
-func (s *uiSession) renderIntro() {
-	fmt.Fprintln(s.writer, "")
-	ui.Banner(s.writer, "☰ Alpen 命令导航")
-	fmt.Fprintln(s.writer, "")
-}
-
-func (s *uiSession) renderExitMessage() {
-	fmt.Fprintln(s.writer, "")
-	fmt.Fprintln(s.writer, "")
-	fmt.Fprintln(s.writer, ui.Yellow("    已退出"))
-}
-
-func (s *uiSession) promptMenuSelection() (int, error) {
-	choices := buildSurveyChoices(s.options)
-	prompt := &survey.Select{
-		Message: "选择命令 (↑/↓ 导航 | / 搜索 | Enter 确认)",
-		Options: choices,
-		Description: func(value string, index int) string {
-			// 退出选项不显示描述
-			if index >= len(s.options) {
-				return ""
+// shortcutSections 生成菜单顶部的置顶与最近使用分组，其中的节点引用命令树中同一个菜单选项；
+// 已不在当前配置中的命令会被跳过
+func shortcutSections(options []menuOption, hist *history.Store) []*menuNode {
+	if hist == nil {
+		return nil
+	}
+	byLabel := map[string]*menuOption{}
+	for i := range options {
+		byLabel[options[i].Label()] = &options[i]
+	}
+	section := func(section, name, description string, labels []string) *menuNode {
+		node := &menuNode{Name: name, Path: []string{name}, Description: description, Section: section, Expanded: true}
+		for _, label := range labels {
+			option, ok := byLabel[label]
+			if !ok {
+				continue
 			}
-			return s.options[index].Description
-		},
-		PageSize: 15,
-		Filter:   s.buildPrefixFilter(),
-		Help:     "",
+			node.Children = append(node.Children, &menuNode{
+				Name:        label,
+				Path:        option.Path,
+				Alias:       option.Alias,
+				Description: option.Description,
+				Option:      option,
+				Section:     section,
+			})
+		}
+		return node
+	}
+	var sections []*menuNode
+	if pinned := section(sectionPinned, ui.Icons().Pinned+" "+i18n.T("ui.section.pinned"), i18n.T("ui.section.pinned_hint"), hist.Pins); len(pinned.Children) > 0 {
+		sections = append(sections, pinned)
 	}
-	var selectedIndex int
-	if err := survey.AskOne(prompt, &selectedIndex); err != nil {
-		return -1, err
+	if recent := section(sectionRecent, ui.Icons().Recent+" "+i18n.T("ui.section.recent"), i18n.T("ui.section.recent_hint"), hist.Recent(recentLimit)); len(recent.Children) > 0 {
+		sections = append(sections, recent)
 	}
-	return selectedIndex, nil
+	return sections
 }
 
-func (s *uiSession) buildPrefixFilter() func(filter string, value string, index int) bool {
-	return func(filter string, value string, index int) bool {
-		trimmed := strings.TrimSpace(filter)
-		if trimmed == "" {
-			return true
-		}
-
-		lowerValue := strings.ToLower(value)
-		if strings.HasPrefix(trimmed, "/") {
-			prefix := strings.TrimLeft(trimmed, "/")
-			prefix = strings.ToLower(strings.TrimSpace(prefix))
-			if prefix == "" {
-				return true
-			}
-			// 退出选项
-			if index >= len(s.options) {
-				return strings.HasPrefix(strings.ToLower("退出"), prefix)
+// visibleRows 返回当前可见的行；filter 非空时展示路径、别名或描述匹配的节点及其上级，并忽略折叠状态
+func visibleRows(roots []*menuNode, filter string) []menuRow {
+	filter = strings.ToLower(strings.TrimSpace(filter))
+	var rows []menuRow
+	var walk func(nodes []*menuNode, depth int, forced bool)
+	walk = func(nodes []*menuNode, depth int, forced bool) {
+		for _, node := range nodes {
+			switch {
+			case filter != "" && node.Section != "":
+				// 筛选时不展示快捷分组，避免同一命令重复出现
+			case filter == "" || forced:
+				rows = append(rows, menuRow{Node: node, Depth: depth})
+				if node.Expanded || forced {
+					walk(node.Children, depth+1, forced)
+				}
+			case node.matches(filter):
+				rows = append(rows, menuRow{Node: node, Depth: depth})
+				walk(node.Children, depth+1, true)
+			case node.hasMatch(filter):
+				rows = append(rows, menuRow{Node: node, Depth: depth})
+				walk(node.Children, depth+1, false)
 			}
-			option := s.options[index]
-			joinedPath := strings.ToLower(strings.Join(option.Path, " "))
-			topLevel := ""
-			if len(option.Path) > 0 {
-				topLevel = strings.ToLower(option.Path[0])
-			}
-			return strings.HasPrefix(joinedPath, prefix) || strings.HasPrefix(topLevel, prefix)
 		}
-
-		return strings.Contains(lowerValue, strings.ToLower(trimmed))
 	}
+	walk(roots, 0, false)
+	return rows
 }
 
-func (s *uiSession) executeOption(option menuOption) (bool, error) {
-	if strings.TrimSpace(option.Command) == "" {
-		ui.Warning(s.writer, "命令 %s 未配置可执行脚本", ui.Highlight(strings.Join(option.Path, " ")))
-		return true, nil
-	}
+func (n *menuNode) matches(filter string) bool {
+	text := strings.ToLower(strings.Join(n.Path, " ") + " " + n.Alias + " " + n.Description)
+	return strings.Contains(text, filter)
+}
 
-	extraArgs, err := promptExtraArgs(s.reader, s.writer)
-	if err != nil {
-		if errors.Is(err, io.EOF) {
-			fmt.Fprintln(s.writer)
-			return false, nil
+func (n *menuNode) hasMatch(filter string) bool {
+	for _, child := range n.Children {
+		if child.matches(filter) || child.hasMatch(filter) {
+			return true
 		}
-		ui.Error(s.writer, "解析参数失败: %v", err)
-		return true, nil
 	}
-
-	displayName := strings.Join(option.Path, " ")
-	ui.BeginExecution(s.writer, displayName)
-
-	req := executor.ScriptRequest{
-		CommandPath: option.Path,
-		Command:     option.Command,
-		ExtraArgs:   extraArgs,
-	}
-
-	result, execErr := s.deps.Executor.Execute(s.cmd.Context(), req)
-
-	ui.EndExecution(s.writer)
-	ui.ExecutionSummary(s.writer, execErr == nil, result.Duration, execErr)
-	fmt.Fprintln(s.writer, "")
-
-	return true, nil
+	return false
 }
@@ -0,0 +1,872 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alpen/alpen-cli/internal/config"
+	"github.com/alpen/alpen-cli/internal/executor"
+	"github.com/alpen/alpen-cli/internal/history"
+	"github.com/alpen/alpen-cli/internal/i18n"
+	"github.com/alpen/alpen-cli/internal/plugins/metrics"
+	"github.com/alpen/alpen-cli/internal/tui"
+	"github.com/alpen/alpen-cli/internal/ui"
+)
+
+// uiMode 为全屏界面当前的交互状态
+type uiMode int
+
+const (
+	modeBrowse uiMode = iota
+	modeSearch
+	modeForm
+	modeOutput
+)
+
+// maxOutputLines 为输出面板保留的最大行数
+const maxOutputLines = 5000
+
+// uiApp 为 alpen ui 的全屏界面：左侧为命令树，右侧依次展示详情、参数表单与输出面板
+type uiApp struct {
+	cmd         *cobra.Command
+	deps        Dependencies
+	term        *tui.Terminal
+	cfg         *config.Config
+	configLabel string
+	options     []menuOption
+	roots       []*menuNode
+	rows        []menuRow
+	cursor      int
+	offset      int
+	mode        uiMode
+	filter      string
+	form        *paramForm
+	output      *outputPanel
+	stats       *metrics.Store
+	// marked 为多选模式下按选择顺序排列的命令，Enter 时批量执行
+	marked  []*menuOption
+	history *history.Store
+	// message 为底部状态栏的提示，下一次按键后清除
+	message string
+
+	keys       chan keyEvent
+	keyPending bool
+	events     chan func()
+}
+
+type keyEvent struct {
+	key tui.Key
+	err error
+}
+
+func newUIApp(cmd *cobra.Command, deps Dependencies, term *tui.Terminal, cfg *config.Config, configLabel string, options []menuOption) *uiApp {
+	app := &uiApp{
+		cmd:         cmd,
+		deps:        deps,
+		term:        term,
+		cfg:         cfg,
+		configLabel: configLabel,
+		options:     options,
+		keys:        make(chan keyEvent),
+		events:      make(chan func(), 16),
+	}
+	app.reloadStats()
+	app.rebuildTree()
+	if count := len(cfg.Diagnostics); count > 0 {
+		app.message = ui.Yellow(i18n.T("ui.diagnostics_notice", count))
+	}
+	return app
+}
+
+// loop 为界面主循环：绘制、等待按键或后台事件，直到用户退出
+func (a *uiApp) loop() error {
+	var ticker *time.Ticker
+	defer func() {
+		if ticker != nil {
+			ticker.Stop()
+		}
+	}()
+	for {
+		a.render()
+		running := a.output != nil && a.output.running()
+		if running && ticker == nil {
+			ticker = time.NewTicker(200 * time.Millisecond)
+		} else if !running && ticker != nil {
+			ticker.Stop()
+			ticker = nil
+		}
+		var tick <-chan time.Time
+		if ticker != nil {
+			tick = ticker.C
+		}
+
+		a.requestKey()
+		select {
+		case ev := <-a.keys:
+			a.keyPending = false
+			if ev.err != nil {
+				return ev.err
+			}
+			if a.handleKey(ev.key) {
+				return nil
+			}
+		case apply := <-a.events:
+			apply()
+		case <-tick:
+		}
+	}
+}
+
+// requestKey 在后台读取下一次按键；同一时间只保留一个读取，
+// 处理按键时不存在未完成的读取，子进程可以安全地接管终端
+func (a *uiApp) requestKey() {
+	if a.keyPending {
+		return
+	}
+	a.keyPending = true
+	go func() {
+		key, err := a.term.ReadKey()
+		a.keys <- keyEvent{key: key, err: err}
+	}()
+}
+
+// post 将后台任务的结果交给主循环处理
+func (a *uiApp) post(apply func()) {
+	a.events <- apply
+}
+
+func (a *uiApp) refreshRows() {
+	a.rows = visibleRows(a.roots, a.filter)
+	if a.cursor >= len(a.rows) {
+		a.cursor = len(a.rows) - 1
+	}
+	if a.cursor < 0 {
+		a.cursor = 0
+	}
+}
+
+// rebuildTree 按最新的使用历史重建命令树，保留已有的展开状态与光标所在的命令
+func (a *uiApp) rebuildTree() {
+	expanded := map[string]bool{}
+	var walk func(nodes []*menuNode)
+	walk = func(nodes []*menuNode) {
+		for _, node := range nodes {
+			expanded[node.key()] = node.Expanded
+			walk(node.Children)
+		}
+	}
+	walk(a.roots)
+	current := ""
+	if node := a.selected(); node != nil {
+		current = node.key()
+	}
+
+	roots := buildMenuTree(a.cfg, a.options)
+	rankMenuTree(roots, a.history, time.Now())
+	a.roots = append(shortcutSections(a.options, a.history), roots...)
+	walk = func(nodes []*menuNode) {
+		for _, node := range nodes {
+			if value, ok := expanded[node.key()]; ok {
+				node.Expanded = value
+			}
+			walk(node.Children)
+		}
+	}
+	walk(a.roots)
+
+	a.refreshRows()
+	for i, row := range a.rows {
+		if row.Node.key() == current {
+			a.cursor = i
+			break
+		}
+	}
+}
+
+func (a *uiApp) reloadStats() {
+	a.history = loadHistory()
+	a.stats = nil
+	path, err := config.StatePath(metrics.DefaultFileName)
+	if err != nil {
+		return
+	}
+	if store, err := metrics.Load(path); err == nil {
+		a.stats = store
+	}
+}
+
+func (a *uiApp) selected() *menuNode {
+	if a.cursor < 0 || a.cursor >= len(a.rows) {
+		return nil
+	}
+	return a.rows[a.cursor].Node
+}
+
+// handleKey 处理一次按键，返回 true 表示退出界面
+func (a *uiApp) handleKey(key tui.Key) bool {
+	a.message = ""
+	switch a.mode {
+	case modeSearch:
+		a.handleSearchKey(key)
+	case modeForm:
+		a.handleFormKey(key)
+	case modeOutput:
+		a.handleOutputKey(key)
+	default:
+		return a.handleBrowseKey(key)
+	}
+	return false
+}
+
+func (a *uiApp) handleBrowseKey(key tui.Key) bool {
+	_, height := a.term.Size()
+	page := max(bodyHeight(height)-1, 1)
+	switch {
+	case key.Code == tui.KeyCtrlC, key.Code == tui.KeyRune && key.Rune == 'q':
+		return true
+	case key.Code == tui.KeyEscape:
+		switch {
+		case len(a.marked) > 0:
+			a.marked = nil
+		case a.filter != "":
+			a.filter = ""
+			a.refreshRows()
+		default:
+			return true
+		}
+	case key.Code == tui.KeyUp, key.Code == tui.KeyRune && key.Rune == 'k':
+		a.moveCursor(-1)
+	case key.Code == tui.KeyDown, key.Code == tui.KeyRune && key.Rune == 'j':
+		a.moveCursor(1)
+	case key.Code == tui.KeyPageUp:
+		a.moveCursor(-page)
+	case key.Code == tui.KeyPageDown:
+		a.moveCursor(page)
+	case key.Code == tui.KeyHome, key.Code == tui.KeyRune && key.Rune == 'g':
+		a.moveCursor(-len(a.rows))
+	case key.Code == tui.KeyEnd, key.Code == tui.KeyRune && key.Rune == 'G':
+		a.moveCursor(len(a.rows))
+	case key.Code == tui.KeyRight, key.Code == tui.KeyRune && key.Rune == 'l':
+		if node := a.selected(); node != nil && len(node.Children) > 0 {
+			node.Expanded = true
+			a.refreshRows()
+		}
+	case key.Code == tui.KeyLeft, key.Code == tui.KeyRune && key.Rune == 'h':
+		a.collapseOrParent()
+	case key.Code == tui.KeyRune && key.Rune == '/':
+		a.mode = modeSearch
+	case key.Code == tui.KeyRune && key.Rune == '!':
+		a.showDiagnostics()
+	case key.Code == tui.KeyRune && key.Rune == 'p':
+		a.togglePin()
+	case key.Code == tui.KeyRune && key.Rune == 'r':
+		if node := a.selected(); node != nil && node.Option != nil {
+			a.rerun(node.Option)
+		}
+	case key.Code == tui.KeyRune && key.Rune == ' ':
+		a.toggleMark()
+	case key.Code == tui.KeyEnter:
+		if len(a.marked) > 0 {
+			a.form = newBatchForm(a.cmd, a.marked, a.environment())
+			a.mode = modeForm
+			return false
+		}
+		a.activate()
+	}
+	return false
+}
+
+func (a *uiApp) moveCursor(delta int) {
+	a.cursor = min(max(a.cursor+delta, 0), len(a.rows)-1)
+}
+
+// collapseOrParent 折叠已展开的节点，否则跳到上级节点
+func (a *uiApp) collapseOrParent() {
+	node := a.selected()
+	if node == nil {
+		return
+	}
+	if node.Expanded && len(node.Children) > 0 && a.filter == "" {
+		node.Expanded = false
+		a.refreshRows()
+		return
+	}
+	depth := a.rows[a.cursor].Depth
+	for i := a.cursor - 1; i >= 0; i-- {
+		if a.rows[i].Depth < depth {
+			a.cursor = i
+			return
+		}
+	}
+}
+
+// activate 展开或折叠分组节点，可执行节点则打开参数表单
+func (a *uiApp) activate() {
+	node := a.selected()
+	if node == nil {
+		return
+	}
+	if node.Option == nil {
+		node.Expanded = !node.Expanded
+		a.refreshRows()
+		return
+	}
+	if node.Section == sectionRecent {
+		a.rerun(node.Option)
+		return
+	}
+	if a.blocked(node.Option) {
+		return
+	}
+	a.form = newParamForm(a.cmd, node.Option, a.environment())
+	a.mode = modeForm
+}
+
+// blocked 判断命令是否因缺少前置条件而无法执行，并在状态栏提示原因
+func (a *uiApp) blocked(option *menuOption) bool {
+	if option.Unavailable != "" && !dryRunRequested(a.cmd) {
+		a.message = ui.Red(i18n.T("ui.unavailable") + option.Unavailable)
+		return true
+	}
+	return false
+}
+
+// rerun 以上次的额外参数与参数再次执行命令；没有执行记录时打开参数表单，需要执行确认时打开预填的表单，
+// 上次的参数含有敏感值时打开只预填非敏感参数的表单
+func (a *uiApp) rerun(option *menuOption) {
+	if a.blocked(option) {
+		return
+	}
+	form := newParamForm(a.cmd, option, a.environment())
+	entry := a.lastEntry(option.Label())
+	if entry == nil {
+		a.form = form
+		a.mode = modeForm
+		a.message = ui.Gray(i18n.T("ui.no_history"))
+		return
+	}
+	if entry.Redacted {
+		// 上次的参数含有敏感值，只预填未遮蔽的声明参数，由用户重新填写
+		form.fill(nil, entry.LastParams)
+		form.focus = 0
+		a.form = form
+		a.mode = modeForm
+		a.message = ui.Gray(i18n.T("ui.rerun_redacted"))
+		return
+	}
+	if form.needsConfirm() {
+		form.fill(entry.LastArgs, entry.LastParams)
+		a.form = form
+		a.mode = modeForm
+		return
+	}
+	a.start(option, entry.LastArgs, entry.LastParams)
+}
+
+func (a *uiApp) lastEntry(label string) *history.Entry {
+	if a.history == nil {
+		return nil
+	}
+	return a.history.Commands[label]
+}
+
+// toggleMark 将所选命令加入或移出批量执行列表
+func (a *uiApp) toggleMark() {
+	node := a.selected()
+	if node == nil || node.Option == nil {
+		a.message = ui.Gray(i18n.T("ui.mark_runnable_only"))
+		return
+	}
+	if index := slices.Index(a.marked, node.Option); index >= 0 {
+		a.marked = slices.Delete(a.marked, index, index+1)
+	} else {
+		if a.blocked(node.Option) {
+			return
+		}
+		if missing := requiredParams(node.Option.Target); len(missing) > 0 {
+			a.message = ui.Red(i18n.T("batch.params_required", node.Option.Label(), strings.Join(missing, ", ")))
+			return
+		}
+		a.marked = append(a.marked, node.Option)
+	}
+	a.moveCursor(1)
+}
+
+// startBatch 批量执行已选择的命令，输出按行加上命令名前缀写入输出面板，结束后附上汇总表格
+func (a *uiApp) startBatch(options []*menuOption, runner batchRunner) {
+	targets := make([]config.Target, 0, len(options))
+	for _, option := range options {
+		targets = append(targets, option.Target)
+	}
+	a.marked = nil
+	panel := newOutputPanel(i18n.T("ui.batch_label", len(options)))
+	panel.title = i18n.T("ui.batch_title", len(options))
+	a.output = panel
+	a.mode = modeOutput
+
+	items, err := prepareBatch(a.cmd, a.deps, targets)
+	if err != nil {
+		panel.finish(executor.Result{}, err)
+		return
+	}
+	if dryRunRequested(a.cmd) {
+		for _, item := range items {
+			if err := showPlan(a.cmd, a.deps, item.Target, item.Request, panel); err != nil {
+				panel.finish(executor.Result{}, err)
+				return
+			}
+		}
+		panel.finish(executor.Result{}, nil)
+		panel.dryRun = true
+		return
+	}
+	// 执行确认已在表单中完成
+	if err := renderBatch(a.cmd, items); err != nil {
+		panel.finish(executor.Result{}, err)
+		return
+	}
+	for _, item := range items {
+		if requestUsesSecrets(item.Request) {
+			a.runBatchSuspended(panel, runner, items)
+			return
+		}
+	}
+
+	ctx, cancel := context.WithCancel(a.cmd.Context())
+	panel.cancel = cancel
+	var mu sync.Mutex
+	outputs := make([]*prefixWriter, len(items))
+	runner.Start = func(index int, req *executor.ScriptRequest) {
+		outputs[index] = newPrefixWriter(panel, &mu, items[index].Target.Label())
+		req.Output = outputs[index]
+		mu.Lock()
+		defer mu.Unlock()
+		ui.Executing(panel, items[index].Target.Label())
+	}
+	runner.Finish = func(index int, _ batchResult) {
+		outputs[index].Flush()
+	}
+	go func() {
+		started := time.Now()
+		results := runner.Run(ctx, items)
+		cancel()
+		a.post(func() {
+			a.finishBatch(panel, results, time.Since(started))
+			a.reloadStats()
+		})
+	}()
+}
+
+// runBatchSuspended 退出全屏界面后在终端中批量执行，结束后等待回车返回界面
+func (a *uiApp) runBatchSuspended(panel *outputPanel, runner batchRunner, items []batchItem) {
+	if err := a.term.Suspend(); err != nil {
+		panel.finish(executor.Result{}, err)
+		return
+	}
+	writer := a.cmd.OutOrStdout()
+	attachBatchOutput(&runner, writer, items)
+	started := time.Now()
+	results := runner.Run(a.cmd.Context(), items)
+	fmt.Fprintln(writer, "")
+	renderBatchSummary(writer, results)
+	ui.Prompt(writer, i18n.T("ui.press_enter"))
+	for {
+		key, err := a.term.ReadKey()
+		if err != nil || key.Code == tui.KeyEnter {
+			break
+		}
+	}
+	if err := a.term.Resume(); err != nil {
+		panel.finish(executor.Result{}, err)
+		return
+	}
+	panel.Write([]byte(i18n.T("ui.suspended")))
+	a.finishBatch(panel, results, time.Since(started))
+	a.reloadStats()
+}
+
+// finishBatch 在输出面板末尾写入汇总表格并结束面板
+func (a *uiApp) finishBatch(panel *outputPanel, results []batchResult, duration time.Duration) {
+	panel.Write([]byte("\n"))
+	renderBatchSummary(panel, results)
+	var err error
+	if failures := batchFailures(results); failures > 0 {
+		err = i18n.Errorf("batch.failed", failures)
+	}
+	panel.finish(executor.Result{Duration: duration}, err)
+}
+
+// togglePin 置顶或取消置顶所选命令
+func (a *uiApp) togglePin() {
+	node := a.selected()
+	if node == nil || node.Option == nil {
+		a.message = ui.Gray(i18n.T("ui.pin_runnable_only"))
+		return
+	}
+	label := node.Option.Label()
+	path, err := historyPath()
+	if err != nil {
+		a.message = ui.Red(err.Error())
+		return
+	}
+	pinned := false
+	err = history.Update(path, func(store *history.Store) error {
+		if store.Unpin(label) {
+			return nil
+		}
+		pinned = store.Pin(label)
+		return nil
+	})
+	if err != nil {
+		a.message = ui.Red(i18n.T("ui.pin_save_failed", err))
+		return
+	}
+	a.reloadStats()
+	a.rebuildTree()
+	if pinned {
+		a.message = ui.Green(i18n.T("ui.pinned", label))
+	} else {
+		a.message = ui.Gray(i18n.T("ui.unpinned", label))
+	}
+}
+
+func (a *uiApp) environment() string {
+	_, envName, _ := resolveConfigFlags(a.cmd)
+	return envName
+}
+
+func (a *uiApp) handleSearchKey(key tui.Key) {
+	switch key.Code {
+	case tui.KeyEnter:
+		a.mode = modeBrowse
+	case tui.KeyEscape, tui.KeyCtrlC:
+		a.filter = ""
+		a.mode = modeBrowse
+	case tui.KeyBackspace:
+		a.filter = trimLastRune(a.filter)
+	case tui.KeyCtrlU:
+		a.filter = ""
+	case tui.KeyUp:
+		a.moveCursor(-1)
+		return
+	case tui.KeyDown:
+		a.moveCursor(1)
+		return
+	case tui.KeyRune:
+		a.filter += string(key.Rune)
+	default:
+		return
+	}
+	a.refreshRows()
+	a.selectFirstExecutable()
+}
+
+// selectFirstExecutable 搜索时将光标移动到第一个可执行的节点
+func (a *uiApp) selectFirstExecutable() {
+	for i, row := range a.rows {
+		if row.Node.Option != nil {
+			a.cursor = i
+			return
+		}
+	}
+	a.cursor = 0
+}
+
+func (a *uiApp) handleFormKey(key tui.Key) {
+	form := a.form
+	switch key.Code {
+	case tui.KeyEscape, tui.KeyCtrlC:
+		a.form = nil
+		a.mode = modeBrowse
+	case tui.KeyTab, tui.KeyDown:
+		form.focus = (form.focus + 1) % len(form.fields)
+	case tui.KeyBacktab, tui.KeyUp:
+		form.focus = (form.focus + len(form.fields) - 1) % len(form.fields)
+	case tui.KeyLeft:
+		form.cycle(-1)
+	case tui.KeyRight:
+		form.cycle(1)
+	case tui.KeyBackspace:
+		if len(form.fields[form.focus].Choices) == 0 {
+			form.fields[form.focus].Value = trimLastRune(form.fields[form.focus].Value)
+		}
+	case tui.KeyCtrlU:
+		if len(form.fields[form.focus].Choices) == 0 {
+			form.fields[form.focus].Value = ""
+		}
+	case tui.KeyRune:
+		if len(form.fields[form.focus].Choices) > 0 {
+			if key.Rune == ' ' {
+				form.cycle(1)
+			}
+			return
+		}
+		form.fields[form.focus].Value += string(key.Rune)
+	case tui.KeyEnter:
+		if form.option == nil {
+			jobs, keepGoing, err := form.submitBatch()
+			if err != nil {
+				form.err = err.Error()
+				return
+			}
+			a.form = nil
+			a.startBatch(form.batch, batchRunner{Executor: a.deps.Executor, Jobs: jobs, KeepGoing: keepGoing})
+			return
+		}
+		args, params, err := form.submit()
+		if err != nil {
+			form.err = err.Error()
+			return
+		}
+		a.form = nil
+		a.start(form.option, args, params)
+	}
+}
+
+// start 执行所选命令：--dry-run 时在输出面板展示执行计划；含密钥引用的命令需要在终端中解锁密钥库，
+// 会暂时退出全屏界面执行；其余命令在输出面板中实时展示输出
+func (a *uiApp) start(option *menuOption, args []string, params map[string]string) {
+	target := option.Target
+	req, err := buildScriptRequest(a.cmd, target, args, params)
+	if err != nil {
+		a.mode = modeBrowse
+		a.message = ui.Red(err.Error())
+		return
+	}
+	panel := newOutputPanel(target.Label())
+	a.output = panel
+	a.mode = modeOutput
+
+	if req.DryRun {
+		panel.title = i18n.T("ui.plan_title", target.Label())
+		if err := showPlan(a.cmd, a.deps, target, req, panel); err != nil {
+			panel.finish(executor.Result{}, err)
+			return
+		}
+		panel.finish(executor.Result{}, nil)
+		panel.dryRun = true
+		return
+	}
+	if requestUsesSecrets(req) {
+		a.runSuspended(panel, target, req, args, params)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(a.cmd.Context())
+	panel.cancel = cancel
+	req.Output = panel
+	go func() {
+		result, err := a.deps.Executor.Execute(ctx, req)
+		if err != nil && ctx.Err() != nil {
+			err = i18n.Errorf("ui.interrupted", err)
+		}
+		cancel()
+		recordHistory(a.deps.Executor.Redactor(), target, args, params)
+		a.post(func() {
+			panel.finish(result, err)
+			a.reloadStats()
+		})
+	}()
+}
+
+// runSuspended 退出全屏界面后在终端中执行命令，结束后等待回车返回界面
+func (a *uiApp) runSuspended(panel *outputPanel, target config.Target, req executor.ScriptRequest, args []string, params map[string]string) {
+	if err := a.term.Suspend(); err != nil {
+		panel.finish(executor.Result{}, err)
+		return
+	}
+	writer := a.cmd.OutOrStdout()
+	ui.BeginExecution(writer, panel.label)
+	result, err := a.deps.Executor.Execute(a.cmd.Context(), req)
+	recordHistory(a.deps.Executor.Redactor(), target, args, params)
+	ui.EndExecution(writer)
+	ui.ExecutionSummary(writer, err == nil, result.Duration, err)
+	ui.Prompt(writer, i18n.T("ui.press_enter"))
+	for {
+		key, readErr := a.term.ReadKey()
+		if readErr != nil || key.Code == tui.KeyEnter {
+			break
+		}
+	}
+	if resumeErr := a.term.Resume(); resumeErr != nil && err == nil {
+		err = resumeErr
+	}
+	panel.Write([]byte(i18n.T("ui.suspended")))
+	panel.finish(result, err)
+	a.reloadStats()
+}
+
+// requestUsesSecrets 判断执行请求的环境变量中是否引用了密钥
+func requestUsesSecrets(req executor.ScriptRequest) bool {
+	for _, env := range []map[string]string{req.BaseEnv, req.ExtraEnv} {
+		for _, value := range env {
+			if _, ok := config.ParseSecretRef(value); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (a *uiApp) handleOutputKey(key tui.Key) {
+	panel := a.output
+	_, height := a.term.Size()
+	page := max(bodyHeight(height)-3, 1)
+	switch key.Code {
+	case tui.KeyCtrlC:
+		if panel.running() {
+			panel.cancel()
+			return
+		}
+		a.closeOutput()
+	case tui.KeyEnter, tui.KeyEscape:
+		if !panel.running() {
+			a.closeOutput()
+		}
+	case tui.KeyRune:
+		if key.Rune == 'q' && !panel.running() {
+			a.closeOutput()
+		}
+	case tui.KeyUp:
+		panel.scroll(1)
+	case tui.KeyDown:
+		panel.scroll(-1)
+	case tui.KeyPageUp:
+		panel.scroll(page)
+	case tui.KeyPageDown:
+		panel.scroll(-page)
+	}
+}
+
+func (a *uiApp) closeOutput() {
+	a.output = nil
+	a.mode = modeBrowse
+	a.rebuildTree()
+}
+
+// showDiagnostics 在输出面板中展示配置提示
+func (a *uiApp) showDiagnostics() {
+	if len(a.cfg.Diagnostics) == 0 {
+		a.message = ui.Gray(i18n.T("ui.no_diagnostics"))
+		return
+	}
+	panel := newOutputPanel(i18n.T("ui.diagnostics"))
+	panel.title = i18n.T("ui.diagnostics_title", len(a.cfg.Diagnostics))
+	for _, diag := range a.cfg.Diagnostics {
+		fmt.Fprintf(panel, "[%s] %s\n", diag.Level, diag.Message)
+	}
+	panel.finish(executor.Result{}, nil)
+	panel.static = true
+	a.output = panel
+	a.mode = modeOutput
+}
+
+// outputPanel 收集命令输出并记录执行状态，实现 io.Writer 供执行器实时写入
+type outputPanel struct {
+	mu       sync.Mutex
+	label    string
+	title    string
+	lines    []string
+	partial  string
+	started  time.Time
+	done     bool
+	result   executor.Result
+	err      error
+	cancel   context.CancelFunc
+	offset   int
+	dryRun   bool
+	static   bool
+	notified bool
+}
+
+func newOutputPanel(label string) *outputPanel {
+	return &outputPanel{label: label, title: i18n.T("ui.output_title", label), started: time.Now()}
+}
+
+// Write 按行追加输出，\r 覆盖当前行以兼容进度条
+func (p *outputPanel) Write(data []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	text := strings.ReplaceAll(string(data), "\t", "    ")
+	for _, r := range text {
+		switch r {
+		case '\n':
+			p.lines = append(p.lines, p.partial)
+			p.partial = ""
+		case '\r':
+			p.partial = ""
+		default:
+			p.partial += string(r)
+		}
+	}
+	if overflow := len(p.lines) - maxOutputLines; overflow > 0 {
+		p.lines = append([]string(nil), p.lines[overflow:]...)
+	}
+	return len(data), nil
+}
+
+func (p *outputPanel) finish(result executor.Result, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.partial != "" {
+		p.lines = append(p.lines, p.partial)
+		p.partial = ""
+	}
+	p.done = true
+	p.result = result
+	p.err = err
+}
+
+func (p *outputPanel) running() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return !p.done
+}
+
+// scroll 向上（正数）或向下滚动输出，offset 为距离底部的行数
+func (p *outputPanel) scroll(delta int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.offset = min(max(p.offset+delta, 0), max(len(p.lines)-1, 0))
+}
+
+// snapshot 返回当前的输出行与状态，供绘制使用
+func (p *outputPanel) snapshot() ([]string, bool, executor.Result, error, int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	lines := append([]string(nil), p.lines...)
+	if p.partial != "" {
+		lines = append(lines, p.partial)
+	}
+	return lines, p.done, p.result, p.err, p.offset
+}
+
+func trimLastRune(text string) string {
+	runes := []rune(text)
+	if len(runes) == 0 {
+		return text
+	}
+	return string(runes[:len(runes)-1])
+}
+
+// lastRunSummary 返回命令最近一次执行的状态描述
+func lastRunSummary(stats *metrics.Store, label string) string {
+	if stats == nil {
+		return ""
+	}
+	entry, ok := stats.Commands[label]
+	if !ok || entry.Runs == 0 {
+		return ""
+	}
+	status := ui.Green(i18n.T("batch.status.succeeded"))
+	if entry.LastFailed {
+		status = ui.Red(i18n.T("batch.status.failed"))
+	}
+	return i18n.T("ui.last_run", entry.LastRun.Local().Format("2006-01-02 15:04:05"), status, entry.Runs, entry.Failures)
+}
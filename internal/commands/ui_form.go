@@ -0,0 +1,240 @@
+package commands
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kballard/go-shellquote"
+	"github.com/spf13/cobra"
+
+	"github.com/alpen/alpen-cli/internal/config"
+	"github.com/alpen/alpen-cli/internal/i18n"
+)
+
+// formField 为表单中的一个输入项
+type formField struct {
+	// Name 为参数名，额外参数、执行确认与批量执行的设置使用以 \x00 开头的名称
+	Name        string
+	Label       string
+	Hint        string
+	Placeholder string
+	Value       string
+	Required    bool
+	// Choices 非空时为选择项，Value 只能取其中之一，通过 ←/→ 或空格切换
+	Choices []string
+	// Expected 为执行确认需要输入的内容
+	Expected string
+}
+
+const (
+	fieldArgs    = "\x00args"
+	fieldConfirm = "\x00confirm"
+	fieldMode    = "\x00mode"
+	fieldJobs    = "\x00jobs"
+	fieldFailure = "\x00failure"
+)
+
+// 批量执行表单中执行方式与失败处理的选择项在 Choices 中的位置
+const (
+	choiceSequential = iota
+	choiceParallel
+)
+
+const (
+	choiceStop = iota
+	choiceContinue
+)
+
+// defaultBatchJobs 为并行执行时默认的并发上限
+const defaultBatchJobs = 4
+
+// paramForm 为执行前填写参数与执行确认的表单；option 为空时为多条命令的批量执行表单
+type paramForm struct {
+	option *menuOption
+	batch  []*menuOption
+	fields []formField
+	focus  int
+	err    string
+}
+
+func newParamForm(cmd *cobra.Command, option *menuOption, environment string) *paramForm {
+	form := &paramForm{option: option}
+	form.fields = append(form.fields, formField{
+		Name:  fieldArgs,
+		Label: i18n.T("form.args"),
+		Hint:  i18n.T("form.args_hint"),
+	})
+	target := option.Target
+	names := make([]string, 0, len(target.Params))
+	for name := range target.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		spec := target.Params[name]
+		field := formField{Name: name, Label: name, Hint: strings.TrimSpace(spec.Description), Required: spec.Required()}
+		if spec.Default != nil {
+			field.Placeholder = i18n.T("form.default", *spec.Default)
+		}
+		form.fields = append(form.fields, field)
+	}
+	if field, ok := confirmField(cmd, target, environment, i18n.T("form.confirm")); ok {
+		form.fields = append(form.fields, field)
+	}
+	return form
+}
+
+// newBatchForm 创建批量执行表单：执行方式、并发数、失败时的处理，以及每条需要确认的命令的执行确认
+func newBatchForm(cmd *cobra.Command, options []*menuOption, environment string) *paramForm {
+	form := &paramForm{batch: options}
+	form.fields = append(form.fields,
+		newChoiceField(fieldMode, i18n.T("form.mode"), i18n.T("form.mode.sequential"), i18n.T("form.mode.parallel")),
+		formField{Name: fieldJobs, Label: i18n.T("form.jobs"), Placeholder: i18n.T("form.default_jobs", defaultBatchJobs), Hint: i18n.T("form.jobs_hint")},
+		newChoiceField(fieldFailure, i18n.T("form.failure"), i18n.T("form.failure.stop"), i18n.T("form.failure.continue")),
+	)
+	for _, option := range options {
+		if field, ok := confirmField(cmd, option.Target, environment, i18n.T("form.confirm_command", option.Label())); ok {
+			form.fields = append(form.fields, field)
+		}
+	}
+	return form
+}
+
+// newChoiceField 创建默认选中第一项的选择项
+func newChoiceField(name, label string, choices ...string) formField {
+	return formField{Name: name, Label: label, Choices: choices, Value: choices[0]}
+}
+
+// choice 返回当前选中项在 Choices 中的位置
+func (f formField) choice() int {
+	return slices.Index(f.Choices, f.Value)
+}
+
+// confirmField 返回命令需要的执行确认输入项，无需确认、已指定 --yes 或 --dry-run 时返回 false
+func confirmField(cmd *cobra.Command, target config.Target, environment, label string) (formField, bool) {
+	required, protectedEnv := target.GuardFor(environment)
+	assumeYes, _ := cmd.Root().PersistentFlags().GetBool("yes")
+	if !required || assumeYes || dryRunRequested(cmd) {
+		return formField{}, false
+	}
+	field := formField{Name: fieldConfirm, Label: label, Required: true}
+	if protectedEnv {
+		field.Expected = environment
+		field.Hint = i18n.T("form.hint.env", environment)
+	} else {
+		field.Expected = target.Label()
+		field.Hint = i18n.T("form.hint.command", target.Label())
+	}
+	if message := strings.TrimSpace(target.Confirm.Message); message != "" {
+		field.Hint = message + i18n.T("common.clause_sep") + field.Hint
+	}
+	return field, true
+}
+
+// needsConfirm 判断表单是否包含执行确认
+func (f *paramForm) needsConfirm() bool {
+	return slices.ContainsFunc(f.fields, func(field formField) bool {
+		return field.Name == fieldConfirm
+	})
+}
+
+// cycle 切换当前选择项的值，delta 为 1 或 -1
+func (f *paramForm) cycle(delta int) bool {
+	field := &f.fields[f.focus]
+	if len(field.Choices) == 0 {
+		return false
+	}
+	index := max(field.choice(), 0)
+	field.Value = field.Choices[(index+delta+len(field.Choices))%len(field.Choices)]
+	return true
+}
+
+// fill 以上次执行的参数预填表单，并将焦点移到执行确认
+func (f *paramForm) fill(args []string, params map[string]string) {
+	for i := range f.fields {
+		field := &f.fields[i]
+		switch field.Name {
+		case fieldArgs:
+			field.Value = shellquote.Join(args...)
+		case fieldConfirm:
+			f.focus = i
+		default:
+			field.Value = params[field.Name]
+		}
+	}
+}
+
+// checkConfirm 校验全部执行确认的输入
+func (f *paramForm) checkConfirm() error {
+	for _, field := range f.fields {
+		if field.Name == fieldConfirm && strings.TrimSpace(field.Value) != field.Expected {
+			return errGuardDeclined
+		}
+	}
+	return nil
+}
+
+// submit 校验表单并返回额外参数与声明参数
+func (f *paramForm) submit() ([]string, map[string]string, error) {
+	var args []string
+	params := map[string]string{}
+	for _, field := range f.fields {
+		value := strings.TrimSpace(field.Value)
+		switch field.Name {
+		case fieldArgs:
+			if value == "" {
+				continue
+			}
+			parts, err := shellquote.Split(value)
+			if err != nil {
+				return nil, nil, i18n.Errorf("form.args_invalid", err)
+			}
+			args = parts
+		case fieldConfirm:
+		default:
+			if value == "" {
+				if field.Required {
+					return nil, nil, i18n.Errorf("form.param_required", field.Name)
+				}
+				continue
+			}
+			params[field.Name] = value
+		}
+	}
+	if err := f.checkConfirm(); err != nil {
+		return nil, nil, err
+	}
+	return args, params, nil
+}
+
+// submitBatch 校验批量执行表单并返回并发上限与失败后是否继续
+func (f *paramForm) submitBatch() (int, bool, error) {
+	jobs, keepGoing := 1, false
+	for _, field := range f.fields {
+		switch field.Name {
+		case fieldMode:
+			if field.choice() == choiceParallel {
+				jobs = defaultBatchJobs
+			}
+		case fieldFailure:
+			keepGoing = field.choice() == choiceContinue
+		}
+	}
+	for _, field := range f.fields {
+		value := strings.TrimSpace(field.Value)
+		if field.Name != fieldJobs || value == "" || jobs == 1 {
+			continue
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			return 0, false, i18n.Errorf("form.jobs_invalid")
+		}
+		jobs = parsed
+	}
+	if err := f.checkConfirm(); err != nil {
+		return 0, false, err
+	}
+	return jobs, keepGoing, nil
+}
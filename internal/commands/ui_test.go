@@ -0,0 +1,227 @@
+package commands
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alpen/alpen-cli/internal/config"
+	"github.com/alpen/alpen-cli/internal/executor"
+	"github.com/alpen/alpen-cli/internal/history"
+)
+
+func strPtr(value string) *string {
+	return &value
+}
+
+func newMenuTestConfig() *config.Config {
+	return &config.Config{
+		Namespaces: []string{"ops"},
+		Commands: map[string]config.CommandSpec{
+			"build": {
+				Description: "构建项目",
+				Command:     "make",
+				Actions: map[string]config.ActionSpec{
+					"docker": {Description: "构建镜像", Alias: "img", Command: "docker build ."},
+				},
+			},
+			"ops deploy": {
+				Description: "部署服务",
+				Command:     "./deploy.sh",
+			},
+			"tools": {
+				Description: "工具集",
+				Actions: map[string]config.ActionSpec{
+					"lint": {Description: "代码检查", Command: "golangci-lint run"},
+				},
+			},
+		},
+	}
+}
+
+func rowLabels(rows []menuRow) []string {
+	labels := make([]string, 0, len(rows))
+	for _, row := range rows {
+		labels = append(labels, row.Node.Name)
+	}
+	return labels
+}
+
+func TestBuildMenuTreeGroupsNamespacesAndActions(t *testing.T) {
+	cfg := newMenuTestConfig()
+	roots := buildMenuTree(cfg, buildMenuOptions(cfg, nil))
+
+	rows := visibleRows(roots, "")
+	want := []string{"build", "docker", "ops", "deploy", "tools", "lint"}
+	if got := rowLabels(rows); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected rows: %v", got)
+	}
+	if rows[0].Node.Option == nil || rows[0].Node.Option.Label() != "build" {
+		t.Fatalf("expected build to be executable")
+	}
+	if rows[2].Node.Option != nil || rows[2].Node.Description != "命名空间 ops" {
+		t.Fatalf("expected ops to be a namespace group, got %+v", rows[2].Node)
+	}
+	if rows[3].Depth != 1 || rows[3].Node.Option.Label() != "ops deploy" {
+		t.Fatalf("expected deploy under ops, got %+v", rows[3])
+	}
+	if rows[4].Node.Option != nil || rows[4].Node.Description != "工具集" {
+		t.Fatalf("expected tools to be a group with its description")
+	}
+
+	rows[0].Node.Expanded = false
+	if got := rowLabels(visibleRows(roots, "")); !reflect.DeepEqual(got, []string{"build", "ops", "deploy", "tools", "lint"}) {
+		t.Fatalf("collapsed node should hide children, got %v", got)
+	}
+}
+
+func TestVisibleRowsFiltersAndKeepsAncestors(t *testing.T) {
+	cfg := newMenuTestConfig()
+	roots := buildMenuTree(cfg, buildMenuOptions(cfg, nil))
+	for _, node := range roots {
+		node.Expanded = false
+	}
+
+	if got := rowLabels(visibleRows(roots, "IMG")); !reflect.DeepEqual(got, []string{"build", "docker"}) {
+		t.Fatalf("alias filter should show match with its parent, got %v", got)
+	}
+	if got := rowLabels(visibleRows(roots, "部署")); !reflect.DeepEqual(got, []string{"ops", "deploy"}) {
+		t.Fatalf("description filter should ignore collapsed state, got %v", got)
+	}
+	if got := rowLabels(visibleRows(roots, "tools")); !reflect.DeepEqual(got, []string{"tools", "lint"}) {
+		t.Fatalf("matching group should show all children, got %v", got)
+	}
+	if got := visibleRows(roots, "missing"); len(got) != 0 {
+		t.Fatalf("expected no rows, got %v", rowLabels(got))
+	}
+}
+
+func TestMenuTreeRankedByHistoryWithShortcuts(t *testing.T) {
+	cfg := newMenuTestConfig()
+	options := buildMenuOptions(cfg, nil)
+	now := time.Now()
+	hist := &history.Store{}
+	for i := 0; i < 3; i++ {
+		hist.Record("tools lint", nil, nil, now.Add(-time.Hour))
+	}
+	hist.Record("ops deploy", []string{"v2"}, nil, now)
+	hist.Record("removed", nil, nil, now)
+	hist.Pin("build docker")
+	hist.Pin("removed")
+
+	roots := buildMenuTree(cfg, options)
+	rankMenuTree(roots, hist, now)
+	roots = append(shortcutSections(options, hist), roots...)
+
+	want := []string{
+		"★ 置顶", "build docker",
+		"↻ 最近使用", "ops deploy", "tools lint",
+		"tools", "lint", "ops", "deploy", "build", "docker",
+	}
+	if got := rowLabels(visibleRows(roots, "")); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected ranked rows: %v", got)
+	}
+	if roots[0].Children[0].Option != &options[1] {
+		t.Fatalf("shortcut should reference the menu option")
+	}
+	if got := rowLabels(visibleRows(roots, "deploy")); !reflect.DeepEqual(got, []string{"ops", "deploy"}) {
+		t.Fatalf("shortcut sections should be hidden while filtering, got %v", got)
+	}
+	if sections := shortcutSections(options, &history.Store{}); len(sections) != 0 {
+		t.Fatalf("empty history should not add sections")
+	}
+}
+
+func TestParamFormSubmit(t *testing.T) {
+	cmd := newGuardTestCommand("", false)
+	option := &menuOption{
+		Path: []string{"db", "drop"},
+		Target: config.Target{
+			Path:      []string{"db", "drop"},
+			Protected: []string{"prod"},
+			Params: map[string]config.ParamSpec{
+				"table":  {},
+				"schema": {Default: strPtr("public")},
+			},
+		},
+	}
+	form := newParamForm(cmd, option, "prod")
+	names := make([]string, 0, len(form.fields))
+	for _, field := range form.fields {
+		names = append(names, field.Name)
+	}
+	if want := []string{fieldArgs, "schema", "table", fieldConfirm}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("unexpected fields: %q", names)
+	}
+
+	if _, _, err := form.submit(); err == nil {
+		t.Fatalf("expected missing required param to fail")
+	}
+	form.fields[0].Value = `--force "a b"`
+	form.fields[2].Value = "users"
+	form.fields[3].Value = "dev"
+	if _, _, err := form.submit(); !errors.Is(err, errGuardDeclined) {
+		t.Fatalf("expected guard mismatch, got %v", err)
+	}
+	form.fields[3].Value = "prod"
+	args, params, err := form.submit()
+	if err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+	if !reflect.DeepEqual(args, []string{"--force", "a b"}) {
+		t.Fatalf("unexpected args: %q", args)
+	}
+	if !reflect.DeepEqual(params, map[string]string{"table": "users"}) {
+		t.Fatalf("defaults should be left to the executor, got %v", params)
+	}
+
+	if form := newParamForm(newGuardTestCommand("", true), option, "prod"); len(form.fields) != 3 {
+		t.Fatalf("--yes should skip the confirm field")
+	}
+}
+
+func TestParamFormFillFocusesConfirm(t *testing.T) {
+	option := &menuOption{
+		Path: []string{"db", "drop"},
+		Target: config.Target{
+			Path:    []string{"db", "drop"},
+			Confirm: config.ConfirmSpec{Enabled: true},
+			Params:  map[string]config.ParamSpec{"table": {}},
+		},
+	}
+	form := newParamForm(newGuardTestCommand("", false), option, "")
+	form.fill([]string{"--force", "a b"}, map[string]string{"table": "users"})
+	if form.fields[form.focus].Name != fieldConfirm {
+		t.Fatalf("expected focus on confirm field, got %q", form.fields[form.focus].Name)
+	}
+	form.fields[form.focus].Value = "db drop"
+	args, params, err := form.submit()
+	if err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+	if !reflect.DeepEqual(args, []string{"--force", "a b"}) || params["table"] != "users" {
+		t.Fatalf("unexpected prefilled values: %q %v", args, params)
+	}
+}
+
+func TestOutputPanelHandlesCarriageReturnAndLimit(t *testing.T) {
+	panel := newOutputPanel("build")
+	panel.Write([]byte("step\t1\nprogress 10%\rprogress 100%\npartial"))
+	lines, done, _, _, _ := panel.snapshot()
+	if want := []string{"step    1", "progress 100%", "partial"}; !reflect.DeepEqual(lines, want) {
+		t.Fatalf("unexpected lines: %q", lines)
+	}
+	if done {
+		t.Fatalf("panel should still be running")
+	}
+
+	for i := 0; i < maxOutputLines+10; i++ {
+		panel.Write([]byte("x\n"))
+	}
+	panel.finish(executor.Result{}, nil)
+	lines, done, _, _, _ = panel.snapshot()
+	if len(lines) != maxOutputLines || !done {
+		t.Fatalf("expected %d lines after finish, got %d", maxOutputLines, len(lines))
+	}
+}
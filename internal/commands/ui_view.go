@@ -0,0 +1,374 @@
+package commands
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kballard/go-shellquote"
+
+	"github.com/alpen/alpen-cli/internal/i18n"
+	"github.com/alpen/alpen-cli/internal/tui"
+	"github.com/alpen/alpen-cli/internal/ui"
+)
+
+// bodyHeight 返回去除标题栏与状态栏后的主体高度
+func bodyHeight(height int) int {
+	return max(height-4, 1)
+}
+
+// treeWidth 返回左侧命令树的宽度
+func treeWidth(rows []menuRow, width int) int {
+	longest := 0
+	for _, row := range rows {
+		longest = max(longest, tui.Width(treeLabel(row, false)))
+	}
+	return min(max(longest+2, 24), max(width*2/5, 1))
+}
+
+// render 重绘整个界面
+func (a *uiApp) render() {
+	width, height := a.term.Size()
+	body := bodyHeight(height)
+	left := treeWidth(a.rows, width)
+	right := max(width-left-3, 1)
+
+	tree := a.renderTree(left, body)
+	var pane []string
+	switch a.mode {
+	case modeForm:
+		pane = a.renderForm(right)
+	case modeOutput:
+		pane = a.renderOutput(right, body)
+	default:
+		pane = a.renderDetails(right)
+	}
+
+	lines := make([]string, 0, height)
+	lines = append(lines, tui.Fit(a.header(), width), ui.Gray(strings.Repeat(ui.Icons().Line, width)))
+	for i := 0; i < body; i++ {
+		var treeLine, paneLine string
+		if i < len(tree) {
+			treeLine = tree[i]
+		}
+		if i < len(pane) {
+			paneLine = pane[i]
+		}
+		lines = append(lines, tui.Fit(treeLine, left)+ui.Gray(" "+ui.Icons().Border+" ")+tui.Fit(paneLine, right))
+	}
+	lines = append(lines, ui.Gray(strings.Repeat(ui.Icons().Line, width)), tui.Fit(a.footer(), width))
+	a.term.Draw(lines)
+}
+
+func (a *uiApp) header() string {
+	icons := ui.Icons()
+	text := " " + ui.Highlight(icons.Menu+" "+i18n.T("ui.header")) + ui.Gray(" "+icons.Bullet+" "+a.configLabel)
+	if dryRunRequested(a.cmd) {
+		text += " " + ui.Yellow("[dry-run]")
+	}
+	if a.filter != "" && a.mode != modeSearch {
+		text += ui.Gray(i18n.T("ui.header.filter")) + a.filter
+	}
+	if len(a.marked) > 0 {
+		text += ui.Green(i18n.T("ui.header.marked", len(a.marked)))
+	}
+	return text
+}
+
+func (a *uiApp) footer() string {
+	if a.message != "" {
+		return " " + a.message
+	}
+	switch a.mode {
+	case modeSearch:
+		return " " + ui.Highlight(i18n.T("ui.search")) + a.filter + ui.Icons().Cursor + ui.Gray(i18n.T("ui.footer.search"))
+	case modeForm:
+		if a.form.option == nil {
+			return ui.Gray(i18n.T("ui.footer.batch_form"))
+		}
+		return ui.Gray(i18n.T("ui.footer.form"))
+	case modeOutput:
+		return " " + a.outputStatus()
+	}
+	if len(a.marked) > 0 {
+		return ui.Gray(i18n.T("ui.footer.marked", len(a.marked)))
+	}
+	return ui.Gray(i18n.T("ui.footer.browse"))
+}
+
+// treeLabel 返回命令树中一行的纯文本内容（不含光标标记），marked 表示命令已加入批量执行
+func treeLabel(row menuRow, marked bool) string {
+	icons := ui.Icons()
+	icon := icons.Bullet
+	switch {
+	case marked:
+		icon = icons.Done
+	case len(row.Node.Children) > 0:
+		icon = icons.Collapsed
+		if row.Node.Expanded {
+			icon = icons.Expanded
+		}
+	}
+	return strings.Repeat("  ", row.Depth) + icon + " " + row.Node.Name
+}
+
+func (a *uiApp) renderTree(width, height int) []string {
+	if len(a.rows) == 0 {
+		return []string{ui.Gray(i18n.T("ui.no_match"))}
+	}
+	if a.cursor < a.offset {
+		a.offset = a.cursor
+	}
+	if a.cursor >= a.offset+height {
+		a.offset = a.cursor - height + 1
+	}
+	a.offset = min(a.offset, max(len(a.rows)-height, 0))
+
+	var lines []string
+	for i := a.offset; i < len(a.rows) && i < a.offset+height; i++ {
+		row := a.rows[i]
+		marked := row.Node.Option != nil && slices.Contains(a.marked, row.Node.Option)
+		label := treeLabel(row, marked)
+		alias := ""
+		if row.Node.Alias != "" {
+			alias = " (" + row.Node.Alias + ")"
+		}
+		unavailable := row.Node.Option != nil && row.Node.Option.Unavailable != ""
+		if i == a.cursor {
+			// 反色显示时不能夹带其他颜色，否则重置序列会提前结束反色
+			lines = append(lines, ui.Reverse(tui.Fit(ui.Icons().Pointer+label+alias, width)))
+			continue
+		}
+		if unavailable {
+			lines = append(lines, ui.Gray(" "+label+alias))
+			continue
+		}
+		if marked {
+			lines = append(lines, " "+ui.Green(label)+ui.Gray(alias))
+			continue
+		}
+		lines = append(lines, " "+label+ui.Gray(alias))
+	}
+	return lines
+}
+
+// detailWriter 按面板宽度组织“字段: 值”形式的详情行
+type detailWriter struct {
+	width int
+	lines []string
+}
+
+func (d *detailWriter) line(text string) {
+	d.lines = append(d.lines, text)
+}
+
+func (d *detailWriter) field(label, value string) {
+	if strings.TrimSpace(value) == "" {
+		return
+	}
+	prefix := label + ": "
+	indent := strings.Repeat(" ", tui.Width(prefix))
+	for i, part := range tui.Wrap(value, max(d.width-tui.Width(prefix), 1)) {
+		if i == 0 {
+			d.line(ui.Gray(prefix) + part)
+			continue
+		}
+		d.line(indent + part)
+	}
+}
+
+func (a *uiApp) renderDetails(width int) []string {
+	node := a.selected()
+	if node == nil {
+		return nil
+	}
+	d := &detailWriter{width: width}
+	d.line(ui.Highlight(strings.Join(node.Path, " ")))
+	d.line("")
+	d.field(i18n.T("ui.detail.description"), node.Description)
+	d.field(i18n.T("ui.detail.alias"), node.Alias)
+	if node.Option == nil {
+		d.field(i18n.T("ui.detail.actions"), i18n.T("ui.detail.actions_hint", len(node.Children)))
+		return d.lines
+	}
+
+	target := node.Option.Target
+	if target.Inline {
+		d.line(ui.Gray(i18n.T("ui.detail.inline")))
+		for _, line := range strings.Split(strings.TrimRight(target.Command, "\n"), "\n") {
+			for _, part := range tui.Wrap(line, max(width-2, 1)) {
+				d.line("  " + ui.Cyan(part))
+			}
+		}
+	} else {
+		d.field(i18n.T("ui.detail.command"), target.Command)
+	}
+	if strings.Contains(target.Command, "{{") {
+		d.line(ui.Gray(i18n.T("ui.detail.template")))
+	}
+	d.field(i18n.T("ui.detail.interpreter"), strings.Join(target.Interpreter, " "))
+	d.field(i18n.T("ui.detail.workdir"), target.WorkDir)
+	d.field(i18n.T("ui.detail.source"), target.Origin.String())
+
+	if len(target.Params) > 0 {
+		d.line(ui.Gray(i18n.T("ui.detail.params")))
+		names := make([]string, 0, len(target.Params))
+		for name := range target.Params {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			spec := target.Params[name]
+			status := ui.Yellow(i18n.T("ui.detail.required"))
+			if spec.Default != nil {
+				status = ui.Gray(i18n.T("form.default", *spec.Default))
+			}
+			text := "  " + ui.Cyan(name) + " " + status
+			if description := strings.TrimSpace(spec.Description); description != "" {
+				text += " " + description
+			}
+			d.line(text)
+		}
+	}
+
+	if summary := lastRunSummary(a.stats, node.Option.Label()); summary != "" {
+		d.line(ui.Gray(i18n.T("ui.detail.last_run")) + summary)
+	} else {
+		d.line(ui.Gray(i18n.T("ui.detail.last_run")) + i18n.T("ui.detail.never"))
+	}
+	if entry := a.lastEntry(node.Option.Label()); entry != nil {
+		d.field(i18n.T("ui.detail.last_args"), describeLastArgs(entry.LastArgs, entry.LastParams))
+	}
+	if a.history.Pinned(node.Option.Label()) {
+		d.line(ui.Cyan(ui.Icons().Pinned + " " + i18n.T("ui.detail.pinned")))
+	}
+	if required, protectedEnv := target.GuardFor(a.environment()); required {
+		if protectedEnv {
+			d.line(ui.Yellow(ui.Icons().Warning + " " + i18n.T("ui.detail.guard_env", a.environment())))
+		} else {
+			d.line(ui.Yellow(ui.Icons().Warning + " " + i18n.T("ui.detail.guard")))
+		}
+	}
+	if node.Option.Unavailable != "" {
+		d.line(ui.Red(i18n.T("list.unavailable") + node.Option.Unavailable))
+	}
+	return d.lines
+}
+
+func (a *uiApp) renderForm(width int) []string {
+	form := a.form
+	d := &detailWriter{width: width}
+	if form.option != nil {
+		d.line(ui.Highlight(i18n.T("ui.form.run", form.option.Label())))
+	} else {
+		d.line(ui.Highlight(i18n.T("ui.form.batch", len(form.batch))))
+		for i, option := range form.batch {
+			d.line(ui.Gray(fmt.Sprintf("  %d. ", i+1)) + option.Label())
+		}
+	}
+	d.line("")
+	for i, field := range form.fields {
+		marker := "  "
+		if i == form.focus {
+			marker = ui.Cyan(ui.Icons().Pointer + " ")
+		}
+		label := field.Label
+		if field.Required {
+			label += ui.Red(" *")
+		}
+		value := field.Value
+		if len(field.Choices) > 0 {
+			choices := make([]string, len(field.Choices))
+			for j, choice := range field.Choices {
+				choices[j] = ui.Gray(choice)
+				if choice == field.Value {
+					choices[j] = ui.Highlight("[" + choice + "]")
+				}
+			}
+			d.line(marker + label + ui.Gray(": ") + strings.Join(choices, " "))
+			continue
+		}
+		if i == form.focus {
+			value += ui.Icons().Cursor
+		}
+		if field.Value == "" && field.Placeholder != "" {
+			value += ui.Gray(field.Placeholder)
+		}
+		d.line(marker + label + ui.Gray(": ") + value)
+		if field.Hint != "" {
+			for _, part := range tui.Wrap(field.Hint, max(width-4, 1)) {
+				d.line("    " + ui.Gray(part))
+			}
+		}
+	}
+	if form.err != "" {
+		d.line("")
+		d.line(ui.Red("x " + form.err))
+	}
+	return d.lines
+}
+
+func (a *uiApp) renderOutput(width, height int) []string {
+	panel := a.output
+	lines, _, _, _, offset := panel.snapshot()
+	result := []string{ui.Highlight(panel.title), ui.Gray(strings.Repeat(ui.Icons().Line, width))}
+	visible := max(height-len(result), 1)
+	end := max(len(lines)-offset, 0)
+	start := max(end-visible, 0)
+	result = append(result, lines[start:end]...)
+	if len(lines) == 0 && !panel.static {
+		result = append(result, ui.Gray(i18n.T("ui.output.empty")))
+	}
+	return result
+}
+
+// describeLastArgs 返回上次执行参数的描述，没有参数时返回“无”
+func describeLastArgs(args []string, params map[string]string) string {
+	var parts []string
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("--param %s=%s", name, params[name]))
+	}
+	if len(args) > 0 {
+		parts = append(parts, shellquote.Join(args...))
+	}
+	if len(parts) == 0 {
+		return i18n.T("ui.none")
+	}
+	return strings.Join(parts, " ")
+}
+
+// outputStatus 返回输出面板对应的状态栏内容
+func (a *uiApp) outputStatus() string {
+	panel := a.output
+	_, done, result, err, offset := panel.snapshot()
+	scroll := ""
+	if offset > 0 {
+		scroll = i18n.T("ui.output.scrolled", offset)
+	}
+	if !done {
+		elapsed := time.Since(panel.started)
+		frames := []rune(ui.Icons().Spinner)
+		frame := frames[int(elapsed/(200*time.Millisecond))%len(frames)]
+		return ui.Yellow(i18n.T("ui.output.running", frame, int(elapsed.Seconds()))) +
+			ui.Gray(i18n.T("ui.output.running_keys")+scroll)
+	}
+	back := ui.Gray(i18n.T("ui.output.back") + scroll)
+	switch {
+	case panel.static:
+		return ui.Gray(i18n.T("ui.output.back_static") + scroll)
+	case panel.dryRun:
+		return ui.Cyan(ui.Icons().Info+" "+i18n.T("ui.output.dry_run")) + back
+	case err != nil:
+		message := strings.ReplaceAll(strings.TrimSpace(err.Error()), "\n", " ")
+		return ui.Red(ui.Icons().Failed+" "+i18n.T("ui.output.failed")+message) + back
+	default:
+		return ui.Green(ui.Icons().Done+" "+i18n.T("ui.output.done")+result.Duration.Round(time.Millisecond).String()) + back
+	}
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/alpen/alpen-cli/internal/i18n"
+)
+
+// NamespaceSeparator 连接命名空间与命令名，命令名本身不允许包含空白字符，因此不会与普通命令冲突
+const NamespaceSeparator = " "
+
+// QualifiedName 返回挂载在命名空间下的命令名，例如 "work deploy"
+func QualifiedName(namespace string, name string) string {
+	if namespace == "" {
+		return name
+	}
+	return namespace + NamespaceSeparator + name
+}
+
+// SplitQualifiedName 拆分命令名中的命名空间，未挂载命名空间时 namespace 为空
+func SplitQualifiedName(name string) (namespace string, short string) {
+	if ns, rest, ok := strings.Cut(name, NamespaceSeparator); ok {
+		return ns, rest
+	}
+	return "", name
+}
+
+// LoadActive 依次加载多个激活的配置并合并为一份命令树：未设置命名空间的配置挂载在根命令下，
+// 其余配置的命令挂载在各自的命名空间下。合并沿用 mergeConfig 的冲突检查
+func (l *Loader) LoadActive(entries []ActiveConfig, env string) (*Config, error) {
+	if len(entries) == 0 {
+		return nil, i18n.Errorf("config.no_active")
+	}
+	result := &Config{Commands: map[string]CommandSpec{}}
+	var diagnostics []Diagnostic
+	namespaces := map[string]bool{}
+	for _, entry := range entries {
+		if entry.Namespace != "" {
+			if err := validateNamespace(entry.Namespace); err != nil {
+				return nil, err
+			}
+			namespaces[entry.Namespace] = true
+		}
+		cfg, err := l.Load(entry.Path, env)
+		if err != nil {
+			return nil, i18n.Errorf("config.load_entry_failed", entry.Label(), err)
+		}
+		diagnostics = append(diagnostics, cfg.Diagnostics...)
+		mounted := mountConfig(cfg, entry.Namespace)
+		if err := mergeConfig(result, mounted, mergeOptions{
+			label: entry.Label(),
+			collect: func(d Diagnostic) {
+				diagnostics = append(diagnostics, d)
+			},
+		}); err != nil {
+			return nil, i18n.Errorf("config.merge_active_failed", err)
+		}
+	}
+	for namespace := range namespaces {
+		if spec, exists := result.Commands[namespace]; exists {
+			return nil, i18n.Errorf("config.namespace_conflict", namespace, namespace, spec.Origin.String())
+		}
+		result.Namespaces = append(result.Namespaces, namespace)
+	}
+	sort.Strings(result.Namespaces)
+	if err := validateMountedAliases(result); err != nil {
+		return nil, err
+	}
+	result.Diagnostics = diagnostics
+	l.diagnostics = diagnostics
+	return result, nil
+}
+
+// mountConfig 将文件级 vars 下放到各命令中，避免不同配置的 vars 互相影响，
+// 并在设置了命名空间时为命令名加上前缀
+func mountConfig(cfg *Config, namespace string) *Config {
+	scopeFileVars(cfg)
+	mounted := &Config{Commands: make(map[string]CommandSpec, len(cfg.Commands))}
+	for name, spec := range cfg.Commands {
+		mounted.Commands[QualifiedName(namespace, name)] = spec
+	}
+	return mounted
+}
+
+// validateMountedAliases 检查同一命名空间下的命令别名不重复，各配置自身已在加载时校验
+func validateMountedAliases(cfg *Config) error {
+	owners := map[string]string{}
+	for _, name := range cfg.SortedCommandNames() {
+		alias := strings.TrimSpace(cfg.Commands[name].Alias)
+		if alias == "" {
+			continue
+		}
+		namespace, _ := SplitQualifiedName(name)
+		key := QualifiedName(namespace, alias)
+		if owner, exists := owners[key]; exists {
+			return i18n.Errorf("config.alias_conflict", name, alias, owner)
+		}
+		if _, exists := cfg.Commands[key]; exists && key != name {
+			return i18n.Errorf("config.alias_conflict", name, alias, key)
+		}
+		owners[key] = name
+	}
+	return nil
+}
+
+func validateNamespace(namespace string) error {
+	if err := validateIdentifier(i18n.T("config.label.namespace"), namespace); err != nil {
+		return err
+	}
+	if strings.ContainsAny(namespace, "=/\\") {
+		return i18n.Errorf("config.namespace_invalid", namespace)
+	}
+	return nil
+}
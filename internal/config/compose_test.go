@@ -0,0 +1,104 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoaderLoadActiveMountsNamespaces(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFiles(t, dir, map[string]string{
+		"work.yaml": `vars:
+  region:
+    value: eu
+commands:
+  deploy:
+    alias: d
+    command: ./deploy.sh {{ .vars.region }}
+`,
+		"home.yaml": `commands:
+  deploy:
+    alias: d
+    command: ./home-deploy.sh
+  backup:
+    command: ./backup.sh
+`,
+		"tools.yaml": "commands:\n  lint:\n    command: make lint\n",
+	})
+
+	loader := NewLoader(dir)
+	cfg, err := loader.LoadActive([]ActiveConfig{
+		{Path: "work.yaml", Namespace: "work"},
+		{Path: "home.yaml", Namespace: "home"},
+		{Path: "tools.yaml"},
+	}, "")
+	if err != nil {
+		t.Fatalf("load active failed: %v", err)
+	}
+	if strings.Join(cfg.Namespaces, ",") != "home,work" {
+		t.Fatalf("unexpected namespaces: %v", cfg.Namespaces)
+	}
+	for _, name := range []string{"work deploy", "home deploy", "home backup", "lint"} {
+		if _, ok := cfg.Commands[name]; !ok {
+			t.Fatalf("expected command %q, got %v", name, cfg.SortedCommandNames())
+		}
+	}
+	target, ok := cfg.ResolveTarget([]string{"work deploy"})
+	if !ok || target.Label() != "work deploy" || target.Vars["region"].Value != "eu" {
+		t.Fatalf("file vars should stay with their config: %+v", target)
+	}
+	if home, _ := cfg.ResolveTarget([]string{"home deploy"}); len(home.Vars) != 0 {
+		t.Fatalf("vars leaked into another namespace: %+v", home.Vars)
+	}
+}
+
+func TestLoaderLoadActiveDetectsConflicts(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFiles(t, dir, map[string]string{
+		"a.yaml":    "commands:\n  deploy:\n    command: echo a\n",
+		"b.yaml":    "commands:\n  deploy:\n    command: echo b\n",
+		"work.yaml": "commands:\n  work:\n    command: echo work\n",
+	})
+	loader := NewLoader(dir)
+	if _, err := loader.LoadActive([]ActiveConfig{{Path: "a.yaml"}, {Path: "b.yaml"}}, ""); err == nil || !strings.Contains(err.Error(), "冲突") {
+		t.Fatalf("expected conflict between root mounted configs, got %v", err)
+	}
+	if _, err := loader.LoadActive([]ActiveConfig{{Path: "a.yaml", Namespace: "a"}, {Path: "b.yaml", Namespace: "b"}}, ""); err != nil {
+		t.Fatalf("namespaced configs should not conflict: %v", err)
+	}
+	if _, err := loader.LoadActive([]ActiveConfig{{Path: "work.yaml"}, {Path: "a.yaml", Namespace: "work"}}, ""); err == nil || !strings.Contains(err.Error(), "命名空间 work") {
+		t.Fatalf("expected namespace collision error, got %v", err)
+	}
+}
+
+func TestActiveConfigsRoundTrip(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	configDir := filepath.Join(home, ".alpen", "config")
+	if err := os.MkdirAll(configDir, 0o755); err != nil {
+		t.Fatalf("mkdir failed: %v", err)
+	}
+	work := filepath.Join(configDir, "work.yaml")
+	personal := filepath.Join(configDir, "personal.conf")
+	if err := SaveActiveConfigs([]ActiveConfig{{Path: work, Namespace: "work"}, {Path: personal}}); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	entries, err := LoadActiveConfigs()
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0] != (ActiveConfig{Path: work, Namespace: "work"}) || entries[1] != (ActiveConfig{Path: personal}) {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+	if !IsComposite(entries) {
+		t.Fatalf("expected composite selection")
+	}
+	if path, _ := LoadActiveConfigPath(); path != work {
+		t.Fatalf("expected first active path, got %s", path)
+	}
+	if err := SaveActiveConfigs([]ActiveConfig{{Path: work, Namespace: "a b"}}); err == nil {
+		t.Fatalf("expected invalid namespace to be rejected")
+	}
+}
@@ -0,0 +1,332 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/alpen/alpen-cli/internal/fsutil"
+	"github.com/alpen/alpen-cli/internal/i18n"
+)
+
+// BackupSuffix 为修改配置文件前保存原内容的文件后缀
+const BackupSuffix = ".bak"
+
+// maxRestoreCells 限制恢复空行时比较的行数乘积，超出时不恢复空行
+const maxRestoreCells = 4_000_000
+
+// Entry 为新增命令或子命令时写入的字段，空字段不写入
+type Entry struct {
+	Description string
+	Alias       string
+	Command     string
+}
+
+// Document 为以 yaml.Node 打开的单个配置文件，增删命令后写回时保留注释、键的顺序与空行
+type Document struct {
+	Path     string
+	original []byte
+	exists   bool
+	root     *yaml.Node
+	indent   int
+}
+
+// OpenDocument 打开配置文件用于修改，文件不存在时视为空文件
+func OpenDocument(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	doc := &Document{Path: path, original: data, exists: err == nil, indent: 2}
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, i18n.Errorf("config.edit.parse_failed", path, err)
+	}
+	if root.Kind == 0 {
+		root = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	if len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return nil, i18n.Errorf("config.edit.not_mapping", path)
+	}
+	doc.root = &root
+	if indent := detectIndent(root.Content[0]); indent > 0 {
+		doc.indent = indent
+	}
+	return doc, nil
+}
+
+// AddCommand 在 commands 末尾新增顶层命令，同名命令已存在时返回错误
+func (d *Document) AddCommand(name string, entry Entry) error {
+	commands, err := d.mapping(d.top(), "commands")
+	if err != nil {
+		return err
+	}
+	if hasKey(commands, name) {
+		return i18n.Errorf("config.edit.command_exists", name, d.Path)
+	}
+	commands.Content = append(commands.Content, stringNode(name), entry.node())
+	return nil
+}
+
+// AddAction 为本文件中定义的命令新增子命令，同名子命令已存在时返回错误
+func (d *Document) AddAction(command, action string, entry Entry) error {
+	spec, err := d.command(command)
+	if err != nil {
+		return err
+	}
+	actions, err := d.mapping(spec, "actions")
+	if err != nil {
+		return err
+	}
+	if hasKey(actions, action) {
+		return i18n.Errorf("config.edit.action_exists", command, action, d.Path)
+	}
+	actions.Content = append(actions.Content, stringNode(action), entry.node())
+	return nil
+}
+
+// RemoveCommand 删除本文件中定义的顶层命令
+func (d *Document) RemoveCommand(name string) error {
+	_, commands := directEntry(d.top(), "commands")
+	index, _ := directEntry(commands, name)
+	if index < 0 {
+		return i18n.Errorf("config.edit.command_not_defined", name, d.Path)
+	}
+	commands.Content = append(commands.Content[:index], commands.Content[index+2:]...)
+	return nil
+}
+
+// RemoveAction 删除本文件中定义的子命令，删除后 actions 为空时一并删除 actions
+func (d *Document) RemoveAction(command, action string) error {
+	spec, err := d.command(command)
+	if err != nil {
+		return err
+	}
+	actionsIndex, actions := directEntry(spec, "actions")
+	index, _ := directEntry(actions, action)
+	if index < 0 {
+		return i18n.Errorf("config.edit.action_not_defined", command, action, d.Path)
+	}
+	actions.Content = append(actions.Content[:index], actions.Content[index+2:]...)
+	if len(actions.Content) == 0 {
+		spec.Content = append(spec.Content[:actionsIndex], spec.Content[actionsIndex+2:]...)
+	}
+	return nil
+}
+
+// Bytes 返回修改后的文件内容，沿用原文件的缩进并恢复编码时丢失的空行
+func (d *Document) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(d.indent)
+	if err := encoder.Encode(d.root); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return restoreBlankLines(d.original, buf.Bytes()), nil
+}
+
+// Save 将 data 原子写入配置文件，并把原内容保存到 <文件>.bak；
+// 打开之后文件被其他程序修改时返回错误，避免覆盖他人的修改。
+// 配置文件为符号链接时写入链接指向的文件，链接本身保持不变
+func (d *Document) Save(data []byte) (string, error) {
+	current, err := os.ReadFile(d.Path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+	if (err == nil) != d.exists || !bytes.Equal(current, d.original) {
+		return "", i18n.Errorf("config.edit.changed", d.Path)
+	}
+	path := d.Path
+	perm := os.FileMode(0o644)
+	backup := ""
+	if d.exists {
+		if path, err = filepath.EvalSymlinks(d.Path); err != nil {
+			return "", err
+		}
+		if info, err := os.Stat(path); err == nil {
+			perm = info.Mode().Perm()
+		}
+		backup = path + BackupSuffix
+		if err := fsutil.WriteFileAtomic(backup, d.original, perm); err != nil {
+			return "", i18n.Errorf("config.edit.backup_failed", backup, err)
+		}
+	}
+	if err := fsutil.WriteFileAtomic(path, data, perm); err != nil {
+		return "", err
+	}
+	return backup, nil
+}
+
+func (d *Document) top() *yaml.Node {
+	return d.root.Content[0]
+}
+
+// command 返回本文件中直接定义的命令映射，命令经锚点合并得到时无法修改
+func (d *Document) command(name string) (*yaml.Node, error) {
+	_, commands := directEntry(d.top(), "commands")
+	_, spec := directEntry(commands, name)
+	if spec == nil {
+		return nil, i18n.Errorf("config.edit.command_not_defined", name, d.Path)
+	}
+	if spec.Kind != yaml.MappingNode {
+		return nil, i18n.Errorf("config.edit.not_editable", name, d.Path)
+	}
+	return spec, nil
+}
+
+// mapping 返回 parent 中 key 对应的映射，不存在或为空值时创建
+func (d *Document) mapping(parent *yaml.Node, key string) (*yaml.Node, error) {
+	_, value := directEntry(parent, key)
+	switch {
+	case value == nil:
+		value = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		parent.Content = append(parent.Content, stringNode(key), value)
+	case value.Kind == yaml.ScalarNode && value.ShortTag() == "!!null":
+		*value = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", HeadComment: value.HeadComment, LineComment: value.LineComment}
+	case value.Kind != yaml.MappingNode:
+		return nil, i18n.Errorf("config.edit.not_editable", key, d.Path)
+	case len(value.Content) == 0:
+		// 空的 {} 改为块格式，新增的条目与文件中其他命令的写法一致
+		value.Style &^= yaml.FlowStyle
+	}
+	return value, nil
+}
+
+func (e Entry) node() *yaml.Node {
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	fields := [][2]string{{"description", e.Description}, {"alias", e.Alias}, {"command", e.Command}}
+	for _, field := range fields {
+		if value := strings.TrimSpace(field[1]); value != "" {
+			node.Content = append(node.Content, stringNode(field[0]), stringNode(value))
+		}
+	}
+	return node
+}
+
+func stringNode(value string) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+	if strings.Contains(value, "\n") {
+		node.Style = yaml.LiteralStyle
+	}
+	return node
+}
+
+// directEntry 返回映射中直接写出（而非经 << 合并得到）的键在 Content 中的位置及其值，不存在时位置为 -1
+func directEntry(node *yaml.Node, key string) (int, *yaml.Node) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return -1, nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key && node.Content[i].ShortTag() != "!!merge" {
+			return i, node.Content[i+1]
+		}
+	}
+	return -1, nil
+}
+
+// hasKey 判断映射中是否已有 key，包括经 << 合并得到的键
+func hasKey(node *yaml.Node, key string) bool {
+	keyNode, _ := mappingEntry(node, key)
+	return keyNode != nil
+}
+
+// detectIndent 按第一个块映射中子键与父键的列差推断文件的缩进宽度，无法推断时返回 0
+func detectIndent(node *yaml.Node) int {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if value.Kind != yaml.MappingNode || value.Style&yaml.FlowStyle != 0 || len(value.Content) == 0 {
+			continue
+		}
+		if indent := value.Content[0].Column - key.Column; indent > 0 {
+			return indent
+		}
+	}
+	return 0
+}
+
+// restoreBlankLines 按最长公共子序列对齐原内容与重新编码的内容，把编码时丢失的空行放回原处；
+// 被删除的内容前后各有空行时只保留一行
+func restoreBlankLines(original []byte, updated []byte) []byte {
+	before, after := splitLines(original), splitLines(updated)
+	if len(before) == 0 || len(before)*len(after) > maxRestoreCells {
+		return updated
+	}
+	// common[i][j] 为 before[i:] 与 after[j:] 的最长公共子序列长度
+	common := make([][]int32, len(before)+1)
+	for i := range common {
+		common[i] = make([]int32, len(after)+1)
+	}
+	for i := len(before) - 1; i >= 0; i-- {
+		for j := len(after) - 1; j >= 0; j-- {
+			if before[i] == after[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+	kept := make([]bool, len(before))
+	for i, j := 0, 0; i < len(before) && j < len(after); {
+		switch {
+		case before[i] == after[j]:
+			kept[i] = true
+			i++
+			j++
+		case common[i][j+1] >= common[i+1][j]:
+			j++
+		default:
+			i++
+		}
+	}
+
+	var out []string
+	emit := func(line string) {
+		if line == "" && (len(out) == 0 || out[len(out)-1] == "") {
+			return
+		}
+		out = append(out, line)
+	}
+	i, j := 0, 0
+	for i < len(before) || j < len(after) {
+		switch {
+		case i < len(before) && j < len(after) && before[i] == after[j]:
+			out = append(out, after[j])
+			i++
+			j++
+		case j < len(after) && (i >= len(before) || common[i][j+1] >= common[i+1][j]):
+			out = append(out, after[j])
+			j++
+		default:
+			if before[i] == "" && nextKept(before, kept, i) {
+				emit("")
+			}
+			i++
+		}
+	}
+	return []byte(strings.Join(out, "\n") + "\n")
+}
+
+// nextKept 判断 lines[i] 之后的第一个非空行是否保留在新内容中
+func nextKept(lines []string, kept []bool, i int) bool {
+	for i++; i < len(lines); i++ {
+		if lines[i] != "" {
+			return kept[i]
+		}
+	}
+	return false
+}
+
+func splitLines(data []byte) []string {
+	text := strings.TrimSuffix(string(data), "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
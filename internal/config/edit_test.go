@@ -0,0 +1,192 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const editSample = `# 团队命令
+commands:
+  system:
+    # 描述：日常维护
+    description: 系统维护
+    alias: sys
+    command: "echo system"
+    actions:
+      update:
+        command: echo update # 行尾注释
+
+  tools:
+    description: 工具
+    command: echo tools
+
+# 文件级变量
+vars:
+  region: cn
+`
+
+func openSample(t *testing.T, content string) (*Document, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "demo.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	doc, err := OpenDocument(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	return doc, path
+}
+
+func TestDocumentAddKeepsCommentsAndBlankLines(t *testing.T) {
+	doc, path := openSample(t, editSample)
+	if err := doc.AddCommand("deploy", Entry{Description: "发布", Alias: "dp", Command: "make deploy"}); err != nil {
+		t.Fatalf("add command: %v", err)
+	}
+	if err := doc.AddAction("system", "clean", Entry{Command: "echo clean"}); err != nil {
+		t.Fatalf("add action: %v", err)
+	}
+	if err := doc.AddCommand("system", Entry{Command: "echo"}); err == nil {
+		t.Fatalf("expected duplicate command to fail")
+	}
+	data, err := doc.Bytes()
+	if err != nil {
+		t.Fatalf("bytes: %v", err)
+	}
+	output := string(data)
+	for _, want := range []string{
+		"# 团队命令\n",
+		"    # 描述：日常维护\n",
+		"command: echo update # 行尾注释\n      clean:\n        command: echo clean\n\n  tools:",
+		"    command: echo tools\n  deploy:\n    description: 发布\n    alias: dp\n    command: make deploy\n\n# 文件级变量\nvars:",
+	} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("output should contain %q, got:\n%s", want, output)
+		}
+	}
+
+	backup, err := doc.Save(data)
+	if err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if saved, _ := os.ReadFile(backup); string(saved) != editSample {
+		t.Fatalf("backup should keep the original content, got:\n%s", saved)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Fatalf("save should keep the file mode, got %v", info.Mode().Perm())
+	}
+	cfg, err := NewLoader("").Load(path, "")
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if cfg.Commands["deploy"].Alias != "dp" || cfg.Commands["system"].Actions["clean"].Command != "echo clean" {
+		t.Fatalf("unexpected config after save: %+v", cfg.Commands)
+	}
+}
+
+func TestDocumentRemoveCollapsesBlankLines(t *testing.T) {
+	doc, _ := openSample(t, editSample)
+	if err := doc.RemoveAction("system", "update"); err != nil {
+		t.Fatalf("remove action: %v", err)
+	}
+	if err := doc.RemoveCommand("tools"); err != nil {
+		t.Fatalf("remove command: %v", err)
+	}
+	if err := doc.RemoveCommand("missing"); err == nil {
+		t.Fatalf("expected missing command to fail")
+	}
+	data, err := doc.Bytes()
+	if err != nil {
+		t.Fatalf("bytes: %v", err)
+	}
+	want := `# 团队命令
+commands:
+  system:
+    # 描述：日常维护
+    description: 系统维护
+    alias: sys
+    command: "echo system"
+
+# 文件级变量
+vars:
+  region: cn
+`
+	if string(data) != want {
+		t.Fatalf("unexpected output:\n%s", data)
+	}
+}
+
+func TestDocumentSaveDetectsConcurrentChanges(t *testing.T) {
+	doc, path := openSample(t, editSample)
+	if err := doc.AddCommand("deploy", Entry{Command: "make deploy"}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("commands: {}\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	data, _ := doc.Bytes()
+	if _, err := doc.Save(data); err == nil {
+		t.Fatalf("expected save to fail after the file changed")
+	}
+}
+
+func TestLoaderWithContentValidatesBeforeWriting(t *testing.T) {
+	dir := t.TempDir()
+	moduleDir := filepath.Join(dir, "ops.conf")
+	if err := os.MkdirAll(moduleDir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(moduleDir, "base.yaml"), []byte("commands:\n  build:\n    alias: b\n    command: make\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	newFile := filepath.Join(moduleDir, "deploy.yaml")
+	loader := NewLoader(dir)
+	cfg, err := loader.WithContent(newFile, []byte("commands:\n  deploy:\n    command: make deploy\n")).Load("ops.conf", "")
+	if err != nil || cfg.Commands["deploy"].Command != "make deploy" {
+		t.Fatalf("expected pending file to be loaded, got %v", err)
+	}
+	if _, err := loader.WithContent(newFile, []byte("commands:\n  deploy:\n    alias: b\n    command: make deploy\n")).Load("ops.conf", ""); err == nil {
+		t.Fatalf("expected alias conflict in pending content to fail validation")
+	}
+	if _, err := os.Stat(newFile); !os.IsNotExist(err) {
+		t.Fatalf("validation must not create the file, got %v", err)
+	}
+}
+
+func TestDocumentSaveFollowsSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "dotfiles", "demo.yaml")
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(target, []byte(editSample), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	link := filepath.Join(dir, "demo.yaml")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	doc, err := OpenDocument(link)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := doc.RemoveCommand("tools"); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	data, _ := doc.Bytes()
+	backup, err := doc.Save(data)
+	if err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("the symlink should be kept, got %v %v", info, err)
+	}
+	if saved, _ := os.ReadFile(target); string(saved) != string(data) {
+		t.Fatalf("the link target should be updated, got:\n%s", saved)
+	}
+	if backup != target+BackupSuffix {
+		t.Fatalf("backup should be written next to the link target, got %s", backup)
+	}
+}
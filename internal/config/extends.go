@@ -0,0 +1,110 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/alpen/alpen-cli/internal/i18n"
+)
+
+// extendsInherited 为 extends 继承的字段前缀
+var extendsInherited = []string{OriginEnv, OriginVars, OriginParams}
+
+// resolveExtends 展开命令的 extends：继承被扩展命令的 actions、env、vars 与 params，
+// 子命令同名时以当前命令为准，存在循环时返回错误
+func resolveExtends(cfg *Config) error {
+	resolved := map[string]bool{}
+	var visit func(name string, chain []string) error
+	visit = func(name string, chain []string) error {
+		if resolved[name] {
+			return nil
+		}
+		for i, visiting := range chain {
+			if visiting == name {
+				return i18n.Errorf("config.extends_cycle", strings.Join(append(chain[i:], name), " → "))
+			}
+		}
+		spec := cfg.Commands[name]
+		parentName := strings.TrimSpace(spec.Extends)
+		if parentName == "" {
+			resolved[name] = true
+			return nil
+		}
+		parent, ok := cfg.Commands[parentName]
+		if !ok {
+			return i18n.Errorf("config.extends_missing", name, parentName, spec.Origin.String())
+		}
+		if parent.Disabled {
+			return i18n.Errorf("config.extends_disabled", name, parentName, spec.Origin.String())
+		}
+		if err := visit(parentName, append(chain, name)); err != nil {
+			return err
+		}
+		cfg.Commands[name] = inheritCommand(cfg.Commands[parentName], parentName, spec)
+		resolved[name] = true
+		return nil
+	}
+	for _, name := range cfg.SortedCommandNames() {
+		if err := visit(name, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func inheritCommand(parent CommandSpec, parentName string, child CommandSpec) CommandSpec {
+	hop := "extends " + parentName
+	actions := make(map[string]ActionSpec, len(parent.Actions)+len(child.Actions))
+	for name, action := range parent.Actions {
+		action.Origin = withVia(action.Origin, hop)
+		layers := make([]Layer, len(action.Layers))
+		for i, layer := range action.Layers {
+			layers[i] = Layer{Source: withVia(layer.Source, hop), Fields: layer.Fields}
+		}
+		action.Layers = layers
+		actions[name] = action
+	}
+	for name, action := range child.Actions {
+		actions[name] = action
+	}
+	child.Actions = actions
+	child.Env = mergeByKey(parent.Env, child.Env)
+	child.Vars = mergeByKey(parent.Vars, child.Vars)
+	child.Params = mergeByKey(parent.Params, child.Params)
+
+	origins := map[string]SourceInfo{}
+	for key, origin := range parent.FieldOrigins {
+		if isExtendsInherited(key) {
+			origins[key] = origin
+		}
+	}
+	child.FieldOrigins = mergeByKey(origins, child.FieldOrigins)
+
+	var layers []Layer
+	for _, layer := range parent.Layers {
+		var fields []string
+		for _, field := range layer.Fields {
+			if isExtendsInherited(field) {
+				fields = append(fields, field)
+			}
+		}
+		if len(fields) > 0 {
+			layers = append(layers, Layer{Source: withVia(layer.Source, hop), Fields: fields})
+		}
+	}
+	child.Layers = append(layers, child.Layers...)
+	return child
+}
+
+func isExtendsInherited(field string) bool {
+	for _, prefix := range extendsInherited {
+		if strings.HasPrefix(field, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func withVia(source SourceInfo, hop string) SourceInfo {
+	source.Via = append(append([]string(nil), source.Via...), hop)
+	return source
+}
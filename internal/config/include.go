@@ -0,0 +1,321 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/alpen/alpen-cli/internal/i18n"
+)
+
+// includedAnchorsKey 为注入被引用文件锚点时使用的保留顶层键
+const includedAnchorsKey = "__alpen_included_anchors__"
+
+// IncludeList 为 include 指令的取值，支持单个路径或路径列表，路径可使用通配符
+type IncludeList []string
+
+// UnmarshalYAML 支持 include: a.yaml 与 include: [a.yaml, shared/*.yaml] 两种写法
+func (l *IncludeList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*l = IncludeList{node.Value}
+		return nil
+	}
+	var values []string
+	if err := node.Decode(&values); err != nil {
+		return i18n.Errorf("config.include_type", node.Line)
+	}
+	*l = values
+	return nil
+}
+
+// anchorDef 为可供引用方使用的锚点节点及其定义位置
+type anchorDef struct {
+	node   *yaml.Node
+	source SourceInfo
+}
+
+// includeLoader 递归加载配置文件及其 include 的文件，roots 限定可引用的目录，read 用于读取文件内容
+type includeLoader struct {
+	roots []string
+	stack []string
+	read  func(path string) ([]byte, error)
+}
+
+type loadedFile struct {
+	cfg *Config
+	// anchors 为该文件及其引用文件中定义的锚点，按定义顺序排列
+	anchors []anchorDef
+}
+
+// load 读取单个配置文件：先加载 include 的文件，再以其锚点解析当前文件，
+// 最后由当前文件覆盖被引用文件中的同名定义
+func (il *includeLoader) load(path string, source SourceInfo) (*loadedFile, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	for i, loading := range il.stack {
+		if loading == absPath {
+			chain := append(append([]string(nil), il.stack[i:]...), absPath)
+			return nil, i18n.Errorf("config.include_cycle", strings.Join(chain, " → "))
+		}
+	}
+	il.stack = append(il.stack, absPath)
+	defer func() { il.stack = il.stack[:len(il.stack)-1] }()
+
+	data, err := il.read(path)
+	if err != nil {
+		return nil, err
+	}
+	patterns, includeLine, err := extractIncludes(data)
+	if err != nil {
+		return nil, err
+	}
+	var included []*loadedFile
+	var anchors []anchorDef
+	for _, pattern := range patterns {
+		files, err := il.expand(filepath.Dir(absPath), pattern)
+		if err != nil {
+			return nil, i18n.Errorf("config.include_line", path, includeLine, err)
+		}
+		for _, file := range files {
+			childSource := SourceInfo{
+				Module: source.Module,
+				File:   filepath.ToSlash(file),
+				Via:    append(append([]string(nil), source.Via...), fmt.Sprintf("include %s:%d", source.File, includeLine)),
+			}
+			child, err := il.load(file, childSource)
+			if err != nil {
+				return nil, err
+			}
+			included = append(included, child)
+			anchors = append(anchors, child.anchors...)
+		}
+	}
+
+	root, external, err := parseWithAnchors(data, anchors)
+	if err != nil {
+		return nil, err
+	}
+	var cfg Config
+	if err := root.Decode(&cfg); err != nil {
+		return nil, err
+	}
+	if err := normalizeConfig(&cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	registerOrigins(&cfg, source, root, external)
+	anchors = append(anchors, collectAnchors(root, source)...)
+
+	if len(included) == 0 {
+		return &loadedFile{cfg: &cfg, anchors: anchors}, nil
+	}
+	result := &Config{Commands: map[string]CommandSpec{}}
+	for _, child := range included {
+		if err := mergeConfig(result, child.cfg, mergeOptions{label: "include"}); err != nil {
+			return nil, i18n.Errorf("config.include_merge_failed", path, err)
+		}
+	}
+	if err := mergeConfig(result, &cfg, mergeOptions{allowOverride: true}); err != nil {
+		return nil, err
+	}
+	return &loadedFile{cfg: result, anchors: anchors}, nil
+}
+
+// expand 将 include 路径解析为 roots 内的文件列表，支持 ~、环境变量与通配符
+func (il *includeLoader) expand(dir string, pattern string) ([]string, error) {
+	expanded := ExpandPath(pattern)
+	if expanded == "" {
+		return nil, i18n.Errorf("config.include_empty")
+	}
+	if !filepath.IsAbs(expanded) {
+		expanded = filepath.Join(dir, expanded)
+	}
+	matches, err := filepath.Glob(expanded)
+	if err != nil {
+		return nil, i18n.Errorf("config.include_invalid", pattern, err)
+	}
+	if len(matches) == 0 && !strings.ContainsAny(pattern, "*?[") {
+		return nil, i18n.Errorf("config.include_missing", expanded)
+	}
+	sort.Strings(matches)
+	var files []string
+	for _, match := range matches {
+		info, err := os.Stat(match)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			continue
+		}
+		if !il.allowed(match) {
+			return nil, i18n.Errorf("config.include_outside", match)
+		}
+		files = append(files, match)
+	}
+	return files, nil
+}
+
+// allowed 判断文件（解析符号链接后）是否位于任一允许目录内
+func (il *includeLoader) allowed(path string) bool {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return false
+	}
+	for _, root := range il.roots {
+		rootResolved, err := filepath.EvalSymlinks(root)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(rootResolved, resolved)
+		if err != nil {
+			continue
+		}
+		if rel != ".." && !strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// extractIncludes 提取顶层 include 指令。文件可能引用尚未定义的锚点，
+// 因此只截取 include 所在的片段单独解析
+func extractIncludes(data []byte) (IncludeList, int, error) {
+	lines := strings.Split(string(data), "\n")
+	for i, line := range lines {
+		if !strings.HasPrefix(line, "include:") {
+			continue
+		}
+		end := i + 1
+		for end < len(lines) {
+			next := lines[end]
+			trimmed := strings.TrimSpace(next)
+			if trimmed != "" && !strings.HasPrefix(next, " ") && !strings.HasPrefix(next, "\t") &&
+				!strings.HasPrefix(trimmed, "#") && !strings.HasPrefix(next, "-") {
+				break
+			}
+			end++
+		}
+		var snippet struct {
+			Include IncludeList `yaml:"include"`
+		}
+		if err := yaml.Unmarshal([]byte(strings.Join(lines[i:end], "\n")), &snippet); err != nil {
+			return nil, 0, i18n.Errorf("config.include_parse_failed", i+1, err)
+		}
+		return snippet.Include, i + 1, nil
+	}
+	return nil, 0, nil
+}
+
+// parseWithAnchors 解析配置文件，并使被引用文件中定义的锚点在当前文件中可用。
+// 锚点节点被序列化到文件开头的保留键中，解析后行号会还原为原文件中的位置
+func parseWithAnchors(data []byte, anchors []anchorDef) (*yaml.Node, map[*yaml.Node]SourceInfo, error) {
+	var root yaml.Node
+	if len(anchors) == 0 {
+		if err := yaml.Unmarshal(data, &root); err != nil {
+			return nil, nil, err
+		}
+		return &root, nil, nil
+	}
+	holder := &yaml.Node{Kind: yaml.MappingNode}
+	for i, anchor := range anchors {
+		holder.Content = append(holder.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: fmt.Sprintf("a%d", i)}, anchor.node)
+	}
+	prefix, err := yaml.Marshal(&yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{
+		{Kind: yaml.ScalarNode, Value: includedAnchorsKey}, holder,
+	}})
+	if err != nil {
+		return nil, nil, i18n.Errorf("config.include_anchor_failed", err)
+	}
+	offset := bytes.Count(prefix, []byte("\n"))
+	// 文件以 --- 开头时保留行数但去掉文档分隔符，避免被解析为第二个文档
+	if bytes.HasPrefix(data, []byte("---")) {
+		data = append([]byte("#"), data...)
+	}
+	if err := yaml.Unmarshal(append(prefix, data...), &root); err != nil {
+		return nil, nil, shiftErrorLine(err, offset)
+	}
+	doc := root.Content[0]
+	external := map[*yaml.Node]SourceInfo{}
+	injected := doc.Content[1]
+	for i, anchor := range anchors {
+		mapExternal(injected.Content[2*i+1], anchor.node, anchor.source, external)
+	}
+	// 移除保留键，并将当前文件内容的行号还原
+	doc.Content = doc.Content[2:]
+	shiftLines(&root, offset)
+	return &root, external, nil
+}
+
+// mapExternal 将注入后的节点与原始锚点节点一一对应，记录原始文件与行号
+func mapExternal(parsed *yaml.Node, original *yaml.Node, source SourceInfo, external map[*yaml.Node]SourceInfo) {
+	if parsed == nil || original == nil {
+		return
+	}
+	src := source
+	src.Line = original.Line
+	external[parsed] = src
+	if parsed.Kind == yaml.AliasNode || len(parsed.Content) != len(original.Content) {
+		return
+	}
+	for i := range parsed.Content {
+		mapExternal(parsed.Content[i], original.Content[i], source, external)
+	}
+}
+
+func shiftLines(node *yaml.Node, offset int) {
+	if node == nil {
+		return
+	}
+	node.Line -= offset
+	if node.Kind == yaml.AliasNode {
+		return
+	}
+	for _, child := range node.Content {
+		shiftLines(child, offset)
+	}
+}
+
+var errorLinePattern = regexp.MustCompile(`line (\d+)`)
+
+// shiftErrorLine 修正解析错误中的行号，使其指向原文件
+func shiftErrorLine(err error, offset int) error {
+	msg := err.Error()
+	match := errorLinePattern.FindStringSubmatchIndex(msg)
+	if match == nil {
+		return err
+	}
+	line, convErr := strconv.Atoi(msg[match[2]:match[3]])
+	if convErr != nil {
+		return err
+	}
+	return errors.New(msg[:match[2]] + strconv.Itoa(line-offset) + msg[match[3]:])
+}
+
+// collectAnchors 收集文件中最外层的锚点节点，供引用方使用
+func collectAnchors(root *yaml.Node, source SourceInfo) []anchorDef {
+	var anchors []anchorDef
+	var walk func(node *yaml.Node)
+	walk = func(node *yaml.Node) {
+		if node == nil || node.Kind == yaml.AliasNode {
+			return
+		}
+		if node.Anchor != "" {
+			anchors = append(anchors, anchorDef{node: node, source: source})
+			return
+		}
+		for _, child := range node.Content {
+			walk(child)
+		}
+	}
+	walk(root)
+	return anchors
+}
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfigFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir failed: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s failed: %v", name, err)
+		}
+	}
+}
+
+func TestLoaderIncludesFilesAndSharesAnchors(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFiles(t, dir, map[string]string{
+		"demo.yaml": `include:
+  - shared/*.yaml
+commands:
+  deploy:
+    <<: *deploy_defaults
+    command: ./deploy.sh
+    actions:
+      rollback: *rollback_action
+`,
+		"shared/fragments.yaml": `fragments:
+  deploy_defaults: &deploy_defaults
+    description: 部署
+    env:
+      REGION: eu
+  rollback: &rollback_action
+    description: 回滚
+    command: ./rollback.sh
+`,
+		"shared/tools.yaml": `commands:
+  lint:
+    command: make lint
+`,
+	})
+
+	cfg, err := NewLoader(dir).Load("demo.yaml", "")
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if _, ok := cfg.Commands["lint"]; !ok {
+		t.Fatalf("expected included command lint")
+	}
+	deploy := cfg.Commands["deploy"]
+	if deploy.Description != "部署" || deploy.Env["REGION"] != "eu" {
+		t.Fatalf("merge key not applied: %+v", deploy)
+	}
+	rollback := deploy.Actions["rollback"]
+	if rollback.Command != "./rollback.sh" {
+		t.Fatalf("alias from included file not resolved: %+v", rollback)
+	}
+	fragments := filepath.ToSlash(filepath.Join(dir, "shared", "fragments.yaml"))
+	if got := deploy.FieldOrigins[OriginEnv+"REGION"]; got.File != fragments || got.Line != 5 {
+		t.Fatalf("env origin should point to the fragment file, got %s", got)
+	}
+	if got := deploy.FieldOrigins[OriginCommand]; got.Line != 6 || !strings.HasSuffix(got.File, "demo.yaml") {
+		t.Fatalf("unexpected command origin: %s", got)
+	}
+	lint := cfg.Commands["lint"].Origin
+	if len(lint.Via) != 1 || !strings.Contains(lint.String(), "include") || !strings.Contains(lint.String(), "demo.yaml:1") {
+		t.Fatalf("included origin should record the include chain, got %s", lint)
+	}
+}
+
+func TestLoaderRejectsIncludeCyclesAndEscapes(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFiles(t, dir, map[string]string{
+		"demo.yaml": "include: a.yaml\ncommands:\n  x:\n    command: echo\n",
+		"a.yaml":    "include: demo.yaml\n",
+	})
+	if _, err := NewLoader(dir).Load("demo.yaml", ""); err == nil || !strings.Contains(err.Error(), "循环引用") {
+		t.Fatalf("expected include cycle error, got %v", err)
+	}
+
+	outside := t.TempDir()
+	writeConfigFiles(t, outside, map[string]string{"evil.yaml": "commands:\n  evil:\n    command: echo\n"})
+	inner := filepath.Join(dir, "inner")
+	writeConfigFiles(t, inner, map[string]string{
+		"demo.yaml": "include: " + filepath.Join(outside, "evil.yaml") + "\ncommands:\n  x:\n    command: echo\n",
+	})
+	if _, err := NewLoader(inner).Load("demo.yaml", ""); err == nil || !strings.Contains(err.Error(), "不在允许的目录内") {
+		t.Fatalf("expected root escape error, got %v", err)
+	}
+}
+
+func TestLoaderResolvesExtends(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFiles(t, dir, map[string]string{
+		"demo.yaml": `commands:
+  base:
+    env:
+      REGION: eu
+      TIER: web
+    params:
+      tag: latest
+    actions:
+      status:
+        command: ./status.sh
+      restart:
+        command: ./restart.sh
+  api:
+    extends: base
+    env:
+      TIER: api
+    actions:
+      restart:
+        command: ./api-restart.sh
+  worker:
+    extends: api
+`,
+	})
+	cfg, err := NewLoader(dir).Load("demo.yaml", "")
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	worker := cfg.Commands["worker"]
+	if worker.Env["REGION"] != "eu" || worker.Env["TIER"] != "api" || *worker.Params["tag"].Default != "latest" {
+		t.Fatalf("unexpected inherited env/params: %+v %+v", worker.Env, worker.Params)
+	}
+	if worker.Actions["restart"].Command != "./api-restart.sh" || worker.Actions["status"].Command != "./status.sh" {
+		t.Fatalf("unexpected inherited actions: %+v", worker.Actions)
+	}
+	if via := worker.Actions["status"].Origin.Via; strings.Join(via, ",") != "extends base,extends api" {
+		t.Fatalf("unexpected origin chain: %v", via)
+	}
+
+	writeConfigFiles(t, dir, map[string]string{
+		"demo.yaml": "commands:\n  a:\n    extends: b\n    command: echo\n  b:\n    extends: a\n    command: echo\n",
+	})
+	if _, err := NewLoader(dir).Load("demo.yaml", ""); err == nil || !strings.Contains(err.Error(), "循环") {
+		t.Fatalf("expected extends cycle error, got %v", err)
+	}
+}
@@ -1,20 +1,25 @@
 package config
 
 import (
+	"context"
 	"fmt"
 	"io/fs"
+	"maps"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 
-	"gopkg.in/yaml.v3"
+	"github.com/alpen/alpen-cli/internal/i18n"
+	"github.com/alpen/alpen-cli/internal/tracing"
 )
 
 // Loader 负责从磁盘加载并合并配置
 type Loader struct {
 	baseDir     string
 	diagnostics []Diagnostic
+	// overlay 为代替磁盘内容的文件内容，键为绝对路径
+	overlay map[string][]byte
 }
 
 // NewLoader 构造 Loader
@@ -22,6 +27,15 @@ func NewLoader(baseDir string) *Loader {
 	return &Loader{baseDir: baseDir}
 }
 
+// WithContent 返回读取 path 时以 data 代替磁盘内容的 Loader，path 可以是尚未创建的文件，
+// 用于在写入前按完整的加载流程校验修改后的配置
+func (l *Loader) WithContent(path string, data []byte) *Loader {
+	overlay := make(map[string][]byte, len(l.overlay)+1)
+	maps.Copy(overlay, l.overlay)
+	overlay[absolutePath(l.resolvePath(path))] = data
+	return &Loader{baseDir: l.baseDir, overlay: overlay}
+}
+
 // Diagnostic 用于记录配置合并过程中的提示
 type Diagnostic struct {
 	Level   string
@@ -35,64 +49,104 @@ func (l *Loader) Diagnostics() []Diagnostic {
 	return result
 }
 
-// SourceInfo 描述命令或动作的来源信息
+// SourceInfo 描述命令或动作的来源信息，Line 为定义所在行号（未知时为 0），
+// Via 记录定义经由的 include 位置或 extends 关系，按从外到内的顺序排列
 type SourceInfo struct {
 	Module string
 	File   string
+	Line   int
+	Via    []string
+}
+
+// IsZero 判断来源信息是否为空
+func (s SourceInfo) IsZero() bool {
+	return s.Module == "" && s.File == "" && s.Line == 0 && len(s.Via) == 0
 }
 
 func (s SourceInfo) String() string {
+	file := strings.TrimSpace(s.File)
+	if file != "" && s.Line > 0 {
+		file = fmt.Sprintf("%s:%d", file, s.Line)
+	}
+	var result string
 	switch {
-	case strings.TrimSpace(s.Module) != "" && strings.TrimSpace(s.File) != "":
-		return fmt.Sprintf("%s (%s)", s.Module, s.File)
-	case strings.TrimSpace(s.File) != "":
-		return s.File
+	case strings.TrimSpace(s.Module) != "" && file != "":
+		result = fmt.Sprintf("%s (%s)", s.Module, file)
+	case file != "":
+		result = file
 	case strings.TrimSpace(s.Module) != "":
-		return s.Module
-	default:
-		return ""
+		result = s.Module
+	}
+	if len(s.Via) > 0 && result != "" {
+		result = i18n.T("config.source_via", result, strings.Join(s.Via, " → "))
 	}
+	return result
 }
 
 // Load 读取指定路径的配置文件，env 用于加载额外的环境差异文件
 func (l *Loader) Load(path string, env string) (*Config, error) {
+	_, span := tracing.Start(context.Background(), "alpen.config.load")
+	span.SetAttr("alpen.config.file", path)
+	span.SetAttr("alpen.environment", env)
+	cfg, err := l.load(path, env)
+	if cfg != nil {
+		span.SetAttr("alpen.config.commands", len(cfg.Commands))
+	}
+	span.RecordError(err)
+	span.End()
+	return cfg, err
+}
+
+func (l *Loader) load(path string, env string) (*Config, error) {
 	l.diagnostics = nil
 	fullPath := l.resolvePath(path)
-	info, err := os.Stat(fullPath)
-	if err != nil {
-		return nil, fmt.Errorf("加载基础配置失败: %w", err)
+	isDir := false
+	if _, ok := l.overlay[absolutePath(fullPath)]; !ok {
+		info, err := os.Stat(fullPath)
+		if err != nil {
+			return nil, i18n.Errorf("config.load_base_failed", err)
+		}
+		isDir = info.IsDir()
 	}
-	if info.IsDir() {
+	if isDir {
 		dirCfg, err := l.loadDirectoryConfig(fullPath)
 		if err != nil {
 			return nil, err
 		}
 		dirCfg.Diagnostics = l.Diagnostics()
+		if err := resolveExtends(dirCfg); err != nil {
+			return nil, err
+		}
+		pruneDisabled(dirCfg)
 		if err := dirCfg.Validate(); err != nil {
 			return nil, err
 		}
 		return dirCfg, nil
 	}
 
-	baseConfig, err := loadSingleConfig(fullPath, l.describeSource(fullPath, ""))
+	baseConfig, err := l.loadSingleConfig(fullPath, l.describeSource(fullPath, ""), l.includeRoots(fullPath))
 	if err != nil {
-		return nil, fmt.Errorf("加载基础配置失败: %w", err)
+		return nil, i18n.Errorf("config.load_base_failed", err)
 	}
 	if env != "" {
 		envPath := l.appendEnvSuffix(fullPath, env)
-		if _, err := os.Stat(envPath); err == nil {
-			envConfig, err := loadSingleConfig(envPath, l.describeSource(envPath, fmt.Sprintf("@env:%s", env)))
+		if l.exists(envPath) {
+			envConfig, err := l.loadSingleConfig(envPath, l.describeSource(envPath, fmt.Sprintf("@env:%s", env)), l.includeRoots(fullPath))
 			if err != nil {
-				return nil, fmt.Errorf("加载环境配置失败: %w", err)
+				return nil, i18n.Errorf("config.load_env_failed", err)
 			}
 			if err := mergeConfig(baseConfig, envConfig, mergeOptions{
 				allowOverride: true, // 环境配置允许覆盖基础配置
 			}); err != nil {
-				return nil, fmt.Errorf("合并环境配置失败: %w", err)
+				return nil, i18n.Errorf("config.merge_env_failed", err)
 			}
 		}
 	}
 
+	if err := resolveExtends(baseConfig); err != nil {
+		return nil, err
+	}
+	pruneDisabled(baseConfig)
 	if err := baseConfig.Validate(); err != nil {
 		return nil, err
 	}
@@ -115,47 +169,133 @@ func (l *Loader) appendEnvSuffix(path string, env string) string {
 	return fmt.Sprintf("%s.%s%s", base, env, ext)
 }
 
-func loadSingleConfig(path string, source SourceInfo) (*Config, error) {
-	data, err := os.ReadFile(path)
+// loadSingleConfig 加载单个配置文件并展开其中的 include，roots 为允许引用的目录
+func (l *Loader) loadSingleConfig(path string, source SourceInfo, roots []string) (*Config, error) {
+	il := &includeLoader{roots: roots, read: l.readFile}
+	loaded, err := il.load(path, source)
 	if err != nil {
 		return nil, err
 	}
-	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
-		return nil, err
+	return loaded.cfg, nil
+}
+
+// readFile 读取配置文件，WithContent 提供了内容时不读取磁盘
+func (l *Loader) readFile(path string) ([]byte, error) {
+	if data, ok := l.overlay[absolutePath(path)]; ok {
+		return data, nil
+	}
+	return os.ReadFile(path)
+}
+
+func (l *Loader) exists(path string) bool {
+	if _, ok := l.overlay[absolutePath(path)]; ok {
+		return true
+	}
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func absolutePath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
+
+// includeRoots 返回 include 允许引用的目录：~/.alpen 与配置所在目录
+func (l *Loader) includeRoots(fullPath string) []string {
+	roots := []string{filepath.Dir(fullPath)}
+	if home, err := ResolveHomeDir(); err == nil {
+		roots = append(roots, home)
 	}
-	normalizeConfig(&cfg)
-	registerOrigins(&cfg, source)
-	return &cfg, nil
+	return roots
 }
 
-func normalizeConfig(cfg *Config) {
+func normalizeConfig(cfg *Config) error {
 	if cfg.Commands == nil {
 		cfg.Commands = map[string]CommandSpec{}
-		return
+		return nil
 	}
 	for name, spec := range cfg.Commands {
 		if spec.Actions == nil {
 			spec.Actions = map[string]ActionSpec{}
 		}
+		// 文件级解释器只作用于本文件中的命令，加载时下放到未配置解释器的命令
+		if spec.Shell == "" && len(spec.Interpreter) == 0 {
+			spec.Shell, spec.Interpreter = cfg.Shell, cfg.Interpreter
+		}
+		if err := normalizeScript(i18n.T("config.label.command", name), &spec.Command, &spec.Script, &spec.Inline); err != nil {
+			return err
+		}
+		for actionName, action := range spec.Actions {
+			if err := normalizeScript(i18n.T("config.label.action", name, actionName), &action.Command, &action.Script, &action.Inline); err != nil {
+				return err
+			}
+			spec.Actions[actionName] = action
+		}
 		cfg.Commands[name] = spec
 	}
+	return nil
 }
 
 type mergeOptions struct {
 	label         string
 	collect       func(Diagnostic)
 	allowOverride bool // 是否允许覆盖同名命令(仅用于环境配置)
+	// priority 为当前文件的优先级，owners 记录命令（name）与子命令（name.action）的 command 所在文件的优先级
+	priority int
+	owners   map[string]int
+}
+
+func (o mergeOptions) report(d Diagnostic) {
+	if o.collect != nil {
+		o.collect(d)
+	}
+}
+
+// checkConflict 判断 command 冲突是否允许：显式标记 override 或当前文件优先级更高时改为警告，否则返回错误
+func (o mergeOptions) checkConflict(kind string, key string, marked bool, previous SourceInfo, next SourceInfo) error {
+	if o.allowOverride {
+		return nil
+	}
+	if !marked && o.priority <= o.owners[key] {
+		return i18n.Errorf("config.override_conflict",
+			kind, key, o.label, kind, previous.String(), next.String())
+	}
+	o.report(Diagnostic{
+		Level:   "warning",
+		Message: i18n.T("config.overridden", kind, key, next.String(), previous.String()),
+	})
+	return nil
+}
+
+func (o mergeOptions) recordOwner(key string, command string) {
+	if o.owners != nil && command != "" {
+		o.owners[key] = o.priority
+	}
 }
 
 func mergeConfig(base *Config, override *Config, opts mergeOptions) error {
 	if base.Commands == nil {
 		base.Commands = map[string]CommandSpec{}
 	}
+	base.Vars = mergeByKey(base.Vars, override.Vars)
+	base.FieldOrigins = mergeByKey(base.FieldOrigins, override.FieldOrigins)
 	for name, overrideSpec := range override.Commands {
 		existingSpec, exists := base.Commands[name]
-		if !exists {
+		// 新命令、禁用标记以及重新定义已禁用的命令都直接替换
+		if !exists || overrideSpec.Disabled || existingSpec.Disabled {
+			if exists && overrideSpec.Disabled && !existingSpec.Disabled {
+				opts.report(Diagnostic{
+					Level:   "info",
+					Message: i18n.T("config.command_disabled", name, overrideSpec.Origin.String(), existingSpec.Origin.String()),
+				})
+			}
 			base.Commands[name] = overrideSpec
+			opts.recordOwner(name, overrideSpec.Command)
+			for actionName, action := range overrideSpec.Actions {
+				opts.recordOwner(name+"."+actionName, action.Command)
+			}
 			continue
 		}
 		baseSpec := existingSpec
@@ -165,21 +305,21 @@ func mergeConfig(base *Config, override *Config, opts mergeOptions) error {
 
 		// 检测命令级别的冲突
 		if overrideSpec.Command != "" && baseSpec.Command != "" && overrideSpec.Command != baseSpec.Command {
-			// 环境配置允许覆盖
-			if !opts.allowOverride {
-				return fmt.Errorf("命令 %s 冲突: %s 尝试覆盖命令定义，原来源: %s，新来源: %s",
-					name, opts.label, baseSpec.Origin.String(), overrideSpec.Origin.String())
+			if err := opts.checkConflict(i18n.T("config.kind.command"), name, overrideSpec.Override, baseSpec.Origin, overrideSpec.Origin); err != nil {
+				return err
 			}
 		}
 
 		// 检测子命令(Action)级别的冲突
 		for actionName, overrideAction := range overrideSpec.Actions {
 			baseAction := baseSpec.Actions[actionName]
+			if overrideAction.Disabled || baseAction.Disabled {
+				continue
+			}
 			if baseAction.Command != "" && overrideAction.Command != "" && baseAction.Command != overrideAction.Command {
-				// 环境配置允许覆盖
-				if !opts.allowOverride {
-					return fmt.Errorf("子命令 %s.%s 冲突: %s 尝试覆盖子命令定义，原来源: %s，新来源: %s",
-						name, actionName, opts.label, baseAction.Origin.String(), overrideAction.Origin.String())
+				marked := overrideAction.Override || overrideSpec.Override
+				if err := opts.checkConflict(i18n.T("config.kind.action"), name+"."+actionName, marked, baseAction.Origin, overrideAction.Origin); err != nil {
+					return err
 				}
 			}
 		}
@@ -188,15 +328,61 @@ func mergeConfig(base *Config, override *Config, opts mergeOptions) error {
 		if overrideSpec.Alias != "" {
 			baseSpec.Alias = overrideSpec.Alias
 		}
+		if overrideSpec.Extends != "" {
+			baseSpec.Extends = overrideSpec.Extends
+		}
 		if overrideSpec.Description != "" {
 			baseSpec.Description = overrideSpec.Description
 		}
 		if overrideSpec.Command != "" {
 			baseSpec.Command = overrideSpec.Command
+			baseSpec.Inline = overrideSpec.Inline
+		}
+		if overrideSpec.Shell != "" || len(overrideSpec.Interpreter) > 0 {
+			baseSpec.Shell, baseSpec.Interpreter = overrideSpec.Shell, overrideSpec.Interpreter
+		}
+		if overrideSpec.Notify != nil {
+			baseSpec.Notify = overrideSpec.Notify
+		}
+		if overrideSpec.Confirm != nil {
+			baseSpec.Confirm = overrideSpec.Confirm
 		}
+		if overrideSpec.Protected != nil {
+			baseSpec.Protected = overrideSpec.Protected
+		}
+		if overrideSpec.Platforms != nil {
+			baseSpec.Platforms = overrideSpec.Platforms
+		}
+		if overrideSpec.Requires != nil {
+			baseSpec.Requires = overrideSpec.Requires
+		}
+		if overrideSpec.MinAlpenVersion != "" {
+			baseSpec.MinAlpenVersion = overrideSpec.MinAlpenVersion
+		}
+		baseSpec.Env = mergeByKey(baseSpec.Env, overrideSpec.Env)
+		if overrideSpec.WorkDir != "" {
+			baseSpec.WorkDir = overrideSpec.WorkDir
+		}
+		baseSpec.Vars = mergeByKey(baseSpec.Vars, overrideSpec.Vars)
+		baseSpec.Params = mergeByKey(baseSpec.Params, overrideSpec.Params)
+		baseSpec.FieldOrigins = mergeByKey(baseSpec.FieldOrigins, overrideSpec.FieldOrigins)
+		baseSpec.Layers = append(append([]Layer(nil), baseSpec.Layers...), overrideSpec.Layers...)
+
+		opts.recordOwner(name, overrideSpec.Command)
 
 		for actionName, overrideAction := range overrideSpec.Actions {
-			baseAction := baseSpec.Actions[actionName]
+			opts.recordOwner(name+"."+actionName, overrideAction.Command)
+			baseAction, actionExists := baseSpec.Actions[actionName]
+			if overrideAction.Disabled || baseAction.Disabled || !actionExists {
+				if actionExists && overrideAction.Disabled && !baseAction.Disabled {
+					opts.report(Diagnostic{
+						Level:   "info",
+						Message: i18n.T("config.action_disabled", name, actionName, overrideAction.Origin.String(), baseAction.Origin.String()),
+					})
+				}
+				baseSpec.Actions[actionName] = overrideAction
+				continue
+			}
 			if overrideAction.Alias != "" {
 				baseAction.Alias = overrideAction.Alias
 			}
@@ -205,12 +391,42 @@ func mergeConfig(base *Config, override *Config, opts mergeOptions) error {
 			}
 			if overrideAction.Command != "" {
 				baseAction.Command = overrideAction.Command
+				baseAction.Inline = overrideAction.Inline
+			}
+			if overrideAction.Shell != "" || len(overrideAction.Interpreter) > 0 {
+				baseAction.Shell, baseAction.Interpreter = overrideAction.Shell, overrideAction.Interpreter
+			}
+			if overrideAction.Notify != nil {
+				baseAction.Notify = overrideAction.Notify
+			}
+			if overrideAction.Confirm != nil {
+				baseAction.Confirm = overrideAction.Confirm
+			}
+			if overrideAction.Protected != nil {
+				baseAction.Protected = overrideAction.Protected
+			}
+			if overrideAction.Platforms != nil {
+				baseAction.Platforms = overrideAction.Platforms
+			}
+			if overrideAction.Requires != nil {
+				baseAction.Requires = overrideAction.Requires
 			}
+			if overrideAction.MinAlpenVersion != "" {
+				baseAction.MinAlpenVersion = overrideAction.MinAlpenVersion
+			}
+			baseAction.Env = mergeByKey(baseAction.Env, overrideAction.Env)
+			if overrideAction.WorkDir != "" {
+				baseAction.WorkDir = overrideAction.WorkDir
+			}
+			baseAction.Vars = mergeByKey(baseAction.Vars, overrideAction.Vars)
+			baseAction.Params = mergeByKey(baseAction.Params, overrideAction.Params)
+			baseAction.FieldOrigins = mergeByKey(baseAction.FieldOrigins, overrideAction.FieldOrigins)
+			baseAction.Layers = append(append([]Layer(nil), baseAction.Layers...), overrideAction.Layers...)
 			baseAction.Origin = overrideAction.Origin
 			baseSpec.Actions[actionName] = baseAction
 		}
 
-		if overrideSpec.Origin != (SourceInfo{}) {
+		if !overrideSpec.Origin.IsZero() {
 			baseSpec.Origin = overrideSpec.Origin
 		}
 		base.Commands[name] = baseSpec
@@ -218,17 +434,6 @@ func mergeConfig(base *Config, override *Config, opts mergeOptions) error {
 	return nil
 }
 
-func registerOrigins(cfg *Config, source SourceInfo) {
-	for name, spec := range cfg.Commands {
-		spec.Origin = source
-		for actionName, action := range spec.Actions {
-			action.Origin = source
-			spec.Actions[actionName] = action
-		}
-		cfg.Commands[name] = spec
-	}
-}
-
 func (l *Loader) describeSource(path string, module string) SourceInfo {
 	cleaned := filepath.Clean(path)
 	return SourceInfo{
@@ -266,29 +471,110 @@ func collectModuleYAML(root string) ([]string, error) {
 	return files, nil
 }
 
+// withOverlayFiles 将 WithContent 提供的、位于模块目录中且尚未创建的 YAML 文件加入文件列表
+func (l *Loader) withOverlayFiles(dir string, files []string) []string {
+	root := absolutePath(dir)
+	known := map[string]bool{}
+	for _, file := range files {
+		known[absolutePath(file)] = true
+	}
+	added := false
+	for path := range l.overlay {
+		ext := strings.ToLower(filepath.Ext(path))
+		rel, err := filepath.Rel(root, path)
+		if known[path] || (ext != ".yaml" && ext != ".yml") || err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		files = append(files, filepath.Join(dir, rel))
+		added = true
+	}
+	if added {
+		sort.Strings(files)
+	}
+	return files
+}
+
 func (l *Loader) loadDirectoryConfig(dir string) (*Config, error) {
 	files, err := collectModuleYAML(dir)
 	if err != nil {
-		return nil, fmt.Errorf("遍历目录 %s 失败: %w", dir, err)
+		return nil, i18n.Errorf("config.walk_failed", dir, err)
 	}
+	files = l.withOverlayFiles(dir, files)
 	if len(files) == 0 {
-		return nil, fmt.Errorf("目录 %s 中未找到 YAML 配置", dir)
+		return nil, i18n.Errorf("config.dir_empty", dir)
 	}
-	result := &Config{Commands: map[string]CommandSpec{}}
 	moduleName := filepath.Base(dir)
+	type moduleFile struct {
+		name string
+		cfg  *Config
+	}
+	loaded := make([]moduleFile, 0, len(files))
 	for _, file := range files {
-		cfg, err := loadSingleConfig(file, l.describeSource(file, moduleName))
+		cfg, err := l.loadSingleConfig(file, l.describeSource(file, moduleName), l.includeRoots(dir))
 		if err != nil {
-			return nil, fmt.Errorf("加载目录 %s 的配置 %s 失败: %w", moduleName, filepath.Base(file), err)
+			return nil, i18n.Errorf("config.load_module_failed", moduleName, filepath.Base(file), err)
 		}
-		if err := mergeConfig(result, cfg, mergeOptions{
-			label: fmt.Sprintf("%s/%s", moduleName, filepath.Base(file)),
+		// 文件级 vars 只作用于本文件中的命令，合并前下放，避免同名变量被其他文件覆盖
+		scopeFileVars(cfg)
+		loaded = append(loaded, moduleFile{name: filepath.Base(file), cfg: cfg})
+	}
+	// 按 priority 从小到大合并，相同优先级保持文件名顺序
+	sort.SliceStable(loaded, func(i, j int) bool {
+		return loaded[i].cfg.Priority < loaded[j].cfg.Priority
+	})
+	result := &Config{Commands: map[string]CommandSpec{}}
+	owners := map[string]int{}
+	for _, file := range loaded {
+		if err := mergeConfig(result, file.cfg, mergeOptions{
+			label: fmt.Sprintf("%s/%s", moduleName, file.name),
 			collect: func(d Diagnostic) {
 				l.diagnostics = append(l.diagnostics, d)
 			},
+			priority: file.cfg.Priority,
+			owners:   owners,
 		}); err != nil {
-			return nil, fmt.Errorf("合并配置失败: %w", err)
+			return nil, i18n.Errorf("config.merge_failed", err)
 		}
 	}
 	return result, nil
 }
+
+// scopeFileVars 将文件级 vars 及其定义位置下放到文件中的各命令，并清空文件级 vars
+func scopeFileVars(cfg *Config) {
+	for name, spec := range cfg.Commands {
+		spec.Vars = mergeByKey(cfg.Vars, spec.Vars)
+		spec.FieldOrigins = mergeByKey(cfg.FieldOrigins, spec.FieldOrigins)
+		cfg.Commands[name] = spec
+	}
+	cfg.Vars, cfg.FieldOrigins = nil, nil
+}
+
+// pruneDisabled 移除合并与继承完成后仍带有 disabled 标记的命令与子命令
+func pruneDisabled(cfg *Config) {
+	for name, spec := range cfg.Commands {
+		if spec.Disabled {
+			delete(cfg.Commands, name)
+			continue
+		}
+		for actionName, action := range spec.Actions {
+			if action.Disabled {
+				delete(spec.Actions, actionName)
+			}
+		}
+	}
+}
+
+// mergeByKey 按键合并 env、vars、params 等映射，override 中的同名项覆盖 base
+func mergeByKey[V any](base map[string]V, override map[string]V) map[string]V {
+	if len(override) == 0 {
+		return base
+	}
+	merged := make(map[string]V, len(base)+len(override))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range override {
+		merged[k] = v
+	}
+	return merged
+}
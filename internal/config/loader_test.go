@@ -97,6 +97,41 @@ commands:
 	}
 }
 
+func TestLoaderScopesModuleFileVars(t *testing.T) {
+	dir := t.TempDir()
+	moduleDir := filepath.Join(dir, "m.conf")
+	if err := os.MkdirAll(moduleDir, 0o755); err != nil {
+		t.Fatalf("create module dir failed: %v", err)
+	}
+	files := map[string]string{
+		"a.yaml": "vars:\n  host: alpha\ncommands:\n  pa:\n    command: echo {{ .vars.host }}\n",
+		"b.yaml": "vars:\n  host: beta\ncommands:\n  pb:\n    command: echo {{ .vars.host }}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(moduleDir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s failed: %v", name, err)
+		}
+	}
+
+	cfg, err := NewLoader(dir).Load("m.conf", "")
+	if err != nil {
+		t.Fatalf("load module config failed: %v", err)
+	}
+	for command, want := range map[string]string{"pa": "alpha", "pb": "beta"} {
+		target, ok := cfg.ResolveTarget([]string{command})
+		if !ok {
+			t.Fatalf("target %s not found", command)
+		}
+		if got := target.Vars["host"].Value; got != want {
+			t.Fatalf("vars.host of %s = %q, want %q", command, got, want)
+		}
+		want = "m.conf (" + filepath.ToSlash(filepath.Join(moduleDir, command[1:]+".yaml")) + ":2)"
+		if got := target.FieldOrigins[OriginVars+"host"].String(); got != want {
+			t.Fatalf("origin of vars.host for %s = %q, want %q", command, got, want)
+		}
+	}
+}
+
 func TestLoaderModuleOverrideProducesError(t *testing.T) {
 	dir := t.TempDir()
 	basePath := filepath.Join(dir, "demo.yaml")
@@ -142,3 +177,126 @@ commands:
 		t.Fatalf("expected error message to mention conflict, got: %v", err)
 	}
 }
+
+func TestLoaderRecordsFieldOrigins(t *testing.T) {
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "demo.yaml")
+	envPath := filepath.Join(dir, "demo.dev.yaml")
+	baseContent := []byte(`vars:
+  host: example.com
+commands:
+  deploy:
+    workdir: /srv
+    env:
+      REGION: eu
+    actions:
+      run:
+        command: ./deploy.sh
+`)
+	envContent := []byte(`commands:
+  deploy:
+    env:
+      REGION: us
+`)
+	if err := os.WriteFile(basePath, baseContent, 0o644); err != nil {
+		t.Fatalf("write base config failed: %v", err)
+	}
+	if err := os.WriteFile(envPath, envContent, 0o644); err != nil {
+		t.Fatalf("write env config failed: %v", err)
+	}
+
+	cfg, err := NewLoader(dir).Load("demo.yaml", "dev")
+	if err != nil {
+		t.Fatalf("load config failed: %v", err)
+	}
+	target, ok := cfg.ResolveTarget([]string{"deploy", "run"})
+	if !ok {
+		t.Fatalf("target not found")
+	}
+	expect := map[string]string{
+		OriginCommand:        filepath.ToSlash(basePath) + ":10",
+		OriginWorkDir:        filepath.ToSlash(basePath) + ":5",
+		OriginVars + "host":  filepath.ToSlash(basePath) + ":2",
+		OriginEnv + "REGION": "@env:dev (" + filepath.ToSlash(envPath) + ":4)",
+	}
+	for key, want := range expect {
+		if got := target.FieldOrigins[key].String(); got != want {
+			t.Fatalf("origin of %s = %q, want %q", key, got, want)
+		}
+	}
+	if target.Origin.Line != 9 {
+		t.Fatalf("unexpected action origin: %+v", target.Origin)
+	}
+	layers := cfg.Commands["deploy"].Layers
+	if len(layers) != 2 || strings.Join(layers[0].Fields, ",") != "env.REGION,workdir" || layers[1].Source.Module != "@env:dev" {
+		t.Fatalf("unexpected layers: %+v", layers)
+	}
+}
+
+func TestLoaderModulePriorityOverrideAndDisabled(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFiles(t, dir, map[string]string{
+		"deploy.conf/personal.yaml": `priority: 10
+commands:
+  deploy:
+    command: echo personal
+    actions:
+      rollback:
+        disabled: true
+`,
+		"deploy.conf/shared.yaml": `commands:
+  deploy:
+    command: echo shared
+    actions:
+      status:
+        command: echo status
+      rollback:
+        command: echo rollback
+  lint:
+    command: echo lint
+`,
+		"deploy.conf/zz_local.yaml": `commands:
+  lint:
+    override: true
+    command: echo local lint
+  deploy:
+    actions:
+      status:
+        command: echo local status
+        override: true
+`,
+	})
+
+	loader := NewLoader(dir)
+	cfg, err := loader.Load("deploy.conf", "")
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	deploy := cfg.Commands["deploy"]
+	if deploy.Command != "echo personal" {
+		t.Fatalf("expected higher priority file to win, got %q", deploy.Command)
+	}
+	if _, ok := deploy.Actions["rollback"]; ok {
+		t.Fatalf("expected rollback to be removed by disabled tombstone")
+	}
+	if deploy.Actions["status"].Command != "echo local status" || cfg.Commands["lint"].Command != "echo local lint" {
+		t.Fatalf("expected explicit overrides to apply: %+v %+v", deploy.Actions["status"], cfg.Commands["lint"])
+	}
+	warnings := 0
+	for _, diag := range cfg.Diagnostics {
+		if diag.Level == "warning" {
+			warnings++
+		}
+	}
+	if warnings != 3 {
+		t.Fatalf("expected 3 override warnings, got %+v", cfg.Diagnostics)
+	}
+
+	// 优先级相同且未标记 override 时仍视为冲突
+	writeConfigFiles(t, dir, map[string]string{
+		"deploy.conf/zz_local.yaml": "commands:\n  lint:\n    command: echo conflict\n",
+	})
+	if _, err := loader.Load("deploy.conf", ""); err == nil || !strings.Contains(err.Error(), "override: true") {
+		t.Fatalf("expected conflict error with hint, got %v", err)
+	}
+}
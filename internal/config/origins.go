@@ -0,0 +1,171 @@
+package config
+
+import (
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 字段来源键，env、vars、params 按 <前缀>.<名称> 记录单个条目
+const (
+	OriginCommand = "command"
+	OriginWorkDir = "workdir"
+	OriginEnv     = "env."
+	OriginVars    = "vars."
+	OriginParams  = "params."
+)
+
+// Layer 记录某个配置文件对命令或子命令的一次定义，合并后按加载顺序排列
+type Layer struct {
+	Source SourceInfo
+	// Fields 为该文件设置的字段，env、vars、params 按 <前缀><名称> 展开
+	Fields []string
+}
+
+// originRecorder 按节点计算来源位置，external 记录经锚点引用自其他文件的节点
+type originRecorder struct {
+	source   SourceInfo
+	external map[*yaml.Node]SourceInfo
+}
+
+// registerOrigins 记录命令、子命令及其字段在文件中的来源位置
+func registerOrigins(cfg *Config, source SourceInfo, root *yaml.Node, external map[*yaml.Node]SourceInfo) {
+	r := originRecorder{source: source, external: external}
+	doc := root
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		doc = doc.Content[0]
+	}
+	cfg.FieldOrigins = map[string]SourceInfo{}
+	_, varsNode := mappingEntry(doc, "vars")
+	r.collectEntryOrigins(cfg.FieldOrigins, OriginVars, varsNode)
+
+	_, commandsNode := mappingEntry(doc, "commands")
+	for name, spec := range cfg.Commands {
+		keyNode, specNode := mappingEntry(commandsNode, name)
+		spec.Origin = r.at(keyNode)
+		spec.FieldOrigins = r.fieldOrigins(specNode)
+		spec.Layers = []Layer{{Source: spec.Origin, Fields: layerFields(specNode)}}
+		_, actionsNode := mappingEntry(specNode, "actions")
+		for actionName, action := range spec.Actions {
+			actionKey, actionNode := mappingEntry(actionsNode, actionName)
+			action.Origin = r.at(actionKey)
+			action.FieldOrigins = r.fieldOrigins(actionNode)
+			action.Layers = []Layer{{Source: action.Origin, Fields: layerFields(actionNode)}}
+			spec.Actions[actionName] = action
+		}
+		cfg.Commands[name] = spec
+	}
+}
+
+// fieldOrigins 记录命令或子命令映射中各字段的行号
+func (r originRecorder) fieldOrigins(node *yaml.Node) map[string]SourceInfo {
+	origins := map[string]SourceInfo{}
+	for _, key := range []string{OriginCommand, OriginWorkDir} {
+		if _, value := mappingEntry(node, key); value != nil {
+			origins[key] = r.at(value)
+		}
+	}
+	// 内联脚本与 command 共用来源键
+	if _, value := mappingEntry(node, "script"); value != nil {
+		origins[OriginCommand] = r.at(value)
+	}
+	for key, prefix := range map[string]string{"env": OriginEnv, "vars": OriginVars, "params": OriginParams} {
+		_, value := mappingEntry(node, key)
+		r.collectEntryOrigins(origins, prefix, value)
+	}
+	return origins
+}
+
+func (r originRecorder) collectEntryOrigins(origins map[string]SourceInfo, prefix string, node *yaml.Node) {
+	for _, pair := range mappingPairs(node) {
+		origins[prefix+pair[0].Value] = r.at(pair[0])
+	}
+}
+
+// at 返回节点的来源位置，来自其他文件锚点的节点返回其原始文件与行号
+func (r originRecorder) at(node *yaml.Node) SourceInfo {
+	if node == nil {
+		return r.source
+	}
+	if source, ok := r.external[node]; ok {
+		return source
+	}
+	source := r.source
+	source.Line = node.Line
+	return source
+}
+
+// layerFields 返回命令或子命令映射中设置的字段，actions 由子命令各自记录
+func layerFields(node *yaml.Node) []string {
+	prefixes := map[string]string{"env": OriginEnv, "vars": OriginVars, "params": OriginParams}
+	var fields []string
+	for _, pair := range mappingPairs(node) {
+		key := pair[0].Value
+		if key == "actions" {
+			continue
+		}
+		prefix, ok := prefixes[key]
+		if !ok {
+			fields = append(fields, key)
+			continue
+		}
+		for _, entry := range mappingPairs(pair[1]) {
+			fields = append(fields, prefix+entry[0].Value)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// mappingEntry 返回映射节点中指定键的键节点与值节点
+func mappingEntry(node *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	for _, pair := range mappingPairs(node) {
+		if pair[0].Value == key {
+			return pair[0], pair[1]
+		}
+	}
+	return nil, nil
+}
+
+// mappingPairs 返回映射节点的键值对，跟随别名并展开 << 合并键，显式键覆盖合并进来的同名键
+func mappingPairs(node *yaml.Node) [][2]*yaml.Node {
+	node = resolveAlias(node)
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	var merged, explicit [][2]*yaml.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if key.ShortTag() != "!!merge" {
+			explicit = append(explicit, [2]*yaml.Node{key, value})
+			continue
+		}
+		value = resolveAlias(value)
+		sources := []*yaml.Node{value}
+		if value != nil && value.Kind == yaml.SequenceNode {
+			sources = value.Content
+		}
+		for _, source := range sources {
+			merged = append(merged, mappingPairs(source)...)
+		}
+	}
+	seen := map[string]bool{}
+	for _, pair := range explicit {
+		seen[pair[0].Value] = true
+	}
+	result := make([][2]*yaml.Node, 0, len(merged)+len(explicit))
+	for _, pair := range merged {
+		if !seen[pair[0].Value] {
+			seen[pair[0].Value] = true
+			result = append(result, pair)
+		}
+	}
+	return append(result, explicit...)
+}
+
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node != nil && node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	return node
+}
@@ -1,10 +1,11 @@
 package config
 
 import (
-	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/alpen/alpen-cli/internal/i18n"
 )
 
 // ExpandPath 展开路径中的 ~ 与环境变量，并返回规范化结果
@@ -55,7 +56,7 @@ func normalizeWithinHome(raw string, fallback string, home string) (string, erro
 		candidate = fallback
 	}
 	if strings.TrimSpace(candidate) == "" {
-		return "", fmt.Errorf("路径不能为空")
+		return "", i18n.Errorf("config.path_empty")
 	}
 
 	expanded := ExpandPath(candidate)
@@ -64,18 +65,18 @@ func normalizeWithinHome(raw string, fallback string, home string) (string, erro
 	}
 	absCandidate, err := filepath.Abs(expanded)
 	if err != nil {
-		return "", fmt.Errorf("解析路径失败: %w", err)
+		return "", i18n.Errorf("config.path_resolve_failed", err)
 	}
 	absHome, err := filepath.Abs(home)
 	if err != nil {
-		return "", fmt.Errorf("解析用户目录失败: %w", err)
+		return "", i18n.Errorf("config.home_resolve_failed", err)
 	}
 	rel, err := filepath.Rel(absHome, absCandidate)
 	if err != nil {
-		return "", fmt.Errorf("计算路径关系失败: %w", err)
+		return "", i18n.Errorf("config.path_rel_failed", err)
 	}
 	if rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
-		return "", fmt.Errorf("路径 %s 不在用户目录 %s 内", absCandidate, absHome)
+		return "", i18n.Errorf("config.path_outside_home", absCandidate, absHome)
 	}
 	return absCandidate, nil
 }
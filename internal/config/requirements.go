@@ -0,0 +1,120 @@
+package config
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/alpen/alpen-cli/internal/i18n"
+)
+
+// knownPlatforms 为 platforms 支持的取值，与 runtime.GOOS 一致
+var knownPlatforms = []string{"linux", "darwin", "windows", "freebsd", "openbsd", "netbsd"}
+
+// RequiresSpec 声明命令执行前需要满足的前置条件
+type RequiresSpec struct {
+	// Bins 为需要在 PATH 中找到的可执行文件
+	Bins []string `yaml:"bins"`
+	// Env 为需要存在的环境变量，命令 env 中已配置的变量视为满足
+	Env []string `yaml:"env"`
+	// Files 为需要存在的文件或目录，相对路径以命令的 workdir 为基准
+	Files []string `yaml:"files"`
+}
+
+// IsEmpty 判断是否未声明任何前置条件
+func (r RequiresSpec) IsEmpty() bool {
+	return len(r.Bins) == 0 && len(r.Env) == 0 && len(r.Files) == 0
+}
+
+// mergeRequires 合并顶层命令与子命令的前置条件，子命令需要同时满足两者
+func mergeRequires(base *RequiresSpec, override *RequiresSpec) RequiresSpec {
+	var result RequiresSpec
+	for _, spec := range []*RequiresSpec{base, override} {
+		if spec == nil {
+			continue
+		}
+		result.Bins = appendUnique(result.Bins, spec.Bins...)
+		result.Env = appendUnique(result.Env, spec.Env...)
+		result.Files = appendUnique(result.Files, spec.Files...)
+	}
+	return result
+}
+
+func appendUnique(values []string, items ...string) []string {
+	for _, item := range items {
+		if !slices.Contains(values, item) {
+			values = append(values, item)
+		}
+	}
+	return values
+}
+
+// CompareVersions 比较 major.minor.patch 形式的版本号，允许 v 前缀并忽略预发布后缀
+func CompareVersions(a string, b string) (int, error) {
+	left, err := parseVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	right, err := parseVersion(b)
+	if err != nil {
+		return 0, err
+	}
+	for i := range left {
+		if left[i] != right[i] {
+			if left[i] < right[i] {
+				return -1, nil
+			}
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func parseVersion(value string) ([3]int, error) {
+	var parts [3]int
+	trimmed := strings.TrimPrefix(strings.TrimSpace(value), "v")
+	if index := strings.IndexAny(trimmed, "-+"); index >= 0 {
+		trimmed = trimmed[:index]
+	}
+	fields := strings.Split(trimmed, ".")
+	if trimmed == "" || len(fields) > 3 {
+		return parts, i18n.Errorf("config.version_invalid", value)
+	}
+	for i, field := range fields {
+		number, err := strconv.Atoi(field)
+		if err != nil || number < 0 {
+			return parts, i18n.Errorf("config.version_invalid", value)
+		}
+		parts[i] = number
+	}
+	return parts, nil
+}
+
+func validateRequirements(label string, platforms []string, requires *RequiresSpec, minVersion string) error {
+	for _, platform := range platforms {
+		if !slices.Contains(knownPlatforms, platform) {
+			return i18n.Errorf("config.platform_unsupported", label, platform, strings.Join(knownPlatforms, ", "))
+		}
+	}
+	if strings.TrimSpace(minVersion) != "" {
+		if _, err := parseVersion(minVersion); err != nil {
+			return i18n.Errorf("config.min_version_invalid", label, err)
+		}
+	}
+	if requires == nil {
+		return nil
+	}
+	for _, name := range requires.Env {
+		if !envKeyPattern.MatchString(name) {
+			return i18n.Errorf("config.requires_env_invalid", label, name)
+		}
+	}
+	for _, values := range [][]string{requires.Bins, requires.Files} {
+		for _, value := range values {
+			if strings.TrimSpace(value) == "" {
+				return i18n.Errorf("config.requires_empty", label)
+			}
+		}
+	}
+	return nil
+}
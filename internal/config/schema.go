@@ -1,50 +1,202 @@
 package config
 
 import (
-	"fmt"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/alpen/alpen-cli/internal/i18n"
 )
 
 // Config 表示 demo.yaml 的顶层结构
 type Config struct {
+	Include     IncludeList            `yaml:"include"`
+	Vars        map[string]VarSpec     `yaml:"vars"`
 	Commands    map[string]CommandSpec `yaml:"commands"`
 	Diagnostics []Diagnostic           `yaml:"-"`
+	// Shell 与 Interpreter 为本文件中命令默认使用的解释器，命令与子命令可各自覆盖
+	Shell       string   `yaml:"shell"`
+	Interpreter []string `yaml:"interpreter"`
+	// Priority 为模块目录中文件的合并优先级，数值大的文件后合并并可覆盖数值小的文件中的定义
+	Priority int `yaml:"priority"`
+	// FieldOrigins 记录文件级 vars 的定义位置，键为 vars.<name>
+	FieldOrigins map[string]SourceInfo `yaml:"-"`
+	// Namespaces 为组合加载多个配置时挂载的命名空间
+	Namespaces []string `yaml:"-"`
 }
 
 // CommandSpec 定义一级命令的元数据
 type CommandSpec struct {
 	Alias       string                `yaml:"alias"`
+	Extends     string                `yaml:"extends"`
 	Description string                `yaml:"description"`
 	Command     string                `yaml:"command"`
+	Script      string                `yaml:"script"`
+	Shell       string                `yaml:"shell"`
+	Interpreter []string              `yaml:"interpreter"`
+	Notify      *NotifySpec           `yaml:"notify"`
+	Confirm     *ConfirmSpec          `yaml:"confirm"`
+	Protected   []string              `yaml:"protected_environments"`
+	Env         map[string]string     `yaml:"env"`
+	WorkDir     string                `yaml:"workdir"`
+	Vars        map[string]VarSpec    `yaml:"vars"`
+	Params      map[string]ParamSpec  `yaml:"params"`
 	Actions     map[string]ActionSpec `yaml:"actions"`
-	Origin      SourceInfo            `yaml:"-"`
+	// Platforms、Requires 与 MinAlpenVersion 为执行前置条件，子命令未配置 platforms 与 min_alpen_version 时继承顶层命令，
+	// requires 则与顶层命令合并
+	Platforms       []string      `yaml:"platforms"`
+	Requires        *RequiresSpec `yaml:"requires"`
+	MinAlpenVersion string        `yaml:"min_alpen_version"`
+	// Inline 表示 Command 来自 script 内联脚本，加载时由 script 转存
+	Inline bool `yaml:"-"`
+	// Override 表示有意覆盖其他文件中的同名命令，Disabled 表示移除已有的同名命令
+	Override bool       `yaml:"override"`
+	Disabled bool       `yaml:"disabled"`
+	Origin   SourceInfo `yaml:"-"`
+	// FieldOrigins 记录各字段的定义位置，键为 command、workdir、env.<KEY>、vars.<name>、params.<name>
+	FieldOrigins map[string]SourceInfo `yaml:"-"`
+	// Layers 为参与合并的各配置文件及其设置的字段
+	Layers []Layer `yaml:"-"`
 }
 
 // ActionSpec 定义子命令的元数据
 type ActionSpec struct {
-	Alias       string     `yaml:"alias"`
-	Description string     `yaml:"description"`
-	Command     string     `yaml:"command"`
-	Origin      SourceInfo `yaml:"-"`
+	Alias       string               `yaml:"alias"`
+	Description string               `yaml:"description"`
+	Command     string               `yaml:"command"`
+	Script      string               `yaml:"script"`
+	Shell       string               `yaml:"shell"`
+	Interpreter []string             `yaml:"interpreter"`
+	Notify      *NotifySpec          `yaml:"notify"`
+	Confirm     *ConfirmSpec         `yaml:"confirm"`
+	Protected   []string             `yaml:"protected_environments"`
+	Env         map[string]string    `yaml:"env"`
+	WorkDir     string               `yaml:"workdir"`
+	Vars        map[string]VarSpec   `yaml:"vars"`
+	Params      map[string]ParamSpec `yaml:"params"`
+	// Platforms、Requires 与 MinAlpenVersion 的含义与 CommandSpec 相同
+	Platforms       []string      `yaml:"platforms"`
+	Requires        *RequiresSpec `yaml:"requires"`
+	MinAlpenVersion string        `yaml:"min_alpen_version"`
+	// Inline 的含义与 CommandSpec 相同
+	Inline bool `yaml:"-"`
+	// Override 与 Disabled 的含义与 CommandSpec 相同，Disabled 可移除继承或合并得到的子命令
+	Override bool       `yaml:"override"`
+	Disabled bool       `yaml:"disabled"`
+	Origin   SourceInfo `yaml:"-"`
+	// FieldOrigins 记录各字段的定义位置，键的含义与 CommandSpec 相同
+	FieldOrigins map[string]SourceInfo `yaml:"-"`
+	// Layers 为参与合并的各配置文件及其设置的字段
+	Layers []Layer `yaml:"-"`
+}
+
+// ConfirmSpec 表示执行前是否需要人工确认，YAML 中可写 true/false 或提示文案
+type ConfirmSpec struct {
+	Enabled bool
+	Message string
+}
+
+// UnmarshalYAML 支持 confirm: true 与 confirm: "提示文案" 两种写法
+func (c *ConfirmSpec) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.ScalarNode {
+		return i18n.Errorf("config.confirm_type", node.Line)
+	}
+	if node.Tag == "!!bool" {
+		var enabled bool
+		if err := node.Decode(&enabled); err != nil {
+			return err
+		}
+		*c = ConfirmSpec{Enabled: enabled}
+		return nil
+	}
+	message := strings.TrimSpace(node.Value)
+	*c = ConfirmSpec{Enabled: message != "", Message: message}
+	return nil
+}
+
+// NotifySpec 定义命令结束后的通知方式，子命令未配置时继承顶层命令
+type NotifySpec struct {
+	On       []string          `yaml:"on"`
+	After    Duration          `yaml:"after"`
+	URL      string            `yaml:"url"`
+	Headers  map[string]string `yaml:"headers"`
+	Template string            `yaml:"template"`
+	Command  string            `yaml:"command"`
+	Retries  *int              `yaml:"retries"`
+}
+
+// 通知触发条件
+const (
+	NotifyOnSuccess = "success"
+	NotifyOnFailure = "failure"
+)
+
+// SecretRefPrefix 为 env 中引用本地密钥库的前缀，例如 secret:github/token
+const SecretRefPrefix = "secret:"
+
+var envKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Triggers 返回通知触发条件，未配置时默认成功与失败都通知
+func (n NotifySpec) Triggers() []string {
+	if len(n.On) == 0 {
+		return []string{NotifyOnSuccess, NotifyOnFailure}
+	}
+	return n.On
+}
+
+// Duration 支持在 YAML 中使用 60s、5m 等写法
+type Duration time.Duration
+
+// UnmarshalYAML 解析 time.ParseDuration 格式或整数秒
+func (d *Duration) UnmarshalYAML(node *yaml.Node) error {
+	value := strings.TrimSpace(node.Value)
+	if value == "" {
+		*d = 0
+		return nil
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		*d = Duration(time.Duration(seconds) * time.Second)
+		return nil
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return i18n.Errorf("config.duration_invalid", node.Line, value)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Std 返回标准库 time.Duration
+func (d Duration) Std() time.Duration {
+	return time.Duration(d)
 }
 
 // Validate 对配置进行基础校验，保证命令结构可执行
 func (c *Config) Validate() error {
 	if len(c.Commands) == 0 {
-		return fmt.Errorf("commands 不能为空")
+		return i18n.Errorf("config.commands_empty")
+	}
+	if err := validateVars(i18n.T("config.label.file"), c.Vars); err != nil {
+		return err
+	}
+	if err := validateShell(i18n.T("config.label.file"), c.Shell, c.Interpreter); err != nil {
+		return err
 	}
 	aliasUsage := map[string]string{}
 	for name, spec := range c.Commands {
-		if err := validateIdentifier("命令名称", name); err != nil {
+		if err := validateIdentifier(i18n.T("config.label.command_name"), name); err != nil {
 			return err
 		}
 		if alias := strings.TrimSpace(spec.Alias); alias != "" {
-			if err := validateIdentifier(fmt.Sprintf("命令 %s 的别名", name), alias); err != nil {
+			if err := validateIdentifier(i18n.T("config.label.alias", name), alias); err != nil {
 				return err
 			}
 			if owner, exists := aliasUsage[alias]; exists {
-				return fmt.Errorf("命令 %s 的别名 %s 与命令 %s 冲突", name, alias, owner)
+				return i18n.Errorf("config.alias_conflict", name, alias, owner)
 			}
 			aliasUsage[alias] = name
 		}
@@ -55,27 +207,119 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+func validateProtectedEnvironments(label string, envs []string) error {
+	for _, env := range envs {
+		if err := validateIdentifier(i18n.T("config.label.protected_env", label), env); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateEnv(label string, env map[string]string) error {
+	for key, value := range env {
+		if !envKeyPattern.MatchString(key) {
+			return i18n.Errorf("config.env_name_invalid", label, key)
+		}
+		if name, ok := ParseSecretRef(value); ok && name == "" {
+			return i18n.Errorf("config.env_secret_empty", label, key)
+		}
+	}
+	return nil
+}
+
+// ParseSecretRef 解析 "secret:<name>" 形式的密钥引用
+func ParseSecretRef(value string) (string, bool) {
+	if !strings.HasPrefix(value, SecretRefPrefix) {
+		return "", false
+	}
+	return strings.TrimSpace(strings.TrimPrefix(value, SecretRefPrefix)), true
+}
+
+func validateNotifySpec(label string, spec *NotifySpec) error {
+	if spec == nil {
+		return nil
+	}
+	if strings.TrimSpace(spec.URL) == "" && strings.TrimSpace(spec.Command) == "" {
+		return i18n.Errorf("config.notify_target", label)
+	}
+	for _, trigger := range spec.On {
+		if trigger != NotifyOnSuccess && trigger != NotifyOnFailure {
+			return i18n.Errorf("config.notify_on_invalid", label, NotifyOnSuccess, NotifyOnFailure, trigger)
+		}
+	}
+	if spec.After < 0 {
+		return i18n.Errorf("config.notify_after_negative", label)
+	}
+	if spec.Retries != nil && *spec.Retries < 0 {
+		return i18n.Errorf("config.notify_retries_negative", label)
+	}
+	return nil
+}
+
 func validateCommandSpec(name string, spec CommandSpec) error {
 	if spec.Actions == nil {
 		spec.Actions = map[string]ActionSpec{}
 	}
 	if strings.TrimSpace(spec.Command) == "" && len(spec.Actions) == 0 {
-		return fmt.Errorf("命令 %s 需要提供默认 command 或至少一个 action", name)
+		return i18n.Errorf("config.command_empty", name)
+	}
+	if err := validateNotifySpec(i18n.T("config.label.command", name), spec.Notify); err != nil {
+		return err
+	}
+	if err := validateProtectedEnvironments(i18n.T("config.label.command", name), spec.Protected); err != nil {
+		return err
+	}
+	if err := validateEnv(i18n.T("config.label.command", name), spec.Env); err != nil {
+		return err
+	}
+	if err := validateVars(i18n.T("config.label.command", name), spec.Vars); err != nil {
+		return err
+	}
+	if err := validateParams(i18n.T("config.label.command", name), spec.Params); err != nil {
+		return err
+	}
+	if err := validateRequirements(i18n.T("config.label.command", name), spec.Platforms, spec.Requires, spec.MinAlpenVersion); err != nil {
+		return err
+	}
+	if err := validateShell(i18n.T("config.label.command", name), spec.Shell, spec.Interpreter); err != nil {
+		return err
 	}
 	actionAliases := map[string]string{}
 	for actionName, action := range spec.Actions {
-		if err := validateIdentifier(fmt.Sprintf("命令 %s 的子命令名称", name), actionName); err != nil {
+		if err := validateIdentifier(i18n.T("config.label.action_name", name), actionName); err != nil {
 			return err
 		}
 		if strings.TrimSpace(action.Command) == "" {
-			return fmt.Errorf("命令 %s 的子命令 %s 缺少 command", name, actionName)
+			return i18n.Errorf("config.action_empty", name, actionName)
+		}
+		if err := validateNotifySpec(i18n.T("config.label.action", name, actionName), action.Notify); err != nil {
+			return err
+		}
+		if err := validateProtectedEnvironments(i18n.T("config.label.action", name, actionName), action.Protected); err != nil {
+			return err
+		}
+		if err := validateEnv(i18n.T("config.label.action", name, actionName), action.Env); err != nil {
+			return err
+		}
+		if err := validateVars(i18n.T("config.label.action", name, actionName), action.Vars); err != nil {
+			return err
+		}
+		if err := validateParams(i18n.T("config.label.action", name, actionName), action.Params); err != nil {
+			return err
+		}
+		if err := validateRequirements(i18n.T("config.label.action", name, actionName), action.Platforms, action.Requires, action.MinAlpenVersion); err != nil {
+			return err
+		}
+		if err := validateShell(i18n.T("config.label.action", name, actionName), action.Shell, action.Interpreter); err != nil {
+			return err
 		}
 		if alias := strings.TrimSpace(action.Alias); alias != "" {
-			if err := validateIdentifier(fmt.Sprintf("命令 %s 的子命令 %s 的别名", name, actionName), alias); err != nil {
+			if err := validateIdentifier(i18n.T("config.label.action_alias", name, actionName), alias); err != nil {
 				return err
 			}
 			if owner, exists := actionAliases[alias]; exists {
-				return fmt.Errorf("命令 %s 的子命令别名 %s 同时被 %s 与 %s 使用", name, alias, owner, actionName)
+				return i18n.Errorf("config.action_alias_conflict", name, alias, owner, actionName)
 			}
 			actionAliases[alias] = actionName
 		}
@@ -109,13 +353,13 @@ func (c *CommandSpec) SortedActionNames() []string {
 func validateIdentifier(label, value string) error {
 	trimmed := strings.TrimSpace(value)
 	if trimmed == "" {
-		return fmt.Errorf("%s不能为空", label)
+		return i18n.Errorf("config.identifier_empty", label)
 	}
 	if trimmed != value {
-		return fmt.Errorf("%s %q 含有首尾空白字符", label, value)
+		return i18n.Errorf("config.identifier_padded", label, value)
 	}
 	if strings.ContainsAny(trimmed, " \t\n\r") {
-		return fmt.Errorf("%s %s 不应包含空白字符", label, trimmed)
+		return i18n.Errorf("config.identifier_space", label, trimmed)
 	}
 	return nil
 }
@@ -1,6 +1,11 @@
 package config
 
-import "testing"
+import (
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
 
 func TestConfigValidateSuccess(t *testing.T) {
 	cfg := &Config{
@@ -77,3 +82,230 @@ func TestCommandActionAliasConflict(t *testing.T) {
 		t.Fatalf("expected duplicate action alias to trigger validation error")
 	}
 }
+
+func TestNotifySpecParsingAndValidation(t *testing.T) {
+	var cfg Config
+	content := []byte(`
+commands:
+  build:
+    command: make
+    notify:
+      on: [failure, success]
+      after: 60s
+      url: https://hooks.example.com/build
+    actions:
+      release:
+        command: make release
+`)
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected notify config to be valid, got %v", err)
+	}
+	target, ok := cfg.ResolveTarget([]string{"build", "release"})
+	if !ok || target.Notify == nil {
+		t.Fatalf("expected action to inherit notify config")
+	}
+	if target.Notify.After.Std() != time.Minute {
+		t.Fatalf("expected after=1m, got %s", target.Notify.After.Std())
+	}
+
+	cfg.Commands["build"].Notify.URL = ""
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("expected notify without url or command to fail validation")
+	}
+}
+
+func TestConfirmAndProtectedEnvironmentsInheritance(t *testing.T) {
+	var cfg Config
+	content := []byte(`
+commands:
+  db:
+    confirm: "该操作会修改数据库"
+    protected_environments: [prod]
+    actions:
+      drop:
+        command: ./drop.sh
+      status:
+        command: ./status.sh
+        confirm: false
+        protected_environments: []
+`)
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected config to be valid, got %v", err)
+	}
+
+	drop, _ := cfg.ResolveTarget([]string{"db", "drop"})
+	if !drop.Confirm.Enabled || drop.Confirm.Message != "该操作会修改数据库" {
+		t.Fatalf("expected drop to inherit confirm message, got %+v", drop.Confirm)
+	}
+	if required, protected := drop.GuardFor("prod"); !required || !protected {
+		t.Fatalf("expected prod to be protected for drop")
+	}
+	if required, protected := drop.GuardFor("dev"); !required || protected {
+		t.Fatalf("expected confirm to still be required outside protected environments")
+	}
+
+	status, _ := cfg.ResolveTarget([]string{"db", "status"})
+	if required, _ := status.GuardFor("prod"); required {
+		t.Fatalf("expected status to opt out of guards")
+	}
+}
+
+func TestEnvSecretReferencesAndInheritance(t *testing.T) {
+	var cfg Config
+	content := []byte(`
+commands:
+  gh:
+    env:
+      GH_TOKEN: "secret:github/token"
+      MODE: base
+    actions:
+      release:
+        command: ./release.sh
+        env:
+          MODE: release
+`)
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected config to be valid, got %v", err)
+	}
+	release, _ := cfg.ResolveTarget([]string{"gh", "release"})
+	if release.Env["GH_TOKEN"] != "secret:github/token" || release.Env["MODE"] != "release" {
+		t.Fatalf("unexpected merged env: %+v", release.Env)
+	}
+	if name, ok := ParseSecretRef(release.Env["GH_TOKEN"]); !ok || name != "github/token" {
+		t.Fatalf("unexpected secret ref: %q %v", name, ok)
+	}
+
+	invalid := Config{Commands: map[string]CommandSpec{
+		"gh": {Command: "true", Env: map[string]string{"1BAD": "x"}},
+	}}
+	if err := invalid.Validate(); err == nil {
+		t.Fatalf("expected invalid env key to be rejected")
+	}
+	emptyRef := Config{Commands: map[string]CommandSpec{
+		"gh": {Command: "true", Env: map[string]string{"TOKEN": "secret:"}},
+	}}
+	if err := emptyRef.Validate(); err == nil {
+		t.Fatalf("expected empty secret reference to be rejected")
+	}
+}
+
+func TestRequirementsInheritanceAndValidation(t *testing.T) {
+	var cfg Config
+	content := []byte(`
+commands:
+  build:
+    platforms: [linux, darwin]
+    requires:
+      bins: [make]
+      env: [GOPATH]
+    min_alpen_version: "1.2.0"
+    command: make
+    actions:
+      image:
+        platforms: [linux]
+        requires:
+          bins: [docker, make]
+        command: make image
+`)
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected config to be valid, got %v", err)
+	}
+
+	image, _ := cfg.ResolveTarget([]string{"build", "image"})
+	if len(image.Platforms) != 1 || image.Platforms[0] != "linux" {
+		t.Fatalf("expected action platforms to override command, got %v", image.Platforms)
+	}
+	if len(image.Requires.Bins) != 2 || image.Requires.Bins[0] != "make" || image.Requires.Bins[1] != "docker" {
+		t.Fatalf("expected requires to be merged without duplicates, got %v", image.Requires.Bins)
+	}
+	if len(image.Requires.Env) != 1 || image.MinAlpenVersion != "1.2.0" {
+		t.Fatalf("expected env and min version to be inherited, got %+v / %q", image.Requires, image.MinAlpenVersion)
+	}
+
+	invalid := []string{
+		"commands:\n  x:\n    platforms: [plan9]\n    command: echo\n",
+		"commands:\n  x:\n    min_alpen_version: latest\n    command: echo\n",
+		"commands:\n  x:\n    requires:\n      bins: [\"\"]\n    command: echo\n",
+	}
+	for _, source := range invalid {
+		var broken Config
+		if err := yaml.Unmarshal([]byte(source), &broken); err != nil {
+			t.Fatalf("unmarshal failed: %v", err)
+		}
+		if err := broken.Validate(); err == nil {
+			t.Fatalf("expected validation to fail for %q", source)
+		}
+	}
+}
+
+func TestShellInterpreterAndInlineScript(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFiles(t, dir, map[string]string{
+		"demo.yaml": `shell: bash
+commands:
+  tool:
+    script: |
+      echo one
+      echo two
+    actions:
+      py:
+        shell: python3
+        script: print("hi")
+      raw:
+        interpreter: ["node", "-e"]
+        command: console.log(1)
+      plain:
+        command: echo plain
+`,
+	})
+	cfg, err := NewLoader(dir).Load("demo.yaml", "")
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	tool, _ := cfg.ResolveTarget([]string{"tool"})
+	if !tool.Inline || tool.Command != "echo one\necho two\n" {
+		t.Fatalf("expected inline script body, got %+v", tool)
+	}
+	if len(tool.Interpreter) == 0 || tool.Interpreter[0] != "bash" {
+		t.Fatalf("expected file level shell to apply, got %v", tool.Interpreter)
+	}
+	if tool.FieldOrigins[OriginCommand].Line != 4 {
+		t.Fatalf("expected script origin line 4, got %d", tool.FieldOrigins[OriginCommand].Line)
+	}
+	py, _ := cfg.ResolveTarget([]string{"tool", "py"})
+	if !py.Inline || py.Interpreter[0] != "python3" {
+		t.Fatalf("expected action shell to override, got %+v", py.Interpreter)
+	}
+	raw, _ := cfg.ResolveTarget([]string{"tool", "raw"})
+	if raw.Inline || raw.Interpreter[0] != "node" {
+		t.Fatalf("expected explicit interpreter, got %+v", raw.Interpreter)
+	}
+	plain, _ := cfg.ResolveTarget([]string{"tool", "plain"})
+	if plain.Interpreter[0] != "bash" {
+		t.Fatalf("expected action to inherit command shell, got %v", plain.Interpreter)
+	}
+
+	invalid := map[string]string{
+		"both.yaml":  "commands:\n  x:\n    command: echo\n    script: echo\n",
+		"shell.yaml": "commands:\n  x:\n    shell: fish\n    command: echo\n",
+		"mixed.yaml": "commands:\n  x:\n    shell: bash\n    interpreter: [bash, -c]\n    command: echo\n",
+	}
+	writeConfigFiles(t, dir, invalid)
+	for name := range invalid {
+		if _, err := NewLoader(dir).Load(name, ""); err == nil {
+			t.Fatalf("expected %s to fail", name)
+		}
+	}
+}
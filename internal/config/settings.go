@@ -0,0 +1,201 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/alpen/alpen-cli/internal/i18n"
+	"github.com/alpen/alpen-cli/internal/logging"
+)
+
+// SettingsFileName 为全局设置文件名，位于 ~/.alpen/config 下
+const SettingsFileName = "settings.yaml"
+
+// ThemeFileName 为界面主题文件名，位于 ~/.alpen/config 下
+const ThemeFileName = "theme.yaml"
+
+// reservedConfigNames 为 config 目录下不属于命令配置的保留文件
+var reservedConfigNames = map[string]struct{}{
+	SettingsFileName: {},
+	ThemeFileName:    {},
+}
+
+// IsReservedConfigName 判断文件名是否为保留的非命令配置文件
+func IsReservedConfigName(name string) bool {
+	_, ok := reservedConfigNames[strings.ToLower(strings.TrimSpace(name))]
+	return ok
+}
+
+// Settings 描述与具体命令配置无关的全局设置
+type Settings struct {
+	// Lang 为界面语言（zh-CN 或 en），未配置时按 LC_ALL/LC_MESSAGES/LANG 选择
+	Lang    string          `yaml:"lang"`
+	Tracing TracingSettings `yaml:"tracing"`
+	Metrics MetricsSettings `yaml:"metrics"`
+	Scripts ScriptSettings  `yaml:"scripts"`
+	Secrets SecretSettings  `yaml:"secrets"`
+	Redact  RedactSettings  `yaml:"redact"`
+	Log     LogSettings     `yaml:"log"`
+}
+
+// LogSettings 描述 alpen 自身日志的级别、格式与输出位置，脚本输出不受影响
+type LogSettings struct {
+	// Level 为 debug、info、warn（默认）或 error
+	Level string `yaml:"level"`
+	// Format 为 text（默认）或 json
+	Format string `yaml:"format"`
+	// File 不为空时日志追加写入该文件，不再输出到标准错误
+	File string `yaml:"file"`
+}
+
+// RedactSettings 描述输出、日志与插件载荷中额外的遮蔽规则
+type RedactSettings struct {
+	// Env 为额外视为敏感的环境变量名通配符，默认已包含 *_TOKEN、*_KEY、*PASSWORD* 等
+	Env []string `yaml:"env"`
+	// Patterns 为需要遮蔽的正则表达式，例如 ghp_[A-Za-z0-9]{36}
+	Patterns []string `yaml:"patterns"`
+}
+
+// SecretSettings 描述本地密钥库的解锁方式
+type SecretSettings struct {
+	// KeyFile 为解锁密钥库的密钥文件，未配置时使用口令
+	KeyFile string `yaml:"key_file"`
+}
+
+// ScriptSettings 描述脚本完整性校验策略
+type ScriptSettings struct {
+	// TrustPolicy 为脚本哈希变化时的处理方式：warn（默认）、block 或 off
+	TrustPolicy string `yaml:"trust_policy"`
+}
+
+// MetricsSettings 描述执行指标的导出方式
+type MetricsSettings struct {
+	// Textfile 为 node-exporter textfile collector 读取的 .prom 文件路径
+	Textfile string `yaml:"textfile"`
+}
+
+// TracingSettings 描述执行链路追踪的导出方式
+type TracingSettings struct {
+	Enabled     *bool             `yaml:"enabled"`
+	Endpoint    string            `yaml:"endpoint"`
+	Headers     map[string]string `yaml:"headers"`
+	File        string            `yaml:"file"`
+	ServiceName string            `yaml:"service_name"`
+}
+
+// SettingsPath 返回全局设置文件的绝对路径
+func SettingsPath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, SettingsFileName), nil
+}
+
+// ThemePath 返回界面主题文件的绝对路径
+func ThemePath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, ThemeFileName), nil
+}
+
+// LoadSettings 读取全局设置并应用环境变量覆盖，文件不存在时返回默认值
+func LoadSettings() (*Settings, error) {
+	settings := &Settings{}
+	path, err := SettingsPath()
+	if err != nil {
+		return settings, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return settings, err
+	}
+	if err == nil {
+		if err := yaml.Unmarshal(data, settings); err != nil {
+			return &Settings{}, i18n.Errorf("settings.parse_failed", path, err)
+		}
+	}
+	settings.applyEnv()
+	if err := settings.validate(); err != nil {
+		return settings, i18n.Errorf("settings.invalid", path, err)
+	}
+	return settings, nil
+}
+
+func (s *Settings) validate() error {
+	if lang := strings.TrimSpace(s.Lang); lang != "" {
+		if _, ok := i18n.Normalize(lang); !ok {
+			return i18n.Errorf("settings.lang_unsupported", strings.Join(i18n.Locales(), i18n.T("common.list_sep")), s.Lang)
+		}
+	}
+	if _, err := logging.ParseLevel(s.Log.Level); err != nil {
+		return err
+	}
+	if _, err := logging.ParseFormat(s.Log.Format); err != nil {
+		return err
+	}
+	switch strings.ToLower(strings.TrimSpace(s.Scripts.TrustPolicy)) {
+	case "", "warn", "block", "off":
+		return nil
+	default:
+		return i18n.Errorf("settings.trust_policy_invalid", s.Scripts.TrustPolicy)
+	}
+}
+
+// applyEnv 使用 OpenTelemetry 约定的环境变量及 ALPEN_* 变量覆盖文件中的设置
+func (s *Settings) applyEnv() {
+	if endpoint := strings.TrimSpace(os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")); endpoint != "" {
+		s.Tracing.Endpoint = endpoint
+	} else if endpoint := strings.TrimSpace(os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")); endpoint != "" {
+		s.Tracing.Endpoint = strings.TrimRight(endpoint, "/") + "/v1/traces"
+	}
+	if name := strings.TrimSpace(os.Getenv("OTEL_SERVICE_NAME")); name != "" {
+		s.Tracing.ServiceName = name
+	}
+	if textfile := strings.TrimSpace(os.Getenv("ALPEN_METRICS_TEXTFILE")); textfile != "" {
+		s.Metrics.Textfile = textfile
+	}
+	if file := strings.TrimSpace(os.Getenv("ALPEN_TRACE_FILE")); file != "" {
+		s.Tracing.File = file
+	}
+	if keyFile := strings.TrimSpace(os.Getenv("ALPEN_SECRET_KEY_FILE")); keyFile != "" {
+		s.Secrets.KeyFile = keyFile
+	}
+	if lang := strings.TrimSpace(os.Getenv("ALPEN_LANG")); lang != "" {
+		s.Lang = lang
+	}
+	if level := strings.TrimSpace(os.Getenv("ALPEN_LOG_LEVEL")); level != "" {
+		s.Log.Level = level
+	}
+	if format := strings.TrimSpace(os.Getenv("ALPEN_LOG_FORMAT")); format != "" {
+		s.Log.Format = format
+	}
+	if file := strings.TrimSpace(os.Getenv("ALPEN_LOG_FILE")); file != "" {
+		s.Log.File = file
+	}
+	if policy := strings.TrimSpace(os.Getenv("ALPEN_TRUST_POLICY")); policy != "" {
+		s.Scripts.TrustPolicy = policy
+	}
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("ALPEN_TRACE"))) {
+	case "1", "true", "on":
+		enabled := true
+		s.Tracing.Enabled = &enabled
+	case "0", "false", "off":
+		enabled := false
+		s.Tracing.Enabled = &enabled
+	}
+}
+
+// Active 判断是否启用追踪：显式配置 enabled 时以其为准，否则在配置了导出目标时启用
+func (t TracingSettings) Active() bool {
+	if t.Enabled != nil {
+		return *t.Enabled
+	}
+	return strings.TrimSpace(t.Endpoint) != "" || strings.TrimSpace(t.File) != ""
+}
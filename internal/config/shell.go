@@ -0,0 +1,83 @@
+package config
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/alpen/alpen-cli/internal/i18n"
+)
+
+// shellPresets 为 shell 支持的取值及其执行命令字符串时的参数前缀，
+// bash 默认启用 -euo pipefail
+var shellPresets = map[string][]string{
+	"sh":      {"sh", "-c"},
+	"bash":    {"bash", "-euo", "pipefail", "-c"},
+	"zsh":     {"zsh", "-c"},
+	"python3": {"python3", "-c"},
+	"node":    {"node", "-e"},
+	"pwsh":    {"pwsh", "-NoProfile", "-Command"},
+	"cmd":     {"cmd.exe", "/C"},
+}
+
+// ShellNames 返回 shell 支持的取值，按名称排序
+func ShellNames() []string {
+	names := make([]string, 0, len(shellPresets))
+	for name := range shellPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolveInterpreter 将同一层级的 shell 与 interpreter 解析为参数前缀，均未配置时返回 nil
+func resolveInterpreter(shell string, interpreter []string) []string {
+	if len(interpreter) > 0 {
+		return interpreter
+	}
+	if preset, ok := shellPresets[strings.TrimSpace(shell)]; ok {
+		return preset
+	}
+	return nil
+}
+
+// firstInterpreter 返回按优先级排列的各层级中第一个配置的解释器
+func firstInterpreter(levels ...[]string) []string {
+	for _, level := range levels {
+		if len(level) > 0 {
+			return level
+		}
+	}
+	return nil
+}
+
+// normalizeScript 将 script 转存到 command 并标记为内联脚本，两者同时配置时返回错误
+func normalizeScript(label string, command *string, script *string, inline *bool) error {
+	if strings.TrimSpace(*script) == "" {
+		*script = ""
+		return nil
+	}
+	if strings.TrimSpace(*command) != "" {
+		return i18n.Errorf("config.command_and_script", label)
+	}
+	*command = *script
+	*script = ""
+	*inline = true
+	return nil
+}
+
+func validateShell(label string, shell string, interpreter []string) error {
+	shell = strings.TrimSpace(shell)
+	if shell != "" && len(interpreter) > 0 {
+		return i18n.Errorf("config.shell_and_interpreter", label)
+	}
+	if shell != "" {
+		if _, ok := shellPresets[shell]; !ok {
+			return i18n.Errorf("config.shell_unsupported", label, shell, strings.Join(ShellNames(), ", "))
+		}
+	}
+	if slices.ContainsFunc(interpreter, func(arg string) bool { return strings.TrimSpace(arg) == "" }) {
+		return i18n.Errorf("config.interpreter_empty", label)
+	}
+	return nil
+}
@@ -4,6 +4,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/alpen/alpen-cli/internal/i18n"
 )
 
 const (
@@ -23,37 +25,124 @@ func ResolveHomeDir() (string, error) {
 	return filepath.Join(home, defaultHomeDirName), nil
 }
 
-// LoadActiveConfigPath 读取当前激活的配置文件路径
-func LoadActiveConfigPath() (string, error) {
+// StateDir 返回 ~/.alpen/state 的绝对路径
+func StateDir() (string, error) {
 	home, err := ResolveHomeDir()
 	if err != nil {
 		return "", err
 	}
+	return filepath.Join(home, stateDirName), nil
+}
+
+// StatePath 返回状态目录下指定文件的绝对路径
+func StatePath(name string) (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// ActiveConfig 描述一个激活的配置，Namespace 非空时其命令挂载在该命名空间下（例如 alpen work deploy）
+type ActiveConfig struct {
+	Path      string
+	Namespace string
+}
+
+// Label 返回用于展示与错误提示的配置描述
+func (a ActiveConfig) Label() string {
+	if a.Namespace == "" {
+		return a.Path
+	}
+	return i18n.T("config.active_namespace", a.Path, a.Namespace)
+}
+
+// IsComposite 判断激活配置是否需要组合加载：多个配置或使用了命名空间
+func IsComposite(entries []ActiveConfig) bool {
+	return len(entries) > 1 || (len(entries) == 1 && entries[0].Namespace != "")
+}
+
+// LoadActiveConfigs 读取激活的配置列表，每行一个配置，格式为 <路径> 或 <命名空间>=<路径>
+func LoadActiveConfigs() ([]ActiveConfig, error) {
+	home, err := ResolveHomeDir()
+	if err != nil {
+		return nil, err
+	}
 	path := filepath.Join(home, stateDirName, activeConfigFileName)
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return "", nil
+			return nil, nil
 		}
-		return "", err
+		return nil, err
 	}
-	return strings.TrimSpace(string(data)), nil
+	var entries []ActiveConfig
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entry := ActiveConfig{Path: line}
+		// 路径均为绝对路径，= 之前不含路径分隔符时才视为命名空间
+		if name, rest, ok := strings.Cut(line, "="); ok && !strings.ContainsAny(name, `/\`) {
+			entry = ActiveConfig{Path: strings.TrimSpace(rest), Namespace: strings.TrimSpace(name)}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
 }
 
-// SaveActiveConfigPath 将选中的配置文件路径写入状态目录
-func SaveActiveConfigPath(configPath string) error {
+// SaveActiveConfigs 将激活的配置列表写入状态目录
+func SaveActiveConfigs(entries []ActiveConfig) error {
+	if len(entries) == 0 {
+		return i18n.Errorf("config.active_required")
+	}
 	home, err := ResolveHomeDir()
 	if err != nil {
 		return err
 	}
-	normalized, err := NormalizeConfigPath(configPath)
-	if err != nil {
-		return err
+	seen := map[string]bool{}
+	var lines []string
+	for _, entry := range entries {
+		normalized, err := NormalizeConfigPath(entry.Path)
+		if err != nil {
+			return err
+		}
+		namespace := strings.TrimSpace(entry.Namespace)
+		if namespace != "" {
+			if err := validateNamespace(namespace); err != nil {
+				return err
+			}
+		}
+		key := namespace + "=" + normalized
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if namespace == "" {
+			lines = append(lines, normalized)
+		} else {
+			lines = append(lines, key)
+		}
 	}
 	stateDir := filepath.Join(home, stateDirName)
 	if err := os.MkdirAll(stateDir, defaultDirPermission); err != nil {
 		return err
 	}
 	path := filepath.Join(stateDir, activeConfigFileName)
-	return os.WriteFile(path, []byte(normalized), defaultFilePermission)
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), defaultFilePermission)
+}
+
+// LoadActiveConfigPath 读取当前激活的配置文件路径，激活了多个配置时返回第一个
+func LoadActiveConfigPath() (string, error) {
+	entries, err := LoadActiveConfigs()
+	if err != nil || len(entries) == 0 {
+		return "", err
+	}
+	return entries[0].Path, nil
+}
+
+// SaveActiveConfigPath 将选中的配置文件路径写入状态目录，替换已激活的全部配置
+func SaveActiveConfigPath(configPath string) error {
+	return SaveActiveConfigs([]ActiveConfig{{Path: configPath}})
 }
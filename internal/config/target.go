@@ -0,0 +1,178 @@
+package config
+
+import "strings"
+
+// Target 描述一条可执行的命令路径（顶层命令的默认动作或子命令）
+type Target struct {
+	Path    []string
+	Command string
+	// Inline 表示 Command 为 script 内联脚本的内容
+	Inline bool
+	// Interpreter 为执行命令所用的解释器参数前缀，为空时使用平台默认的 shell
+	Interpreter []string
+	Description string
+	Notify      *NotifySpec
+	Confirm     ConfirmSpec
+	Protected   []string
+	Env         map[string]string
+	WorkDir     string
+	Vars        map[string]VarSpec
+	Params      map[string]ParamSpec
+	// Platforms、Requires 与 MinAlpenVersion 为执行前置条件
+	Platforms       []string
+	Requires        RequiresSpec
+	MinAlpenVersion string
+	Origin          SourceInfo
+	// FieldOrigins 记录合并后各字段实际生效的定义位置
+	FieldOrigins map[string]SourceInfo
+}
+
+// GuardFor 判断在指定环境下执行前是否需要确认，protectedEnv 表示是否因受保护环境触发
+func (t Target) GuardFor(environment string) (required bool, protectedEnv bool) {
+	environment = strings.TrimSpace(environment)
+	if environment != "" {
+		for _, env := range t.Protected {
+			if env == environment {
+				return true, true
+			}
+		}
+	}
+	return t.Confirm.Enabled, false
+}
+
+// Label 返回以空格连接的命令路径
+func (t Target) Label() string {
+	return strings.Join(t.Path, " ")
+}
+
+// ResolveTarget 按命令路径查找可执行目标，path 长度为 1 时返回顶层命令的默认动作，
+// 文件级 vars 会合并到目标中
+func (c *Config) ResolveTarget(path []string) (Target, bool) {
+	if c == nil || len(path) == 0 || len(path) > 2 {
+		return Target{}, false
+	}
+	spec, ok := c.Commands[path[0]]
+	if !ok {
+		return Target{}, false
+	}
+	var target Target
+	if len(path) == 1 {
+		target = spec.Target(path[0])
+	} else {
+		action, ok := spec.Actions[path[1]]
+		if !ok {
+			return Target{}, false
+		}
+		target = spec.ActionTarget(path[0], path[1], action)
+	}
+	target.Vars = mergeByKey(c.Vars, target.Vars)
+	target.FieldOrigins = mergeByKey(c.FieldOrigins, target.FieldOrigins)
+	return target, true
+}
+
+// Target 返回顶层命令默认动作对应的执行目标
+func (c CommandSpec) Target(name string) Target {
+	return Target{
+		Path:            []string{name},
+		Command:         commandBody(c.Command, c.Inline),
+		Inline:          c.Inline,
+		Interpreter:     resolveInterpreter(c.Shell, c.Interpreter),
+		Description:     strings.TrimSpace(c.Description),
+		Notify:          c.Notify,
+		Confirm:         derefConfirm(c.Confirm),
+		Protected:       c.Protected,
+		Env:             c.Env,
+		WorkDir:         strings.TrimSpace(c.WorkDir),
+		Vars:            c.Vars,
+		Params:          c.Params,
+		Platforms:       c.Platforms,
+		Requires:        mergeRequires(c.Requires, nil),
+		MinAlpenVersion: strings.TrimSpace(c.MinAlpenVersion),
+		Origin:          c.Origin,
+		FieldOrigins:    c.FieldOrigins,
+	}
+}
+
+// ActionTarget 返回子命令对应的执行目标
+func (c CommandSpec) ActionTarget(name string, actionName string, action ActionSpec) Target {
+	notify := action.Notify
+	if notify == nil {
+		notify = c.Notify
+	}
+	confirm := action.Confirm
+	if confirm == nil {
+		confirm = c.Confirm
+	}
+	protected := action.Protected
+	if protected == nil {
+		protected = c.Protected
+	}
+	workDir := strings.TrimSpace(action.WorkDir)
+	if workDir == "" {
+		workDir = strings.TrimSpace(c.WorkDir)
+	}
+	platforms := action.Platforms
+	if platforms == nil {
+		platforms = c.Platforms
+	}
+	minVersion := strings.TrimSpace(action.MinAlpenVersion)
+	if minVersion == "" {
+		minVersion = strings.TrimSpace(c.MinAlpenVersion)
+	}
+	return Target{
+		Path:            []string{name, actionName},
+		Command:         commandBody(action.Command, action.Inline),
+		Inline:          action.Inline,
+		Interpreter:     firstInterpreter(resolveInterpreter(action.Shell, action.Interpreter), resolveInterpreter(c.Shell, c.Interpreter)),
+		Description:     strings.TrimSpace(action.Description),
+		Notify:          notify,
+		Confirm:         derefConfirm(confirm),
+		Protected:       protected,
+		Env:             mergeByKey(c.Env, action.Env),
+		WorkDir:         workDir,
+		Vars:            mergeByKey(c.Vars, action.Vars),
+		Params:          mergeByKey(c.Params, action.Params),
+		Platforms:       platforms,
+		Requires:        mergeRequires(c.Requires, action.Requires),
+		MinAlpenVersion: minVersion,
+		Origin:          action.Origin,
+		FieldOrigins:    mergeByKey(c.FieldOrigins, action.FieldOrigins),
+	}
+}
+
+// commandBody 返回去除首尾空白的命令，内联脚本只去除首尾空行以保留缩进
+func commandBody(command string, inline bool) string {
+	if inline {
+		return strings.Trim(command, "\n") + "\n"
+	}
+	return strings.TrimSpace(command)
+}
+
+func derefConfirm(spec *ConfirmSpec) ConfirmSpec {
+	if spec == nil {
+		return ConfirmSpec{}
+	}
+	return *spec
+}
+
+// Targets 返回配置中全部可执行目标（顶层命令的默认动作与各子命令），按命令路径排序
+func (c *Config) Targets() []Target {
+	if c == nil {
+		return nil
+	}
+	var targets []Target
+	for _, name := range c.SortedCommandNames() {
+		spec := c.Commands[name]
+		if strings.TrimSpace(spec.Command) != "" {
+			if target, ok := c.ResolveTarget([]string{name}); ok {
+				targets = append(targets, target)
+			}
+		}
+		for _, actionName := range spec.SortedActionNames() {
+			if target, ok := c.ResolveTarget([]string{name, actionName}); ok {
+				targets = append(targets, target)
+			}
+		}
+	}
+	return targets
+}
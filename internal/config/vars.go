@@ -0,0 +1,103 @@
+package config
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/alpen/alpen-cli/internal/i18n"
+)
+
+// VarSpec 定义一个模板变量，取值来源为字面量（可引用其他变量）、环境变量或 shell 命令输出
+type VarSpec struct {
+	Value   string `yaml:"value"`
+	Env     string `yaml:"env"`
+	Sh      string `yaml:"sh"`
+	Default string `yaml:"default"`
+}
+
+// UnmarshalYAML 支持直接书写字面量，或使用 value/env/sh 映射
+func (v *VarSpec) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*v = VarSpec{Value: node.Value}
+		return nil
+	}
+	type plain VarSpec
+	var decoded plain
+	if err := node.Decode(&decoded); err != nil {
+		return err
+	}
+	*v = VarSpec(decoded)
+	return nil
+}
+
+// Source 返回变量的取值方式：value、env 或 sh
+func (v VarSpec) Source() string {
+	switch {
+	case v.Env != "":
+		return "env"
+	case v.Sh != "":
+		return "sh"
+	default:
+		return "value"
+	}
+}
+
+// ParamSpec 定义命令参数，执行时通过 --param name=value 传入
+type ParamSpec struct {
+	Description string  `yaml:"description"`
+	Default     *string `yaml:"default"`
+}
+
+// UnmarshalYAML 支持直接书写默认值，或使用 description/default 映射
+func (p *ParamSpec) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		value := node.Value
+		*p = ParamSpec{Default: &value}
+		return nil
+	}
+	type plain ParamSpec
+	var decoded plain
+	if err := node.Decode(&decoded); err != nil {
+		return err
+	}
+	*p = ParamSpec(decoded)
+	return nil
+}
+
+// Required 表示参数没有默认值，执行时必须提供
+func (p ParamSpec) Required() bool {
+	return p.Default == nil
+}
+
+func validateVars(label string, vars map[string]VarSpec) error {
+	for name, spec := range vars {
+		if !envKeyPattern.MatchString(name) {
+			return i18n.Errorf("config.var_name_invalid", label, name)
+		}
+		sources := 0
+		for _, value := range []string{spec.Value, spec.Env, spec.Sh} {
+			if value != "" {
+				sources++
+			}
+		}
+		if sources > 1 {
+			return i18n.Errorf("config.var_sources", label, name)
+		}
+	}
+	return nil
+}
+
+func validateParams(label string, params map[string]ParamSpec) error {
+	for name := range params {
+		if !envKeyPattern.MatchString(name) {
+			return i18n.Errorf("config.param_name_invalid", label, name)
+		}
+	}
+	return nil
+}
+
+// HasTemplate 判断字符串是否包含模板表达式
+func HasTemplate(value string) bool {
+	return strings.Contains(value, "{{")
+}
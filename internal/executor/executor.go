@@ -5,11 +5,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"runtime"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -17,19 +18,32 @@ import (
 	"github.com/atotto/clipboard"
 	"github.com/kballard/go-shellquote"
 
+	"github.com/alpen/alpen-cli/internal/config"
+	"github.com/alpen/alpen-cli/internal/i18n"
 	"github.com/alpen/alpen-cli/internal/lifecycle"
+	"github.com/alpen/alpen-cli/internal/logging"
 	"github.com/alpen/alpen-cli/internal/plugins"
+	"github.com/alpen/alpen-cli/internal/redact"
 	"github.com/alpen/alpen-cli/internal/scripts"
+	"github.com/alpen/alpen-cli/internal/tracing"
 	"github.com/alpen/alpen-cli/internal/ui"
 )
 
 // Executor 负责执行脚本命令，并在执行前后触发生命周期事件
 type Executor struct {
 	plugins  *plugins.Registry
-	logger   *log.Logger
+	logger   *slog.Logger
 	rootOnce sync.Once
 	rootPath string
 	rootErr  error
+	trust    *scripts.TrustChecker
+	secrets  SecretResolver
+	redactor *redact.Redactor
+}
+
+// SecretResolver 按名称读取本地密钥库中的密钥
+type SecretResolver interface {
+	Resolve(name string) (string, error)
 }
 
 // ScriptRequest 描述一次命令执行所需的参数
@@ -40,7 +54,17 @@ type ScriptRequest struct {
 	ExtraArgs   []string
 	ExtraEnv    map[string]string
 	WorkingDir  string
-	DryRun      bool
+	// Interpreter 为解释器参数前缀，为空时使用平台默认 shell；Inline 表示 Command 为内联脚本内容
+	Interpreter []string
+	Inline      bool
+	// Output 不为空时实时写入遮蔽后的标准输出与错误输出，不再在结束后打印，子进程也不读取终端输入
+	Output      io.Writer
+	ConfigPath  string
+	Environment string
+	Source      string
+	// FieldOrigins 为命令、workdir、env 等字段的定义位置，用于 dry-run 展示
+	FieldOrigins map[string]config.SourceInfo
+	DryRun       bool
 }
 
 // Result 表示脚本执行结果
@@ -50,12 +74,12 @@ type Result struct {
 }
 
 // NewExecutor 构造执行器
-func NewExecutor(registry *plugins.Registry, logger *log.Logger) *Executor {
+func NewExecutor(registry *plugins.Registry, logger *slog.Logger) *Executor {
 	if registry == nil {
 		registry = plugins.NewRegistry()
 	}
 	if logger == nil {
-		logger = log.New(os.Stdout, "[alpen] ", log.LstdFlags)
+		logger = logging.Stderr()
 	}
 	return &Executor{
 		plugins: registry,
@@ -63,97 +87,153 @@ func NewExecutor(registry *plugins.Registry, logger *log.Logger) *Executor {
 	}
 }
 
+// SetTrustChecker 设置脚本完整性校验，nil 表示不校验
+func (e *Executor) SetTrustChecker(checker *scripts.TrustChecker) {
+	e.trust = checker
+}
+
+// SetRedactor 设置输出、日志与插件载荷的遮蔽规则，nil 时使用默认规则
+func (e *Executor) SetRedactor(redactor *redact.Redactor) {
+	e.redactor = redactor
+}
+
+// Redactor 返回当前的遮蔽规则，未设置时为 nil（nil 按默认规则遮蔽）
+func (e *Executor) Redactor() *redact.Redactor {
+	if e == nil {
+		return nil
+	}
+	return e.redactor
+}
+
+// SetSecretResolver 设置 env 中 secret: 引用的解析方式
+func (e *Executor) SetSecretResolver(resolver SecretResolver) {
+	e.secrets = resolver
+}
+
 // Execute 运行脚本并在过程中派发事件
 func (e *Executor) Execute(ctx context.Context, req ScriptRequest) (Result, error) {
+	ctx, span := tracing.Start(ctx, "alpen.execute")
+	span.SetAttr("alpen.command.path", strings.Join(req.CommandPath, " "))
+	span.SetAttr("alpen.environment", req.Environment)
+	span.SetAttr("alpen.config.file", req.ConfigPath)
+	span.SetAttr("alpen.dry_run", req.DryRun)
+	result, err := e.execute(ctx, req)
+	span.SetAttr("alpen.exit_code", result.ExitCode)
+	span.RecordError(err)
+	span.End()
+	return result, err
+}
+
+func (e *Executor) execute(ctx context.Context, req ScriptRequest) (Result, error) {
 	pathLabel := strings.Join(req.CommandPath, " ")
 	if strings.TrimSpace(pathLabel) == "" {
 		pathLabel = "<anonymous>"
 	}
 	if strings.TrimSpace(req.Command) == "" {
-		err := errors.New("command 不能为空")
-		e.logger.Printf("执行失败 path=%s err=%v", pathLabel, err)
+		err := i18n.Errorf("executor.command_empty")
+		e.logger.Error(i18n.T("executor.log.failed"), "path", pathLabel, "err", err)
 		return Result{}, err
 	}
-	if err := e.validateScriptCommand(req); err != nil {
-		e.logger.Printf("脚本校验失败 path=%s err=%v", pathLabel, err)
+	prep, err := e.prepare(ctx, req)
+	if err != nil {
+		e.logger.Error(i18n.T("executor.log.script_check_failed"), "path", pathLabel, "err", err)
 		return Result{}, err
 	}
-	envMap := mergeEnv(req.BaseEnv, req.ExtraEnv)
-	payload := &lifecycle.Context{
-		CommandPath: req.CommandPath,
-		Command:     req.Command,
-		Args:        req.ExtraArgs,
-		Env:         envMap,
+	if req.DryRun {
+		e.logger.Debug(i18n.T("executor.log.dry_run"), "path", pathLabel, "command", prep.masker.String(req.Command), "args", prep.masker.Args(req.ExtraArgs))
+		WritePlan(os.Stdout, e.buildPlan(req, prep))
+		return Result{ExitCode: 0}, nil
 	}
-	setLegacyNames(payload, req.CommandPath)
-	if err := e.plugins.Emit(ctx, lifecycle.EventBeforeExecute, payload); err != nil {
-		e.logger.Printf("执行前置钩子失败 path=%s err=%v", pathLabel, err)
+	if err := e.verifyTrust(prep.script); err != nil {
+		e.logger.Error(i18n.T("executor.log.script_check_failed"), "path", pathLabel, "err", err)
 		return Result{}, err
 	}
-	if req.DryRun {
-		e.logger.Printf("DryRun path=%s command=%s args=%v", pathLabel, req.Command, req.ExtraArgs)
-		ui.KeyValue(os.Stdout, "命令", req.Command)
-		if len(req.ExtraArgs) > 0 {
-			ui.KeyValue(os.Stdout, "参数", strings.Join(req.ExtraArgs, " "))
-		}
-		if len(req.BaseEnv) > 0 || len(req.ExtraEnv) > 0 {
-			fmt.Fprintln(os.Stdout, ui.Gray("  环境变量:"))
-			for k, v := range req.BaseEnv {
-				fmt.Fprintf(os.Stdout, "    %s=%s\n", ui.Yellow(k), ui.Gray(v))
-			}
-			for k, v := range req.ExtraEnv {
-				fmt.Fprintf(os.Stdout, "    %s=%s\n", ui.Yellow(k), ui.Gray(v))
-			}
-		}
-		return Result{ExitCode: 0}, nil
+	// 密钥只注入子进程环境，payload、日志与 dry-run 中仅保留 secret: 引用
+	secretEnv, err := e.resolveSecrets(req)
+	if err != nil {
+		e.logger.Error(i18n.T("executor.log.secret_failed"), "path", pathLabel, "err", err)
+		return Result{}, err
+	}
+	envMap := prep.envMap
+	masker := prep.masker.WithValues(mapValues(secretEnv)...)
+	payload := prep.payload
+	if err := e.plugins.Emit(ctx, lifecycle.EventBeforeExecute, payload); err != nil {
+		e.logger.Error(i18n.T("executor.log.before_hook_failed"), "path", pathLabel, "err", err)
+		return Result{}, err
 	}
+	e.logger.Debug(i18n.T("executor.log.start"), "path", pathLabel, "command", masker.String(req.Command), "args", masker.Args(req.ExtraArgs), "workdir", req.WorkingDir)
 	payload.StartAt = time.Now()
 	result := Result{}
 
-	combinedCommand := buildCommand(req.Command, req.ExtraArgs)
-	shell, shellArgs := buildShell(combinedCommand)
+	var scriptPath string
+	if req.Inline {
+		path, cleanup, err := writeInlineScript(req, req.Command)
+		if err != nil {
+			e.logger.Error(i18n.T("executor.log.failed"), "path", pathLabel, "err", err)
+			return Result{}, err
+		}
+		defer cleanup()
+		scriptPath = path
+	}
+	shell, shellArgs := buildShell(req, req.Command, scriptPath)
 
 	cmd := exec.CommandContext(ctx, shell, shellArgs...)
-	cmd.Env = envMapToList(envMap)
+	cmd.Env = envMapToList(overlayEnv(envMap, secretEnv))
 
 	// 捕获命令输出以便复制到剪贴板
 	var stdoutBuf, stderrBuf bytes.Buffer
-	cmd.Stdout = &stdoutBuf
-	cmd.Stderr = &stderrBuf
-	cmd.Stdin = os.Stdin
+	var live *maskingWriter
+	if req.Output != nil {
+		live = newMaskingWriter(req.Output, masker)
+		cmd.Stdout = live
+		cmd.Stderr = live
+		// 中断时 shell 派生的子进程可能仍持有输出管道，超过等待时间后不再等待其关闭
+		cmd.WaitDelay = liveOutputWaitDelay
+	} else {
+		cmd.Stdout = &stdoutBuf
+		cmd.Stderr = &stderrBuf
+		cmd.Stdin = os.Stdin
+	}
 	if req.WorkingDir != "" {
 		cmd.Dir = req.WorkingDir
 	}
 
-	err := cmd.Run()
+	err = cmd.Run()
 	payload.EndAt = time.Now()
 	result.Duration = payload.EndAt.Sub(payload.StartAt)
 
-	// 处理输出
+	// 处理输出，展示前遮蔽敏感值
 	stdoutStr := stdoutBuf.String()
-	stderrStr := stderrBuf.String()
+	stderrStr := masker.String(stderrBuf.String())
 
-	// 如果是环境变量命令，只显示剪贴板提示，不重复输出
-	if err == nil && isEnvCommand(req.CommandPath) {
+	if live != nil {
+		// 实时输出时只需写出末尾未换行的内容
+		live.Flush()
+	} else if err == nil && isEnvCommand(req.CommandPath) {
+		// 如果是环境变量命令，只显示剪贴板提示，不重复输出
 		clipboardContent := extractExportCommands(stdoutStr)
 		if clipboardContent != "" {
 			// 复制到剪贴板
 			if clipErr := clipboard.WriteAll(clipboardContent); clipErr == nil {
 				// 简洁的提示
 				fmt.Fprintln(os.Stdout, "")
-				fmt.Fprintln(os.Stdout, ui.Green("✓ 已复制到剪贴板，请粘贴执行 (Ctrl+Shift+V):"))
-				fmt.Fprintln(os.Stdout, ui.Gray(clipboardContent))
+				fmt.Fprintln(os.Stdout, ui.Green(ui.Icons().Done+" "+i18n.T("executor.clipboard_copied")))
+				fmt.Fprintln(os.Stdout, ui.Gray(masker.String(clipboardContent)))
 			} else {
 				// 复制失败，显示命令让用户手动复制
 				fmt.Fprintln(os.Stdout, "")
-				fmt.Fprintln(os.Stdout, ui.Yellow("请手动复制以下命令："))
-				fmt.Fprintln(os.Stdout, clipboardContent)
+				fmt.Fprintln(os.Stdout, ui.Yellow(i18n.T("executor.clipboard_manual")))
+				masked := masker.String(clipboardContent)
+				fmt.Fprintln(os.Stdout, masked)
+				if masked != clipboardContent {
+					fmt.Fprintln(os.Stdout, ui.Gray(i18n.T("executor.clipboard_masked")))
+				}
 			}
 		}
 	} else {
 		// 其他命令正常输出
 		if stdoutStr != "" {
-			fmt.Print(stdoutStr)
+			fmt.Print(masker.String(stdoutStr))
 		}
 	}
 
@@ -167,27 +247,32 @@ func (e *Executor) Execute(ctx context.Context, req ScriptRequest) (Result, erro
 		if errors.Is(err, context.Canceled) {
 			exitCode := -1
 			payload.Err = err
+			payload.ExitCode = exitCode
 			_ = e.plugins.Emit(ctx, lifecycle.EventError, payload) // 忽略错误,因为主流程已被取消
-			e.logger.Printf("命令被取消 path=%s err=%v", pathLabel, err)
+			e.logger.Warn(i18n.T("executor.log.cancelled"), "path", pathLabel, "err", err)
 			return Result{ExitCode: exitCode, Duration: result.Duration}, err
 		}
 		if errors.As(err, &exitErr) {
 			payload.Err = err
 			result.ExitCode = exitErr.ExitCode()
+			payload.ExitCode = result.ExitCode
 			_ = e.plugins.Emit(ctx, lifecycle.EventError, payload)
-			e.logger.Printf("命令执行失败 path=%s exit=%d err=%v", pathLabel, result.ExitCode, err)
+			e.logger.Error(i18n.T("executor.log.exit"), "path", pathLabel, "exit", result.ExitCode, "err", err)
 			return result, err
 		}
 		payload.Err = err
+		payload.ExitCode = -1
+		result.ExitCode = -1
 		_ = e.plugins.Emit(ctx, lifecycle.EventError, payload)
-		e.logger.Printf("命令执行失败 path=%s err=%v", pathLabel, err)
+		e.logger.Error(i18n.T("executor.log.command_failed"), "path", pathLabel, "err", err)
 		return result, err
 	}
 	result.ExitCode = 0
 	if err := e.plugins.Emit(ctx, lifecycle.EventAfterExecute, payload); err != nil {
-		e.logger.Printf("执行后置钩子失败 path=%s err=%v", pathLabel, err)
+		e.logger.Error(i18n.T("executor.log.after_hook_failed"), "path", pathLabel, "err", err)
 		return result, err
 	}
+	e.logger.Debug(i18n.T("executor.log.finished"), "path", pathLabel, "duration", result.Duration)
 	return result, nil
 }
 
@@ -208,6 +293,66 @@ func mergeEnv(base map[string]string, override map[string]string) map[string]str
 	return envMap
 }
 
+func mapValues(m map[string]string) []string {
+	values := make([]string, 0, len(m))
+	for _, v := range m {
+		values = append(values, v)
+	}
+	return values
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func overlayEnv(base map[string]string, overlay map[string]string) map[string]string {
+	if len(overlay) == 0 {
+		return base
+	}
+	merged := make(map[string]string, len(base)+len(overlay))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range overlay {
+		merged[k] = v
+	}
+	return merged
+}
+
+// resolveSecrets 解析请求 env 中的 secret: 引用，返回需要注入的真实值
+func (e *Executor) resolveSecrets(req ScriptRequest) (map[string]string, error) {
+	refs := map[string]string{}
+	for _, env := range []map[string]string{req.BaseEnv, req.ExtraEnv} {
+		for key, value := range env {
+			if name, ok := config.ParseSecretRef(value); ok {
+				refs[key] = name
+			} else {
+				delete(refs, key)
+			}
+		}
+	}
+	if len(refs) == 0 {
+		return nil, nil
+	}
+	if e.secrets == nil {
+		return nil, i18n.Errorf("executor.vault_disabled")
+	}
+	resolved := make(map[string]string, len(refs))
+	for key, name := range refs {
+		value, err := e.secrets.Resolve(name)
+		if err != nil {
+			return nil, i18n.Errorf("executor.env_resolve_failed", key, err)
+		}
+		resolved[key] = value
+	}
+	return resolved, nil
+}
+
 func setLegacyNames(payload *lifecycle.Context, path []string) {
 	if len(path) == 0 {
 		payload.GroupName = ""
@@ -234,38 +379,90 @@ func buildCommand(base string, extra []string) string {
 	return base + " " + shellquote.Join(extra...)
 }
 
-func buildShell(command string) (string, []string) {
-	if runtime.GOOS == "windows" {
-		return "cmd.exe", []string{"/C", command}
+// preparedRequest 为执行前已校验并遮蔽的请求上下文，执行与 dry-run 共用
+type preparedRequest struct {
+	// script 为命令指向的脚本路径，非脚本命令时为空
+	script  string
+	envMap  map[string]string
+	masker  *redact.Redactor
+	payload *lifecycle.Context
+}
+
+// prepare 校验脚本并合并环境变量，构造遮蔽后的插件载荷；不会解锁密钥库或登记脚本
+func (e *Executor) prepare(ctx context.Context, req ScriptRequest) (preparedRequest, error) {
+	script, err := e.validateScriptCommand(req)
+	if err != nil {
+		return preparedRequest{}, err
+	}
+	envMap := mergeEnv(req.BaseEnv, req.ExtraEnv)
+	if traceparent := tracing.Traceparent(ctx); traceparent != "" {
+		envMap[tracing.TraceparentEnv] = traceparent
+	}
+	masker := e.redactor.WithEnv(envMap)
+	// 插件（审计、通知等）只接收遮蔽后的命令、参数与环境变量
+	payload := &lifecycle.Context{
+		CommandPath: req.CommandPath,
+		Command:     masker.String(req.Command),
+		Args:        masker.Args(req.ExtraArgs),
+		Env:         masker.Env(envMap),
+		ConfigPath:  req.ConfigPath,
+		Environment: req.Environment,
+		Source:      req.Source,
 	}
-	return "/bin/sh", []string{"-c", command}
+	setLegacyNames(payload, req.CommandPath)
+	return preparedRequest{script: script, envMap: envMap, masker: masker, payload: payload}, nil
 }
 
-func (e *Executor) validateScriptCommand(req ScriptRequest) error {
+// validateScriptCommand 解析命令首个 token，对脚本仓库内的脚本校验可执行性，返回脚本路径
+func (e *Executor) validateScriptCommand(req ScriptRequest) (string, error) {
+	if req.Inline {
+		// 内联脚本内容来自配置文件，不指向脚本仓库中的文件
+		return "", nil
+	}
 	tokens, err := shellquote.Split(req.Command)
 	if err != nil {
-		return fmt.Errorf("解析命令 %q 失败: %w", req.Command, err)
+		return "", i18n.Errorf("scripts.command_parse_failed", req.Command, err)
 	}
 	if len(tokens) == 0 {
-		return fmt.Errorf("命令 %q 解析后为空", req.Command)
+		return "", i18n.Errorf("executor.command_parsed_empty", req.Command)
 	}
 	token := os.ExpandEnv(tokens[0])
 	if token == "" {
-		return nil
+		return "", nil
 	}
 	scriptPath, relevant, err := scripts.ResolveCommandTarget(token, req.WorkingDir)
 	if err != nil || !relevant {
-		return err
+		return "", err
 	}
 	root, err := e.resolveScriptsRoot()
 	if err != nil {
-		return err
+		return "", err
 	}
 	scriptPath = filepath.Clean(scriptPath)
-	if !scripts.IsUnderRoot(scriptPath, root) {
+	if scripts.IsUnderRoot(scriptPath, root) {
+		if err := scripts.VerifyExecutable(scriptPath); err != nil {
+			return "", err
+		}
+	}
+	return scriptPath, nil
+}
+
+// verifyTrust 按信任策略校验脚本哈希，首次执行的脚本自动登记
+func (e *Executor) verifyTrust(scriptPath string) error {
+	if scriptPath == "" {
 		return nil
 	}
-	return scripts.VerifyExecutable(scriptPath)
+	status, err := e.trust.Verify(scriptPath)
+	if err != nil {
+		return err
+	}
+	switch status {
+	case scripts.TrustStatusUntrusted:
+		e.logger.Info(i18n.T("executor.log.script_registered"), "path", scriptPath)
+	case scripts.TrustStatusChanged:
+		ui.Warning(os.Stderr, i18n.T("executor.script_changed"), scriptPath, ui.Highlight("alpen script trust"))
+	}
+	return nil
 }
 
 func (e *Executor) resolveScriptsRoot() (string, error) {
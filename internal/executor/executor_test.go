@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/alpen/alpen-cli/internal/config"
 	"github.com/alpen/alpen-cli/internal/lifecycle"
 	"github.com/alpen/alpen-cli/internal/plugins"
 )
@@ -237,3 +238,153 @@ func TestExecutorSuccessfullyRunsCommand(t *testing.T) {
 		t.Fatalf("expected positive duration")
 	}
 }
+
+type mapSecretResolver map[string]string
+
+func (m mapSecretResolver) Resolve(name string) (string, error) {
+	value, ok := m[name]
+	if !ok {
+		return "", fmt.Errorf("密钥 %s 不存在", name)
+	}
+	return value, nil
+}
+
+func TestExecutorInjectsSecretsWithoutExposingPayload(t *testing.T) {
+	registry := plugins.NewRegistry()
+	var seen string
+	if err := registry.Register(&testPlugin{
+		name: "capture",
+		handler: func(_ context.Context, event lifecycle.Event, payload *lifecycle.Context) error {
+			if event == lifecycle.EventBeforeExecute {
+				seen = payload.Env["API_TOKEN"]
+			}
+			return nil
+		},
+	}); err != nil {
+		t.Fatalf("注册插件失败: %v", err)
+	}
+
+	output := filepath.Join(t.TempDir(), "token.txt")
+	exec := NewExecutor(registry, nil)
+	exec.SetSecretResolver(mapSecretResolver{"github/token": "s3cr3t"})
+	_, err := exec.Execute(context.Background(), ScriptRequest{
+		CommandPath: []string{"secret"},
+		Command:     fmt.Sprintf("printf %%s \"$API_TOKEN\" > '%s'", output),
+		BaseEnv:     map[string]string{"API_TOKEN": "secret:github/token"},
+	})
+	if err != nil {
+		t.Fatalf("expected success, got %v", err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("read output failed: %v", err)
+	}
+	if string(data) != "s3cr3t" {
+		t.Fatalf("secret not injected, got %q", data)
+	}
+	if seen != "secret:github/token" {
+		t.Fatalf("payload should keep the reference only, got %q", seen)
+	}
+
+	_, err = exec.Execute(context.Background(), ScriptRequest{
+		CommandPath: []string{"secret"},
+		Command:     "true",
+		BaseEnv:     map[string]string{"API_TOKEN": "secret:missing"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "API_TOKEN") {
+		t.Fatalf("expected missing secret error, got %v", err)
+	}
+}
+
+func TestExecutorRedactsPluginPayload(t *testing.T) {
+	registry := plugins.NewRegistry()
+	var captured *lifecycle.Context
+	if err := registry.Register(&testPlugin{
+		name: "capture",
+		handler: func(_ context.Context, event lifecycle.Event, payload *lifecycle.Context) error {
+			if event == lifecycle.EventAfterExecute {
+				captured = payload
+			}
+			return nil
+		},
+	}); err != nil {
+		t.Fatalf("注册插件失败: %v", err)
+	}
+
+	exec := NewExecutor(registry, nil)
+	_, err := exec.Execute(context.Background(), ScriptRequest{
+		CommandPath: []string{"deploy"},
+		Command:     "true tok-abcdef",
+		ExtraArgs:   []string{"--token", "tok-abcdef", "--verbose"},
+		BaseEnv:     map[string]string{"DEPLOY_TOKEN": "tok-abcdef", "REGION": "eu"},
+	})
+	if err != nil {
+		t.Fatalf("expected success, got %v", err)
+	}
+	if captured == nil {
+		t.Fatalf("plugin did not receive payload")
+	}
+	if captured.Env["DEPLOY_TOKEN"] != "***" || captured.Env["REGION"] != "eu" {
+		t.Fatalf("unexpected payload env: DEPLOY_TOKEN=%q REGION=%q", captured.Env["DEPLOY_TOKEN"], captured.Env["REGION"])
+	}
+	if strings.Contains(captured.Command, "tok-abcdef") || strings.Contains(strings.Join(captured.Args, " "), "tok-abcdef") {
+		t.Fatalf("payload leaks token: command=%q args=%v", captured.Command, captured.Args)
+	}
+}
+
+func TestExecutorPlanDoesNotExecute(t *testing.T) {
+	registry := plugins.NewRegistry()
+	fired := false
+	if err := registry.Register(&testPlugin{
+		name: "capture",
+		handler: func(context.Context, lifecycle.Event, *lifecycle.Context) error {
+			fired = true
+			return nil
+		},
+	}); err != nil {
+		t.Fatalf("注册插件失败: %v", err)
+	}
+	sentinel := filepath.Join(t.TempDir(), "ran")
+	exec := NewExecutor(registry, nil)
+	exec.SetSecretResolver(mapSecretResolver{})
+	plan, err := exec.Plan(context.Background(), ScriptRequest{
+		CommandPath: []string{"deploy"},
+		Command:     "touch " + sentinel,
+		ExtraArgs:   []string{"--token", "tok-abcdef", "a b"},
+		BaseEnv:     map[string]string{"API_TOKEN": "secret:github/token", "REGION": "eu"},
+		WorkingDir:  "/srv",
+		FieldOrigins: map[string]config.SourceInfo{
+			config.OriginCommand:        {File: "demo.yaml", Line: 3},
+			config.OriginEnv + "REGION": {File: "demo.yaml", Line: 6},
+		},
+	})
+	if err != nil {
+		t.Fatalf("plan failed: %v", err)
+	}
+	if fired {
+		t.Fatalf("plan should not emit plugin events")
+	}
+	if _, err := os.Stat(sentinel); !os.IsNotExist(err) {
+		t.Fatalf("plan should not run the command")
+	}
+	if plan.Command != "touch "+sentinel+" --token *** 'a b'" {
+		t.Fatalf("unexpected command: %s", plan.Command)
+	}
+	if plan.Origins[config.OriginCommand] != "demo.yaml:3" || plan.WorkDir != "/srv" {
+		t.Fatalf("unexpected origin/workdir: %v %s", plan.Origins, plan.WorkDir)
+	}
+	env := map[string]string{}
+	for _, change := range plan.Env {
+		env[change.Key] = change.Value
+	}
+	if env["API_TOKEN"] != "secret:github/token" || env["REGION"] != "eu" {
+		t.Fatalf("unexpected env diff: %+v", plan.Env)
+	}
+	if len(plan.Secrets) != 1 || plan.Secrets[0] != "API_TOKEN" {
+		t.Fatalf("unexpected secrets: %v", plan.Secrets)
+	}
+	steps := strings.Join(plan.Steps, "\n")
+	if !strings.Contains(steps, "before_execute 插件: capture") || !strings.Contains(steps, "/srv") {
+		t.Fatalf("unexpected steps:\n%s", steps)
+	}
+}
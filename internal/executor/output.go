@@ -0,0 +1,53 @@
+package executor
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/alpen/alpen-cli/internal/redact"
+)
+
+// liveOutputWaitDelay 为实时输出模式下命令结束或被中断后等待输出管道关闭的最长时间
+const liveOutputWaitDelay = time.Second
+
+// maskingWriter 按行遮蔽敏感值后写入目标，避免敏感值被拆分在两次写入之间而漏掉遮蔽
+type maskingWriter struct {
+	mu      sync.Mutex
+	out     io.Writer
+	masker  *redact.Redactor
+	pending []byte
+}
+
+func newMaskingWriter(out io.Writer, masker *redact.Redactor) *maskingWriter {
+	return &maskingWriter{out: out, masker: masker}
+}
+
+// Write 写出已完整的行，未换行的内容暂存到下一次写入或 Flush
+func (w *maskingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.pending = append(w.pending, p...)
+	index := bytes.LastIndexByte(w.pending, '\n')
+	if index < 0 {
+		return len(p), nil
+	}
+	lines := string(w.pending[:index+1])
+	w.pending = append(w.pending[:0], w.pending[index+1:]...)
+	if _, err := io.WriteString(w.out, w.masker.String(lines)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Flush 写出暂存的未换行内容
+func (w *maskingWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.pending) == 0 {
+		return
+	}
+	_, _ = io.WriteString(w.out, w.masker.String(string(w.pending)))
+	w.pending = nil
+}
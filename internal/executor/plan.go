@@ -0,0 +1,205 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/kballard/go-shellquote"
+
+	"github.com/alpen/alpen-cli/internal/config"
+	"github.com/alpen/alpen-cli/internal/i18n"
+	"github.com/alpen/alpen-cli/internal/lifecycle"
+	"github.com/alpen/alpen-cli/internal/ui"
+)
+
+// Plan 描述 --dry-run 时展示的执行计划，其中的命令、参数与环境变量均已遮蔽
+type Plan struct {
+	CommandPath []string
+	// Command 为模板渲染并拼接转义后的额外参数的完整命令行
+	Command string
+	// Inline 表示 Command 为内联脚本内容，执行时写入临时文件
+	Inline bool
+	// Args 为转义后的额外参数，内联脚本的参数单独展示
+	Args    string
+	Shell   string
+	WorkDir string
+	// Script 为命令指向的脚本路径，TrustStatus 为其信任状态（未启用校验时为空）
+	Script      string
+	TrustStatus string
+	Env         []EnvChange
+	Secrets     []string
+	Steps       []string
+	// Origins 为各字段的定义位置，键与 config.FieldOrigins 相同
+	Origins map[string]string
+	Source  string
+}
+
+// EnvChange 描述相对当前进程环境新增或修改的变量
+type EnvChange struct {
+	Key      string
+	Value    string
+	Previous string
+	Added    bool
+}
+
+// Plan 校验请求并生成执行计划，不会运行命令、派发插件事件、解锁密钥库或登记脚本
+func (e *Executor) Plan(ctx context.Context, req ScriptRequest) (Plan, error) {
+	if strings.TrimSpace(req.Command) == "" {
+		return Plan{}, i18n.Errorf("executor.command_empty")
+	}
+	prep, err := e.prepare(ctx, req)
+	if err != nil {
+		return Plan{}, err
+	}
+	return e.buildPlan(req, prep), nil
+}
+
+func (e *Executor) buildPlan(req ScriptRequest, prep preparedRequest) Plan {
+	masker := prep.masker
+	command := masker.String(req.Command)
+	var args string
+	if len(req.ExtraArgs) > 0 {
+		args = joinMaskedArgs(req.ExtraArgs, masker.Args(req.ExtraArgs))
+	}
+	if args != "" && !req.Inline {
+		command += " " + args
+	}
+	workDir := req.WorkingDir
+	if workDir == "" {
+		if cwd, err := os.Getwd(); err == nil {
+			workDir = cwd
+		}
+	}
+	plan := Plan{
+		CommandPath: req.CommandPath,
+		Command:     command,
+		Inline:      req.Inline,
+		Args:        args,
+		Shell:       describeShell(req),
+		WorkDir:     workDir,
+		Script:      prep.script,
+		Origins:     map[string]string{},
+		Source:      req.Source,
+	}
+	for key, origin := range req.FieldOrigins {
+		plan.Origins[key] = origin.String()
+	}
+
+	current := mergeEnv(nil, nil)
+	masked := masker.Env(prep.envMap)
+	for _, key := range sortedKeys(prep.envMap) {
+		previous, exists := current[key]
+		if exists && previous == prep.envMap[key] {
+			continue
+		}
+		if _, ok := config.ParseSecretRef(prep.envMap[key]); ok {
+			plan.Secrets = append(plan.Secrets, key)
+		}
+		plan.Env = append(plan.Env, EnvChange{
+			Key:      key,
+			Value:    masked[key],
+			Previous: masker.Env(map[string]string{key: previous})[key],
+			Added:    !exists,
+		})
+	}
+
+	if prep.script != "" {
+		if status, err := e.trust.Status(prep.script); err != nil {
+			plan.TrustStatus = i18n.T("executor.plan.trust_read_failed", err)
+		} else if status != "" {
+			plan.TrustStatus = status.Label()
+		}
+		step := i18n.T("executor.plan.verify_script", prep.script)
+		if plan.TrustStatus != "" {
+			step += i18n.T("executor.plan.paren", plan.TrustStatus)
+		}
+		plan.Steps = append(plan.Steps, step)
+	}
+	if len(plan.Secrets) > 0 {
+		plan.Steps = append(plan.Steps, i18n.T("executor.plan.unlock", strings.Join(plan.Secrets, ", ")))
+	}
+	if names := e.plugins.Subscribers(lifecycle.EventBeforeExecute, prep.payload); len(names) > 0 {
+		plan.Steps = append(plan.Steps, i18n.T("executor.plan.before", lifecycle.EventBeforeExecute, strings.Join(names, ", ")))
+	}
+	if req.Inline {
+		plan.Steps = append(plan.Steps, i18n.T("executor.plan.inline", workDir, plan.Shell))
+	} else {
+		plan.Steps = append(plan.Steps, i18n.T("executor.plan.run", workDir, plan.Shell))
+	}
+	if names := e.plugins.Subscribers(lifecycle.EventAfterExecute, prep.payload); len(names) > 0 {
+		plan.Steps = append(plan.Steps, i18n.T("executor.plan.after", lifecycle.EventAfterExecute, strings.Join(names, ", ")))
+	}
+	if names := e.plugins.Subscribers(lifecycle.EventError, prep.payload); len(names) > 0 {
+		plan.Steps = append(plan.Steps, i18n.T("executor.plan.error", lifecycle.EventError, strings.Join(names, ", ")))
+	}
+	return plan
+}
+
+// WritePlan 输出执行计划
+func WritePlan(w io.Writer, plan Plan) {
+	ui.Title(w, i18n.T("executor.plan.title", strings.Join(plan.CommandPath, " ")))
+	if plan.Inline {
+		ui.KeyValue(w, i18n.T("executor.plan.inline_script"), originSuffix(plan.Origins[config.OriginCommand]))
+		for _, line := range strings.Split(strings.TrimRight(plan.Command, "\n"), "\n") {
+			fmt.Fprintf(w, "    %s\n", ui.Cyan(line))
+		}
+		if plan.Args != "" {
+			ui.KeyValue(w, i18n.T("executor.plan.args"), plan.Args)
+		}
+	} else {
+		ui.KeyValue(w, i18n.T("executor.plan.command"), plan.Command+originSuffix(plan.Origins[config.OriginCommand]))
+	}
+	ui.KeyValue(w, "Shell", plan.Shell)
+	ui.KeyValue(w, i18n.T("executor.plan.workdir"), plan.WorkDir+originSuffix(plan.Origins[config.OriginWorkDir]))
+	if plan.Script != "" {
+		script := plan.Script
+		if plan.TrustStatus != "" {
+			script += i18n.T("executor.plan.paren", plan.TrustStatus)
+		}
+		ui.KeyValue(w, i18n.T("executor.plan.script"), script)
+	}
+	if plan.Source != "" {
+		ui.KeyValue(w, i18n.T("executor.plan.source"), plan.Source)
+	}
+
+	fmt.Fprintln(w, ui.Gray(i18n.T("executor.plan.env")))
+	if len(plan.Env) == 0 {
+		fmt.Fprintln(w, ui.Gray(i18n.T("executor.plan.none")))
+	}
+	for _, change := range plan.Env {
+		origin := originSuffix(plan.Origins[config.OriginEnv+change.Key])
+		if change.Added {
+			fmt.Fprintf(w, "    %s %s=%s%s\n", ui.Green("+"), ui.Yellow(change.Key), change.Value, origin)
+			continue
+		}
+		fmt.Fprintf(w, "    %s %s=%s %s%s\n", ui.Yellow("~"), ui.Yellow(change.Key), change.Value, ui.Gray(i18n.T("executor.plan.previous")+change.Previous+")"), origin)
+	}
+
+	fmt.Fprintln(w, ui.Gray(i18n.T("executor.plan.steps")))
+	for i, step := range plan.Steps {
+		fmt.Fprintf(w, "    %d. %s\n", i+1, step)
+	}
+}
+
+// joinMaskedArgs 按 shell 规则转义参数，被遮蔽的参数保留 Mask 原文，便于阅读
+func joinMaskedArgs(args []string, masked []string) string {
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		if masked[i] != arg {
+			parts[i] = masked[i]
+			continue
+		}
+		parts[i] = shellquote.Join(arg)
+	}
+	return strings.Join(parts, " ")
+}
+
+func originSuffix(origin string) string {
+	if origin == "" {
+		return ""
+	}
+	return "  " + ui.Gray(ui.Icons().Origin+" "+origin)
+}
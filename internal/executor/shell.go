@@ -0,0 +1,117 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/alpen/alpen-cli/internal/i18n"
+)
+
+// inlineFlags 为解释器执行命令字符串的参数，执行内联脚本文件时去除
+var inlineFlags = []string{"-c", "-e", "--eval", "-Command"}
+
+// argEmbeddingShells 为将额外参数转义后拼接到命令字符串中的 shell，其余解释器按独立参数传递
+var argEmbeddingShells = []string{"sh", "bash", "zsh", "dash", "ksh", "cmd"}
+
+// interpreterFor 返回请求使用的解释器参数前缀，未配置时为平台默认 shell
+func interpreterFor(req ScriptRequest) []string {
+	if len(req.Interpreter) > 0 {
+		return req.Interpreter
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"cmd.exe", "/C"}
+	}
+	return []string{"/bin/sh", "-c"}
+}
+
+// scriptPrefix 返回执行内联脚本文件时的解释器参数前缀
+func scriptPrefix(interpreter []string) []string {
+	if n := len(interpreter); n > 1 && slices.Contains(inlineFlags, interpreter[n-1]) {
+		return interpreter[:n-1]
+	}
+	return interpreter
+}
+
+// programName 返回解释器的程序名（不含目录与 .exe 后缀）
+func programName(program string) string {
+	return strings.TrimSuffix(strings.ToLower(filepath.Base(program)), ".exe")
+}
+
+// buildShell 返回执行请求的程序与参数；内联脚本以 scriptPath 代替命令字符串
+func buildShell(req ScriptRequest, command string, scriptPath string) (string, []string) {
+	interpreter := interpreterFor(req)
+	if req.Inline {
+		prefix := scriptPrefix(interpreter)
+		args := append(append(append([]string(nil), prefix[1:]...), scriptPath), req.ExtraArgs...)
+		return prefix[0], args
+	}
+	args := append([]string(nil), interpreter[1:]...)
+	if slices.Contains(argEmbeddingShells, programName(interpreter[0])) {
+		return interpreter[0], append(args, buildCommand(command, req.ExtraArgs))
+	}
+	return interpreter[0], append(append(args, command), req.ExtraArgs...)
+}
+
+// describeShell 返回展示用的解释器，不含命令字符串与额外参数
+func describeShell(req ScriptRequest) string {
+	interpreter := interpreterFor(req)
+	if req.Inline {
+		interpreter = scriptPrefix(interpreter)
+	}
+	return strings.Join(interpreter, " ")
+}
+
+// scriptExtension 按解释器返回内联脚本临时文件的扩展名
+func scriptExtension(program string) string {
+	name := programName(program)
+	switch {
+	case strings.HasPrefix(name, "python"):
+		return ".py"
+	case name == "node":
+		return ".js"
+	case name == "pwsh" || name == "powershell":
+		return ".ps1"
+	case name == "cmd":
+		return ".bat"
+	default:
+		return ".sh"
+	}
+}
+
+// writeInlineScript 将内联脚本写入带 shebang 的临时文件，返回文件路径与清理函数
+func writeInlineScript(req ScriptRequest, body string) (string, func(), error) {
+	program := scriptPrefix(interpreterFor(req))[0]
+	ext := scriptExtension(program)
+	file, err := os.CreateTemp("", "alpen-inline-*"+ext)
+	if err != nil {
+		return "", nil, i18n.Errorf("executor.inline_temp_failed", err)
+	}
+	cleanup := func() { _ = os.Remove(file.Name()) }
+
+	var content strings.Builder
+	if ext != ".bat" {
+		if filepath.IsAbs(program) {
+			content.WriteString("#!" + program + "\n")
+		} else {
+			content.WriteString("#!/usr/bin/env " + program + "\n")
+		}
+	}
+	content.WriteString(body)
+	if _, err := file.WriteString(content.String()); err != nil {
+		file.Close()
+		cleanup()
+		return "", nil, i18n.Errorf("executor.inline_write_failed", err)
+	}
+	if err := file.Close(); err != nil {
+		cleanup()
+		return "", nil, i18n.Errorf("executor.inline_write_failed", err)
+	}
+	if err := os.Chmod(file.Name(), 0o700); err != nil {
+		cleanup()
+		return "", nil, i18n.Errorf("executor.inline_chmod_failed", err)
+	}
+	return file.Name(), cleanup, nil
+}
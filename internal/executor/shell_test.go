@@ -0,0 +1,66 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/alpen/alpen-cli/internal/plugins"
+)
+
+func TestBuildShellByInterpreter(t *testing.T) {
+	bash := []string{"bash", "-euo", "pipefail", "-c"}
+	program, args := buildShell(ScriptRequest{Interpreter: bash, ExtraArgs: []string{"a b"}}, "echo", "")
+	if program != "bash" || !reflect.DeepEqual(args, []string{"-euo", "pipefail", "-c", "echo 'a b'"}) {
+		t.Fatalf("expected shell args to be embedded in command, got %s %v", program, args)
+	}
+
+	python := []string{"python3", "-c"}
+	program, args = buildShell(ScriptRequest{Interpreter: python, ExtraArgs: []string{"a b"}}, "print(1)", "")
+	if program != "python3" || !reflect.DeepEqual(args, []string{"-c", "print(1)", "a b"}) {
+		t.Fatalf("expected interpreter args to be passed separately, got %s %v", program, args)
+	}
+
+	program, args = buildShell(ScriptRequest{Interpreter: bash, Inline: true, ExtraArgs: []string{"x"}}, "echo", "/tmp/s.sh")
+	if program != "bash" || !reflect.DeepEqual(args, []string{"-euo", "pipefail", "/tmp/s.sh", "x"}) {
+		t.Fatalf("expected inline script to replace -c, got %s %v", program, args)
+	}
+	if got := describeShell(ScriptRequest{Interpreter: python, Inline: true}); got != "python3" {
+		t.Fatalf("unexpected inline shell description: %s", got)
+	}
+	if got := scriptExtension("/usr/bin/python3"); got != ".py" {
+		t.Fatalf("unexpected extension: %s", got)
+	}
+}
+
+func TestExecutorRunsInlineScript(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("内联 shell 脚本测试仅在类 Unix 系统运行")
+	}
+	exec := NewExecutor(plugins.NewRegistry(), nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	output := filepath.Join(t.TempDir(), "out.txt")
+	_, err := exec.Execute(ctx, ScriptRequest{
+		CommandPath: []string{"inline"},
+		Command:     "first=$1\nhead -n 1 \"$0\" > \"$OUT\"\necho \"$first\" >> \"$OUT\"\n",
+		Inline:      true,
+		ExtraArgs:   []string{"hello world"},
+		ExtraEnv:    map[string]string{"OUT": output},
+	})
+	if err != nil {
+		t.Fatalf("expected inline script to succeed, got %v", err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	if string(data) != "#!/bin/sh\nhello world\n" {
+		t.Fatalf("unexpected output: %q", string(data))
+	}
+}
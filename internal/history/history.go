@@ -0,0 +1,207 @@
+// Package history 记录命令的使用历史与置顶列表，用于按使用频率与最近程度（frecency）排序交互菜单
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/alpen/alpen-cli/internal/fsutil"
+	"github.com/alpen/alpen-cli/internal/i18n"
+)
+
+// DefaultFileName 为使用历史在状态目录下的文件名
+const DefaultFileName = "history.json"
+
+const (
+	lockSuffix = ".lock"
+	// recentVisits 为计算 frecency 保留的最近执行时间数
+	recentVisits = 10
+)
+
+// recencyWeights 按距今时长为每次执行加权，越近的执行权重越高
+var recencyWeights = []struct {
+	within time.Duration
+	weight float64
+}{
+	{4 * time.Hour, 100},
+	{24 * time.Hour, 80},
+	{7 * 24 * time.Hour, 60},
+	{30 * 24 * time.Hour, 40},
+	{90 * 24 * time.Hour, 20},
+}
+
+// olderWeight 为超过 90 天的执行的权重
+const olderWeight = 10
+
+// Entry 为单条命令路径的使用记录，LastArgs 与 LastParams 用于以上次的参数再次执行；
+// Redacted 表示上次的参数中含有敏感值，记录的是遮蔽后的参数，不能按原样再次执行
+type Entry struct {
+	Runs       int64             `json:"runs"`
+	LastRun    time.Time         `json:"last_run"`
+	Visits     []time.Time       `json:"visits"`
+	LastArgs   []string          `json:"last_args,omitempty"`
+	LastParams map[string]string `json:"last_params,omitempty"`
+	Redacted   bool              `json:"redacted,omitempty"`
+}
+
+// Store 为持久化在状态目录中的使用历史，Pins 按置顶的先后顺序排列
+type Store struct {
+	Commands map[string]*Entry `json:"commands"`
+	Pins     []string          `json:"pins"`
+}
+
+// Record 记录一次执行及其参数
+func (s *Store) Record(command string, args []string, params map[string]string, at time.Time) {
+	if s.Commands == nil {
+		s.Commands = map[string]*Entry{}
+	}
+	entry, ok := s.Commands[command]
+	if !ok {
+		entry = &Entry{}
+		s.Commands[command] = entry
+	}
+	entry.Runs++
+	entry.LastRun = at
+	entry.Visits = append(entry.Visits, at)
+	if len(entry.Visits) > recentVisits {
+		entry.Visits = entry.Visits[len(entry.Visits)-recentVisits:]
+	}
+	entry.LastArgs = append([]string(nil), args...)
+	entry.Redacted = false
+	entry.LastParams = nil
+	if len(params) > 0 {
+		entry.LastParams = make(map[string]string, len(params))
+		for key, value := range params {
+			entry.LastParams[key] = value
+		}
+	}
+}
+
+// RecordRedacted 与 Record 相同，args 与 params 为遮蔽后的参数，记录后不提供按上次参数直接执行
+func (s *Store) RecordRedacted(command string, args []string, params map[string]string, at time.Time) {
+	s.Record(command, args, params, at)
+	s.Commands[command].Redacted = true
+}
+
+// Score 返回命令的 frecency 分数：总执行次数乘以最近若干次执行的平均时间权重，没有记录时为 0
+func (s *Store) Score(command string, now time.Time) float64 {
+	if s == nil {
+		return 0
+	}
+	entry, ok := s.Commands[command]
+	if !ok || entry.Runs == 0 || len(entry.Visits) == 0 {
+		return 0
+	}
+	var total float64
+	for _, visit := range entry.Visits {
+		total += recencyWeight(now.Sub(visit))
+	}
+	return float64(entry.Runs) * total / float64(len(entry.Visits))
+}
+
+func recencyWeight(age time.Duration) float64 {
+	for _, bucket := range recencyWeights {
+		if age <= bucket.within {
+			return bucket.weight
+		}
+	}
+	return olderWeight
+}
+
+// Recent 按最近执行时间降序返回至多 limit 条命令路径
+func (s *Store) Recent(limit int) []string {
+	if s == nil {
+		return nil
+	}
+	names := make([]string, 0, len(s.Commands))
+	for name, entry := range s.Commands {
+		if entry.Runs > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := s.Commands[names[i]].LastRun, s.Commands[names[j]].LastRun
+		if !a.Equal(b) {
+			return a.After(b)
+		}
+		return names[i] < names[j]
+	})
+	if limit > 0 && len(names) > limit {
+		names = names[:limit]
+	}
+	return names
+}
+
+// Pinned 判断命令是否已置顶
+func (s *Store) Pinned(command string) bool {
+	return s != nil && slices.Contains(s.Pins, command)
+}
+
+// Pin 置顶命令，已置顶时返回 false
+func (s *Store) Pin(command string) bool {
+	if s.Pinned(command) {
+		return false
+	}
+	s.Pins = append(s.Pins, command)
+	return true
+}
+
+// Unpin 取消置顶，未置顶时返回 false
+func (s *Store) Unpin(command string) bool {
+	index := slices.Index(s.Pins, command)
+	if index < 0 {
+		return false
+	}
+	s.Pins = slices.Delete(s.Pins, index, index+1)
+	return true
+}
+
+// Load 读取使用历史，文件不存在时返回空结果
+func Load(path string) (*Store, error) {
+	store := &Store{Commands: map[string]*Entry{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, store); err != nil {
+		return nil, i18n.Errorf("history.parse_failed", path, err)
+	}
+	if store.Commands == nil {
+		store.Commands = map[string]*Entry{}
+	}
+	return store, nil
+}
+
+// Save 原子写入使用历史
+func (s *Store) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(path, data, 0o600)
+}
+
+// Update 在文件锁内读取、修改并写回使用历史，避免并发执行的命令互相覆盖
+func Update(path string, fn func(*Store) error) error {
+	unlock, err := fsutil.AcquireLock(path + lockSuffix)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	store, err := Load(path)
+	if err != nil {
+		return err
+	}
+	if err := fn(store); err != nil {
+		return err
+	}
+	return store.Save(path)
+}
@@ -0,0 +1,80 @@
+package history
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestScoreFavorsFrequentAndRecentCommands(t *testing.T) {
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	store := &Store{}
+	for i := 0; i < 5; i++ {
+		store.Record("deploy", nil, nil, now.Add(-time.Duration(i)*time.Hour))
+	}
+	store.Record("build", nil, nil, now.Add(-time.Minute))
+	for i := 0; i < 5; i++ {
+		store.Record("cleanup", nil, nil, now.Add(-20*24*time.Hour))
+	}
+
+	deploy, build, cleanup := store.Score("deploy", now), store.Score("build", now), store.Score("cleanup", now)
+	if !(deploy > build && deploy > cleanup) {
+		t.Fatalf("frequent recent command should rank first: deploy=%v build=%v cleanup=%v", deploy, build, cleanup)
+	}
+	if !(cleanup > build) {
+		t.Fatalf("frequency should outweigh a single recent run: build=%v cleanup=%v", build, cleanup)
+	}
+	if got := store.Score("missing", now); got != 0 {
+		t.Fatalf("unknown command should score 0, got %v", got)
+	}
+	if got := store.Recent(2); !reflect.DeepEqual(got, []string{"build", "deploy"}) {
+		t.Fatalf("unexpected recent order: %v", got)
+	}
+}
+
+func TestRecordKeepsLastArgsAndBoundedVisits(t *testing.T) {
+	now := time.Now()
+	store := &Store{}
+	for i := 0; i < recentVisits+5; i++ {
+		store.Record("db migrate", []string{"--step", "1"}, map[string]string{"env": "dev"}, now)
+	}
+	store.Record("db migrate", []string{"--dry"}, nil, now)
+	entry := store.Commands["db migrate"]
+	if entry.Runs != recentVisits+6 || len(entry.Visits) != recentVisits {
+		t.Fatalf("unexpected entry: runs=%d visits=%d", entry.Runs, len(entry.Visits))
+	}
+	if !reflect.DeepEqual(entry.LastArgs, []string{"--dry"}) || entry.LastParams != nil {
+		t.Fatalf("last args should be replaced, got %v %v", entry.LastArgs, entry.LastParams)
+	}
+}
+
+func TestPinsPersistInOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", DefaultFileName)
+	err := Update(path, func(store *Store) error {
+		store.Pin("deploy")
+		store.Pin("ops status")
+		if store.Pin("deploy") {
+			t.Fatalf("pinning twice should report false")
+		}
+		store.Record("deploy", []string{"v1"}, nil, time.Now())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+
+	store, err := Load(path)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if !reflect.DeepEqual(store.Pins, []string{"deploy", "ops status"}) || !store.Pinned("ops status") {
+		t.Fatalf("unexpected pins: %v", store.Pins)
+	}
+	if !store.Unpin("deploy") || store.Unpin("deploy") {
+		t.Fatalf("unpin should succeed once")
+	}
+	if store.Commands["deploy"].LastArgs[0] != "v1" {
+		t.Fatalf("history should be persisted alongside pins")
+	}
+}
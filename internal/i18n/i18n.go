@@ -0,0 +1,147 @@
+// Package i18n 提供界面文案的消息目录，按 --lang、全局设置或 LC_ALL/LC_MESSAGES/LANG 选择语言
+package i18n
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultLocale 为未指定语言或系统语言不受支持时使用的语言，缺失的文案也回退到该语言
+const DefaultLocale = "zh-CN"
+
+//go:embed locales/*.yaml
+var localeFiles embed.FS
+
+var (
+	loadOnce sync.Once
+	catalogs map[string]map[string]string
+	current  atomic.Value
+)
+
+// load 解析内置的语言文件，语言文件随程序编译，格式错误属于构建问题
+func load() {
+	loadOnce.Do(func() {
+		catalogs = map[string]map[string]string{}
+		entries, err := localeFiles.ReadDir("locales")
+		if err != nil {
+			panic(fmt.Sprintf("读取内置语言文件失败: %v", err))
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			data, err := localeFiles.ReadFile(path.Join("locales", name))
+			if err != nil {
+				panic(fmt.Sprintf("读取内置语言文件 %s 失败: %v", name, err))
+			}
+			messages := map[string]string{}
+			if err := yaml.Unmarshal(data, &messages); err != nil {
+				panic(fmt.Sprintf("解析内置语言文件 %s 失败: %v", name, err))
+			}
+			catalogs[strings.TrimSuffix(name, path.Ext(name))] = messages
+		}
+	})
+}
+
+// Locales 返回内置的全部语言
+func Locales() []string {
+	load()
+	locales := make([]string, 0, len(catalogs))
+	for locale := range catalogs {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Normalize 将 zh_CN.UTF-8、en-US 等写法映射为内置语言，不受支持时返回 false
+func Normalize(value string) (string, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if i := strings.IndexAny(value, ".@"); i >= 0 {
+		value = value[:i]
+	}
+	language, _, _ := strings.Cut(strings.ReplaceAll(value, "_", "-"), "-")
+	switch language {
+	case "zh":
+		return "zh-CN", true
+	case "en":
+		return "en", true
+	}
+	return "", false
+}
+
+// Detect 按优先级选择语言：依次尝试 preferred（如 --lang 与全局设置）、LC_ALL、LC_MESSAGES 与 LANG，
+// 均未指定或不受支持时返回 DefaultLocale
+func Detect(preferred ...string) string {
+	candidates := slices.Concat(preferred, []string{os.Getenv("LC_ALL"), os.Getenv("LC_MESSAGES"), os.Getenv("LANG")})
+	for _, candidate := range candidates {
+		if locale, ok := Normalize(candidate); ok {
+			return locale
+		}
+	}
+	return DefaultLocale
+}
+
+// SetLocale 切换当前语言
+func SetLocale(value string) error {
+	locale, ok := Normalize(value)
+	if !ok {
+		return Errorf("i18n.unsupported", value, strings.Join(Locales(), ", "))
+	}
+	current.Store(locale)
+	return nil
+}
+
+// Locale 返回当前语言
+func Locale() string {
+	if locale, ok := current.Load().(string); ok {
+		return locale
+	}
+	return DefaultLocale
+}
+
+// lookup 返回当前语言的文案，缺失时回退到默认语言，仍缺失时返回键本身以便发现遗漏
+func lookup(key string) string {
+	load()
+	if message, ok := catalogs[Locale()][key]; ok {
+		return message
+	}
+	if message, ok := catalogs[DefaultLocale][key]; ok {
+		return message
+	}
+	return key
+}
+
+// T 返回 key 对应的文案；带参数时按 fmt 格式化，不带参数时原样返回，可继续作为格式串使用
+func T(key string, args ...any) string {
+	message := lookup(key)
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// Errorf 以 key 对应的文案为格式串创建错误，支持 %w
+func Errorf(key string, args ...any) error {
+	return fmt.Errorf(lookup(key), args...)
+}
+
+// NewError 创建用于 errors.Is 比较的哨兵错误，文案在输出时按当前语言翻译
+func NewError(key string) error {
+	return &messageError{key: key}
+}
+
+type messageError struct {
+	key string
+}
+
+func (e *messageError) Error() string {
+	return lookup(e.key)
+}
@@ -0,0 +1,121 @@
+package i18n
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// verbPattern 匹配 fmt 格式动词，用于比较不同语言的文案参数是否一致
+var verbPattern = regexp.MustCompile(`%(?:\[\d+\])?[-+# 0]*\d*(?:\.\d+)?[a-zA-Z%]`)
+
+// keyPattern 匹配源码中以字面量引用的文案键
+var keyPattern = regexp.MustCompile(`i18n\.(?:T|Errorf|NewError)\("([^"]+)"`)
+
+func verbs(message string) []string {
+	var result []string
+	for _, verb := range verbPattern.FindAllString(message, -1) {
+		if verb == "%%" {
+			continue
+		}
+		result = append(result, verb[len(verb)-1:])
+	}
+	sort.Strings(result)
+	return result
+}
+
+func TestCatalogsHaveSameKeys(t *testing.T) {
+	load()
+	base := catalogs[DefaultLocale]
+	if len(base) == 0 {
+		t.Fatalf("default catalog %s is empty", DefaultLocale)
+	}
+	for _, locale := range Locales() {
+		catalog := catalogs[locale]
+		for key, message := range base {
+			translated, ok := catalog[key]
+			if !ok {
+				t.Errorf("%s: missing key %s", locale, key)
+				continue
+			}
+			if !slices.Equal(verbs(message), verbs(translated)) {
+				t.Errorf("%s: key %s has verbs %v, want %v", locale, key, verbs(translated), verbs(message))
+			}
+		}
+		for key := range catalog {
+			if _, ok := base[key]; !ok {
+				t.Errorf("%s: key %s is missing in %s", locale, key, DefaultLocale)
+			}
+		}
+	}
+}
+
+func TestSourceKeysExistInEveryCatalog(t *testing.T) {
+	load()
+	root := filepath.Join("..", "..")
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || !strings.HasSuffix(path, ".go") {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, match := range keyPattern.FindAllStringSubmatch(string(data), -1) {
+			for _, locale := range Locales() {
+				if _, ok := catalogs[locale][match[1]]; !ok {
+					t.Errorf("%s: key %s is missing in %s", path, match[1], locale)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("walk source: %v", err)
+	}
+}
+
+func TestDetectPrefersExplicitThenEnvironment(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "en_US.UTF-8")
+	if got := Detect(); got != "en" {
+		t.Fatalf("LANG should select en, got %s", got)
+	}
+	if got := Detect("", "zh_CN"); got != "zh-CN" {
+		t.Fatalf("explicit setting should win, got %s", got)
+	}
+	t.Setenv("LC_ALL", "C.UTF-8")
+	t.Setenv("LANG", "fr_FR.UTF-8")
+	if got := Detect("de"); got != DefaultLocale {
+		t.Fatalf("unsupported locales should fall back to %s, got %s", DefaultLocale, got)
+	}
+}
+
+func TestTranslateWithLocale(t *testing.T) {
+	t.Cleanup(func() { _ = SetLocale(DefaultLocale) })
+	sentinel := NewError("tui.not_terminal")
+	zh := sentinel.Error()
+
+	if err := SetLocale("en-US"); err != nil {
+		t.Fatalf("set locale: %v", err)
+	}
+	if sentinel.Error() == zh || !errors.Is(sentinel, sentinel) {
+		t.Fatalf("sentinel should be translated lazily, got %q", sentinel.Error())
+	}
+	if got := T("ui.batch_label", 3); got != "3 commands" {
+		t.Fatalf("unexpected message: %q", got)
+	}
+	if got := T("missing.key"); got != "missing.key" {
+		t.Fatalf("missing keys should return the key, got %q", got)
+	}
+	if err := SetLocale("fr"); err == nil || Locale() != "en" {
+		t.Fatalf("unsupported locale should be rejected, got %v %s", err, Locale())
+	}
+}
@@ -24,8 +24,12 @@ type Context struct {
 	Command     string
 	Args        []string
 	Env         map[string]string
+	ConfigPath  string
+	Environment string
+	Source      string
 	StartAt     time.Time
 	EndAt       time.Time
+	ExitCode    int
 	Err         error
 }
 
@@ -0,0 +1,156 @@
+// Package logging 提供 alpen 自身的分级日志，输出到标准错误或日志文件，不与脚本的标准输出混在一起
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"github.com/alpen/alpen-cli/internal/i18n"
+)
+
+// 日志格式
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// DefaultLevel 为未配置日志级别时使用的级别，只输出警告与错误
+const DefaultLevel = slog.LevelWarn
+
+// Options 描述日志的级别、格式与输出位置
+type Options struct {
+	Level  slog.Level
+	Format string
+	// File 不为空时追加写入该文件，不再输出到标准错误
+	File string
+	// Quiet 为 true 时不向标准错误输出任何日志，日志文件不受影响
+	Quiet bool
+	// Stderr 为标准错误，为空时使用 os.Stderr
+	Stderr io.Writer
+}
+
+// consoleMuted 为 true 时丢弃输出到标准错误的日志，例如全屏界面占用终端期间
+var consoleMuted atomic.Bool
+
+// ParseLevel 解析 debug、info、warn、error，空值返回 DefaultLevel
+func ParseLevel(value string) (slog.Level, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return DefaultLevel, nil
+	}
+	switch strings.ToLower(value) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return DefaultLevel, i18n.Errorf("logging.level_invalid", value)
+}
+
+// ParseFormat 解析 text 或 json，空值返回 FormatText
+func ParseFormat(value string) (string, error) {
+	switch format := strings.ToLower(strings.TrimSpace(value)); format {
+	case "", FormatText:
+		return FormatText, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	}
+	return FormatText, i18n.Errorf("logging.format_invalid", value)
+}
+
+// New 按 opts 创建日志记录器，返回的 io.Closer 用于在退出前关闭日志文件；
+// 日志文件无法打开时回退到标准错误并返回错误
+func New(opts Options) (*slog.Logger, io.Closer, error) {
+	stderr := opts.Stderr
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+	var openErr error
+	if file := strings.TrimSpace(opts.File); file != "" {
+		handle, err := openFile(file)
+		if err == nil {
+			return slog.New(newHandler(handle, opts.Format, opts.Level, true)), handle, nil
+		}
+		openErr = i18n.Errorf("logging.file_failed", file, err)
+	}
+	if opts.Quiet {
+		return Discard(), nopCloser{}, openErr
+	}
+	writer := consoleWriter{out: stderr}
+	return slog.New(newHandler(writer, opts.Format, opts.Level, false)), nopCloser{}, openErr
+}
+
+// Stderr 返回按 DefaultLevel 以文本格式输出到标准错误的记录器，用于未注入日志记录器的组件
+func Stderr() *slog.Logger {
+	logger, _, _ := New(Options{Level: DefaultLevel})
+	return logger
+}
+
+// Discard 返回丢弃全部日志的记录器
+func Discard() *slog.Logger {
+	return slog.New(discardHandler{})
+}
+
+// MuteConsole 暂停输出到标准错误的日志，返回恢复函数
+func MuteConsole() func() {
+	previous := consoleMuted.Swap(true)
+	return func() {
+		consoleMuted.Store(previous)
+	}
+}
+
+// newHandler 创建文本或 JSON 格式的处理器；输出到终端时省略时间，日志文件保留时间
+func newHandler(w io.Writer, format string, level slog.Level, withTime bool) slog.Handler {
+	options := &slog.HandlerOptions{Level: level}
+	if !withTime {
+		options.ReplaceAttr = func(groups []string, attr slog.Attr) slog.Attr {
+			if len(groups) == 0 && attr.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return attr
+		}
+	}
+	if format == FormatJSON {
+		return slog.NewJSONHandler(w, options)
+	}
+	return slog.NewTextHandler(w, options)
+}
+
+func openFile(path string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+}
+
+// consoleWriter 在 MuteConsole 期间丢弃写入的日志
+type consoleWriter struct {
+	out io.Writer
+}
+
+func (w consoleWriter) Write(data []byte) (int, error) {
+	if consoleMuted.Load() {
+		return len(data), nil
+	}
+	return w.out.Write(data)
+}
+
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
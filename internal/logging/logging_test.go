@@ -0,0 +1,106 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseLevelAndFormat(t *testing.T) {
+	cases := map[string]slog.Level{"": DefaultLevel, "debug": slog.LevelDebug, "INFO": slog.LevelInfo, "warning": slog.LevelWarn, " error ": slog.LevelError}
+	for value, want := range cases {
+		got, err := ParseLevel(value)
+		if err != nil || got != want {
+			t.Fatalf("ParseLevel(%q) = %v, %v, want %v", value, got, err, want)
+		}
+	}
+	if _, err := ParseLevel("trace"); err == nil {
+		t.Fatalf("expected unknown level to fail")
+	}
+	if format, err := ParseFormat("JSON"); err != nil || format != FormatJSON {
+		t.Fatalf("unexpected format %q, %v", format, err)
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Fatalf("expected unknown format to fail")
+	}
+}
+
+func TestConsoleLoggerFiltersLevelAndMutes(t *testing.T) {
+	var stderr bytes.Buffer
+	logger, _, err := New(Options{Level: slog.LevelWarn, Stderr: &stderr})
+	if err != nil {
+		t.Fatalf("new logger: %v", err)
+	}
+	logger.Info("hidden")
+	logger.Error("command failed", "path", "db migrate", "exit", 3)
+	output := stderr.String()
+	if strings.Contains(output, "hidden") || !strings.Contains(output, `path="db migrate"`) || !strings.Contains(output, "exit=3") {
+		t.Fatalf("unexpected output: %q", output)
+	}
+	if strings.Contains(output, "time=") {
+		t.Fatalf("console logs should omit the time, got %q", output)
+	}
+
+	stderr.Reset()
+	restore := MuteConsole()
+	logger.Error("muted")
+	restore()
+	logger.Error("restored")
+	if strings.Contains(stderr.String(), "muted") || !strings.Contains(stderr.String(), "restored") {
+		t.Fatalf("unexpected output after mute: %q", stderr.String())
+	}
+
+	stderr.Reset()
+	quiet, _, _ := New(Options{Level: slog.LevelDebug, Quiet: true, Stderr: &stderr})
+	quiet.Error("quiet")
+	if stderr.Len() != 0 {
+		t.Fatalf("quiet logger should not write to stderr, got %q", stderr.String())
+	}
+}
+
+func TestFileLoggerWritesJSON(t *testing.T) {
+	var stderr bytes.Buffer
+	path := filepath.Join(t.TempDir(), "logs", "alpen.log")
+	logger, closer, err := New(Options{Level: slog.LevelDebug, Format: FormatJSON, File: path, Quiet: true, Stderr: &stderr})
+	if err != nil {
+		t.Fatalf("new logger: %v", err)
+	}
+	logger.Debug("command started", "path", "build")
+	if err := closer.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if stderr.Len() != 0 {
+		t.Fatalf("file logger should not write to stderr, got %q", stderr.String())
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read log file: %v", err)
+	}
+	var record map[string]any
+	if err := json.Unmarshal(data, &record); err != nil {
+		t.Fatalf("log file should contain JSON, got %q: %v", data, err)
+	}
+	if record["level"] != "DEBUG" || record["msg"] != "command started" || record["path"] != "build" || record["time"] == nil {
+		t.Fatalf("unexpected record: %v", record)
+	}
+}
+
+func TestFileLoggerFallsBackToStderr(t *testing.T) {
+	var stderr bytes.Buffer
+	blocker := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(blocker, nil, 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	logger, _, err := New(Options{Level: slog.LevelWarn, File: filepath.Join(blocker, "alpen.log"), Stderr: &stderr})
+	if err == nil {
+		t.Fatalf("expected open failure to be reported")
+	}
+	logger.Warn("fallback")
+	if !strings.Contains(stderr.String(), "fallback") {
+		t.Fatalf("expected fallback to stderr, got %q", stderr.String())
+	}
+}
@@ -0,0 +1,335 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alpen/alpen-cli/internal/fsutil"
+	"github.com/alpen/alpen-cli/internal/i18n"
+	"github.com/alpen/alpen-cli/internal/lifecycle"
+	"github.com/alpen/alpen-cli/internal/logging"
+	"github.com/alpen/alpen-cli/internal/redact"
+)
+
+// PluginName 为审计插件在注册表中的名称
+const PluginName = "audit"
+
+// DefaultFileName 为审计日志在状态目录下的文件名
+const DefaultFileName = "audit.log"
+
+const lockSuffix = ".lock"
+
+// Entry 表示审计日志中的一行记录
+type Entry struct {
+	Time        time.Time `json:"time"`
+	Event       string    `json:"event"`
+	User        string    `json:"user"`
+	Host        string    `json:"host"`
+	CommandPath []string  `json:"command_path"`
+	Args        []string  `json:"args,omitempty"`
+	ConfigFile  string    `json:"config_file,omitempty"`
+	Origin      string    `json:"origin,omitempty"`
+	Environment string    `json:"environment,omitempty"`
+	ExitCode    *int      `json:"exit_code,omitempty"`
+	DurationMS  int64     `json:"duration_ms,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	PrevHash    string    `json:"prev_hash"`
+}
+
+// Plugin 将命令执行事件以哈希链的形式追加到审计日志
+type Plugin struct {
+	path   string
+	now    func() time.Time
+	user   string
+	host   string
+	logger *slog.Logger
+}
+
+// New 创建写入指定文件的审计插件，logger 为空时输出到标准错误
+func New(path string, logger *slog.Logger) *Plugin {
+	if logger == nil {
+		logger = logging.Stderr()
+	}
+	return &Plugin{
+		path:   path,
+		now:    time.Now,
+		user:   currentUser(),
+		host:   currentHost(),
+		logger: logger,
+	}
+}
+
+// Name 实现 plugins.Plugin
+func (p *Plugin) Name() string {
+	return PluginName
+}
+
+// Path 返回审计日志路径
+func (p *Plugin) Path() string {
+	return p.path
+}
+
+// Subscribes 实现 plugins.Subscriber
+func (p *Plugin) Subscribes(event lifecycle.Event, _ *lifecycle.Context) bool {
+	switch event {
+	case lifecycle.EventBeforeExecute, lifecycle.EventAfterExecute, lifecycle.EventError:
+		return true
+	default:
+		return false
+	}
+}
+
+// Handle 在执行前后及失败时写入审计记录。执行前写入失败时返回错误以阻止命令执行；
+// 命令已执行后写入失败只记录日志，不影响其他插件与命令结果
+func (p *Plugin) Handle(_ context.Context, event lifecycle.Event, payload *lifecycle.Context) error {
+	if payload == nil {
+		return nil
+	}
+	entry := Entry{
+		Time:        p.now().UTC(),
+		User:        p.user,
+		Host:        p.host,
+		CommandPath: append([]string(nil), payload.CommandPath...),
+		Args:        redact.Args(payload.Args),
+		ConfigFile:  payload.ConfigPath,
+		Origin:      payload.Source,
+		Environment: payload.Environment,
+	}
+	switch event {
+	case lifecycle.EventBeforeExecute:
+		entry.Event = "start"
+	case lifecycle.EventAfterExecute:
+		entry.Event = "finish"
+		code := payload.ExitCode
+		entry.ExitCode = &code
+		entry.DurationMS = durationMillis(payload)
+	case lifecycle.EventError:
+		entry.Event = "error"
+		code := payload.ExitCode
+		entry.ExitCode = &code
+		entry.DurationMS = durationMillis(payload)
+		if payload.Err != nil {
+			entry.Error = payload.Err.Error()
+		}
+	default:
+		return nil
+	}
+	if err := p.Append(entry); err != nil {
+		if event == lifecycle.EventBeforeExecute {
+			return i18n.Errorf("audit.write_failed", err)
+		}
+		p.logger.Warn(i18n.T("audit.record_failed"), "event", entry.Event, "path", strings.Join(entry.CommandPath, " "), "err", err)
+	}
+	return nil
+}
+
+// Append 计算上一行的哈希后将记录追加到日志末尾
+func (p *Plugin) Append(entry Entry) error {
+	if err := os.MkdirAll(filepath.Dir(p.path), 0o700); err != nil {
+		return err
+	}
+	unlock, err := fsutil.AcquireLock(p.path + lockSuffix)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	f, err := os.OpenFile(p.path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	last, err := readLastLine(f)
+	if err != nil {
+		return err
+	}
+	entry.PrevHash = HashLine(last)
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// HashLine 返回一行日志（不含换行符）的 SHA-256，首行之前的哈希为空字符串
+func HashLine(line []byte) string {
+	if len(line) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256(line)
+	return hex.EncodeToString(sum[:])
+}
+
+// Problem 描述校验过程中发现的异常行
+type Problem struct {
+	Line    int
+	Message string
+}
+
+// Report 为审计日志校验结果
+type Report struct {
+	Entries  int
+	Problems []Problem
+}
+
+// OK 表示哈希链完整
+func (r Report) OK() bool {
+	return len(r.Problems) == 0
+}
+
+// Verify 逐行校验哈希链，返回被篡改或无法解析的行
+func Verify(path string) (Report, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Report{}, err
+	}
+	defer f.Close()
+
+	var report Report
+	var prev []byte
+	lineNo := 0
+	err = scanLines(f, func(line []byte) error {
+		lineNo++
+		var entry Entry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			report.Problems = append(report.Problems, Problem{Line: lineNo, Message: i18n.T("audit.unparsable", err)})
+		} else if expected := HashLine(prev); entry.PrevHash != expected {
+			report.Problems = append(report.Problems, Problem{Line: lineNo, Message: i18n.T("audit.hash_mismatch")})
+		}
+		report.Entries++
+		prev = append(prev[:0], line...)
+		return nil
+	})
+	return report, err
+}
+
+// Filter 描述导出时的时间范围，零值表示不限制
+type Filter struct {
+	From time.Time
+	To   time.Time
+}
+
+func (f Filter) match(t time.Time) bool {
+	if !f.From.IsZero() && t.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !t.Before(f.To) {
+		return false
+	}
+	return true
+}
+
+// Read 读取时间范围内的全部审计记录
+func Read(path string, filter Filter) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []Entry
+	lineNo := 0
+	err = scanLines(f, func(line []byte) error {
+		lineNo++
+		var entry Entry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return i18n.Errorf("audit.line_unparsable", lineNo, err)
+		}
+		if filter.match(entry.Time) {
+			entries = append(entries, entry)
+		}
+		return nil
+	})
+	return entries, err
+}
+
+func durationMillis(payload *lifecycle.Context) int64 {
+	if payload.StartAt.IsZero() || payload.EndAt.IsZero() {
+		return 0
+	}
+	return payload.EndAt.Sub(payload.StartAt).Milliseconds()
+}
+
+func scanLines(r io.Reader, fn func(line []byte) error) error {
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		line = bytes.TrimRight(line, "\r\n")
+		if len(line) > 0 {
+			if cbErr := fn(line); cbErr != nil {
+				return cbErr
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// readLastLine 从文件末尾向前查找最后一个非空行
+func readLastLine(f *os.File) ([]byte, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := info.Size()
+	if size == 0 {
+		return nil, nil
+	}
+	const chunk = 4096
+	var buf []byte
+	offset := size
+	for offset > 0 {
+		readSize := int64(chunk)
+		if offset < readSize {
+			readSize = offset
+		}
+		offset -= readSize
+		part := make([]byte, readSize)
+		if _, err := f.ReadAt(part, offset); err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		buf = append(part, buf...)
+		trimmed := bytes.TrimRight(buf, "\r\n")
+		if idx := bytes.LastIndexByte(trimmed, '\n'); idx >= 0 {
+			return append([]byte(nil), trimmed[idx+1:]...), nil
+		}
+	}
+	return bytes.TrimRight(buf, "\r\n"), nil
+}
+
+func currentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	for _, key := range []string{"USER", "USERNAME", "LOGNAME"} {
+		if value := strings.TrimSpace(os.Getenv(key)); value != "" {
+			return value
+		}
+	}
+	return "unknown"
+}
+
+func currentHost() string {
+	if host, err := os.Hostname(); err == nil && host != "" {
+		return host
+	}
+	return "unknown"
+}
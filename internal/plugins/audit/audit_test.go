@@ -0,0 +1,137 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alpen/alpen-cli/internal/lifecycle"
+	"github.com/alpen/alpen-cli/internal/redact"
+)
+
+func TestPluginWritesHashChain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	plugin := New(path, nil)
+
+	payload := &lifecycle.Context{
+		CommandPath: []string{"db", "migrate"},
+		Args:        []string{"--token", "abc", "--verbose"},
+		ConfigPath:  "/home/demo/.alpen/config/demo.yaml",
+		Environment: "prod",
+	}
+	ctx := context.Background()
+	if err := plugin.Handle(ctx, lifecycle.EventBeforeExecute, payload); err != nil {
+		t.Fatalf("before execute failed: %v", err)
+	}
+	payload.StartAt = time.Now()
+	payload.EndAt = payload.StartAt.Add(1500 * time.Millisecond)
+	if err := plugin.Handle(ctx, lifecycle.EventAfterExecute, payload); err != nil {
+		t.Fatalf("after execute failed: %v", err)
+	}
+
+	report, err := Verify(path)
+	if err != nil {
+		t.Fatalf("verify failed: %v", err)
+	}
+	if !report.OK() || report.Entries != 2 {
+		t.Fatalf("expected intact chain with 2 entries, got %+v", report)
+	}
+
+	entries, err := Read(path, Filter{})
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if entries[0].PrevHash != "" || entries[1].PrevHash == "" {
+		t.Fatalf("unexpected prev hashes: %q %q", entries[0].PrevHash, entries[1].PrevHash)
+	}
+	if entries[1].Args[1] != redact.Mask {
+		t.Fatalf("expected token argument to be redacted, got %v", entries[1].Args)
+	}
+	if entries[1].DurationMS != 1500 || entries[1].ExitCode == nil || *entries[1].ExitCode != 0 {
+		t.Fatalf("unexpected finish entry: %+v", entries[1])
+	}
+}
+
+func TestPluginFailsClosedOnlyBeforeExecution(t *testing.T) {
+	// 父路径为普通文件，任何用户都无法创建审计日志
+	blocker := filepath.Join(t.TempDir(), "state")
+	if err := os.WriteFile(blocker, nil, 0o600); err != nil {
+		t.Fatalf("write blocker: %v", err)
+	}
+	logs := &bytes.Buffer{}
+	plugin := New(filepath.Join(blocker, "audit.log"), slog.New(slog.NewTextHandler(logs, nil)))
+	payload := &lifecycle.Context{CommandPath: []string{"build"}, StartAt: time.Now(), EndAt: time.Now()}
+	ctx := context.Background()
+
+	if err := plugin.Handle(ctx, lifecycle.EventBeforeExecute, payload); err == nil {
+		t.Fatalf("expected before execute to fail when the audit log is unwritable")
+	}
+	payload.Err = errors.New("exit status 1")
+	for _, event := range []lifecycle.Event{lifecycle.EventAfterExecute, lifecycle.EventError} {
+		if err := plugin.Handle(ctx, event, payload); err != nil {
+			t.Fatalf("%s should only log audit failures, got %v", event, err)
+		}
+	}
+	if count := strings.Count(logs.String(), "level=WARN"); count != 2 {
+		t.Fatalf("expected 2 logged failures, got %d: %s", count, logs.String())
+	}
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	plugin := New(path, nil)
+	for i := 0; i < 3; i++ {
+		if err := plugin.Handle(context.Background(), lifecycle.EventError, &lifecycle.Context{
+			CommandPath: []string{"deploy"},
+			ExitCode:    1,
+			Err:         errors.New("exit status 1"),
+		}); err != nil {
+			t.Fatalf("append failed: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read log failed: %v", err)
+	}
+	tampered := strings.Replace(string(data), `"deploy"`, `"status"`, 1)
+	if err := os.WriteFile(path, []byte(tampered), 0o600); err != nil {
+		t.Fatalf("write log failed: %v", err)
+	}
+
+	report, err := Verify(path)
+	if err != nil {
+		t.Fatalf("verify failed: %v", err)
+	}
+	if report.OK() {
+		t.Fatalf("expected tampering to be detected")
+	}
+	if report.Problems[0].Line != 2 {
+		t.Fatalf("expected problem reported on line 2, got %+v", report.Problems)
+	}
+}
+
+func TestReadFiltersByTimeRange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	plugin := New(path, nil)
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		if err := plugin.Append(Entry{Time: base.AddDate(0, 0, i), Event: "start", CommandPath: []string{"build"}}); err != nil {
+			t.Fatalf("append failed: %v", err)
+		}
+	}
+
+	entries, err := Read(path, Filter{From: base.AddDate(0, 0, 1), To: base.AddDate(0, 0, 2)})
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if len(entries) != 1 || !entries[0].Time.Equal(base.AddDate(0, 0, 1)) {
+		t.Fatalf("unexpected filtered entries: %+v", entries)
+	}
+}
@@ -0,0 +1,268 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alpen/alpen-cli/internal/fsutil"
+	"github.com/alpen/alpen-cli/internal/i18n"
+	"github.com/alpen/alpen-cli/internal/lifecycle"
+	"github.com/alpen/alpen-cli/internal/logging"
+)
+
+// PluginName 为指标插件在注册表中的名称
+const PluginName = "metrics"
+
+// DefaultFileName 为指标状态在状态目录下的文件名
+const DefaultFileName = "metrics.json"
+
+const (
+	lockSuffix = ".lock"
+	// retryWindow 内再次执行上一次失败的命令视为一次重试
+	retryWindow = 10 * time.Minute
+	// recentSamples 为计算分位数保留的最近耗时样本数
+	recentSamples = 200
+)
+
+// Buckets 为耗时直方图的桶上界（秒）
+var Buckets = []float64{0.1, 0.5, 1, 5, 10, 30, 60, 300, 900, 1800, 3600}
+
+// CommandStats 为单条命令路径的累计指标
+type CommandStats struct {
+	Runs        int64     `json:"runs"`
+	Failures    int64     `json:"failures"`
+	Retries     int64     `json:"retries"`
+	DurationSum float64   `json:"duration_sum_seconds"`
+	Buckets     []int64   `json:"buckets"`
+	Recent      []float64 `json:"recent_seconds"`
+	LastRun     time.Time `json:"last_run"`
+	LastFailed  bool      `json:"last_failed"`
+}
+
+// Store 为持久化在状态目录中的全部指标
+type Store struct {
+	Commands map[string]*CommandStats `json:"commands"`
+}
+
+// Observe 记录一次执行结果
+func (s *Store) Observe(command string, duration time.Duration, failed bool, endAt time.Time) {
+	if s.Commands == nil {
+		s.Commands = map[string]*CommandStats{}
+	}
+	stats, ok := s.Commands[command]
+	if !ok {
+		stats = &CommandStats{}
+		s.Commands[command] = stats
+	}
+	if len(stats.Buckets) != len(Buckets) {
+		stats.Buckets = make([]int64, len(Buckets))
+	}
+	startAt := endAt.Add(-duration)
+	if stats.LastFailed && !stats.LastRun.IsZero() && startAt.Sub(stats.LastRun) <= retryWindow {
+		stats.Retries++
+	}
+	seconds := duration.Seconds()
+	stats.Runs++
+	if failed {
+		stats.Failures++
+	}
+	stats.DurationSum += seconds
+	for i, bound := range Buckets {
+		if seconds <= bound {
+			stats.Buckets[i]++
+		}
+	}
+	stats.Recent = append(stats.Recent, seconds)
+	if len(stats.Recent) > recentSamples {
+		stats.Recent = stats.Recent[len(stats.Recent)-recentSamples:]
+	}
+	stats.LastRun = endAt
+	stats.LastFailed = failed
+}
+
+// SortedCommands 按执行次数降序返回命令路径
+func (s *Store) SortedCommands() []string {
+	names := make([]string, 0, len(s.Commands))
+	for name := range s.Commands {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := s.Commands[names[i]], s.Commands[names[j]]
+		if a.Runs != b.Runs {
+			return a.Runs > b.Runs
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// FailureRate 返回失败率（0~1）
+func (c *CommandStats) FailureRate() float64 {
+	if c.Runs == 0 {
+		return 0
+	}
+	return float64(c.Failures) / float64(c.Runs)
+}
+
+// Percentile 基于最近的耗时样本计算分位数，q 取值 0~1
+func (c *CommandStats) Percentile(q float64) time.Duration {
+	if len(c.Recent) == 0 {
+		return 0
+	}
+	samples := append([]float64(nil), c.Recent...)
+	sort.Float64s(samples)
+	rank := int(math.Ceil(q*float64(len(samples)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(samples) {
+		rank = len(samples) - 1
+	}
+	return time.Duration(samples[rank] * float64(time.Second))
+}
+
+// Load 读取指标状态，文件不存在时返回空结果
+func Load(path string) (*Store, error) {
+	store := &Store{Commands: map[string]*CommandStats{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, store); err != nil {
+		return nil, i18n.Errorf("metrics.parse_failed", path, err)
+	}
+	if store.Commands == nil {
+		store.Commands = map[string]*CommandStats{}
+	}
+	return store, nil
+}
+
+// Save 原子写入指标状态
+func (s *Store) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(path, data, 0o600)
+}
+
+// Plugin 在每次执行结束后更新指标，并可选输出 Prometheus textfile
+type Plugin struct {
+	statePath string
+	textfile  string
+	logger    *slog.Logger
+}
+
+// New 创建指标插件，textfile 为空时仅记录到状态目录，logger 为空时输出到标准错误
+func New(statePath string, textfile string, logger *slog.Logger) *Plugin {
+	if logger == nil {
+		logger = logging.Stderr()
+	}
+	return &Plugin{statePath: statePath, textfile: textfile, logger: logger}
+}
+
+// Name 实现 plugins.Plugin
+func (p *Plugin) Name() string {
+	return PluginName
+}
+
+// Subscribes 实现 plugins.Subscriber，仅在执行结束后记录
+func (p *Plugin) Subscribes(event lifecycle.Event, _ *lifecycle.Context) bool {
+	return event == lifecycle.EventAfterExecute || event == lifecycle.EventError
+}
+
+// Handle 在执行成功或失败后记录指标；记录失败只输出警告，不影响命令的执行结果与后续插件
+func (p *Plugin) Handle(_ context.Context, event lifecycle.Event, payload *lifecycle.Context) error {
+	if payload == nil || !p.Subscribes(event, payload) {
+		return nil
+	}
+	endAt := payload.EndAt
+	if endAt.IsZero() {
+		endAt = time.Now()
+	}
+	var duration time.Duration
+	if !payload.StartAt.IsZero() {
+		duration = endAt.Sub(payload.StartAt)
+	}
+	command := strings.Join(payload.CommandPath, " ")
+	if err := p.record(command, duration, event == lifecycle.EventError, endAt); err != nil {
+		p.logger.Warn(i18n.T("metrics.record_failed"), "path", command, "err", err)
+	}
+	return nil
+}
+
+func (p *Plugin) record(command string, duration time.Duration, failed bool, endAt time.Time) error {
+	unlock, err := fsutil.AcquireLock(p.statePath + lockSuffix)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	store, err := Load(p.statePath)
+	if err != nil {
+		return err
+	}
+	store.Observe(command, duration, failed, endAt)
+	if err := store.Save(p.statePath); err != nil {
+		return err
+	}
+	if strings.TrimSpace(p.textfile) == "" {
+		return nil
+	}
+	return fsutil.WriteFileAtomic(p.textfile, []byte(store.Prometheus()), 0o644)
+}
+
+// Prometheus 以 Prometheus 文本格式输出全部指标
+func (s *Store) Prometheus() string {
+	names := make([]string, 0, len(s.Commands))
+	for name := range s.Commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	writeCounter := func(metric, help string, value func(*CommandStats) int64) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s counter\n", metric, help, metric)
+		for _, name := range names {
+			fmt.Fprintf(&b, "%s{command=\"%s\"} %d\n", metric, escapeLabel(name), value(s.Commands[name]))
+		}
+	}
+	writeCounter("alpen_command_runs_total", "Total number of command runs.", func(c *CommandStats) int64 { return c.Runs })
+	writeCounter("alpen_command_failures_total", "Total number of failed command runs.", func(c *CommandStats) int64 { return c.Failures })
+	writeCounter("alpen_command_retries_total", "Runs started within 10 minutes after a failed run of the same command.", func(c *CommandStats) int64 { return c.Retries })
+
+	const histogram = "alpen_command_duration_seconds"
+	fmt.Fprintf(&b, "# HELP %s Command run duration in seconds.\n# TYPE %s histogram\n", histogram, histogram)
+	for _, name := range names {
+		stats := s.Commands[name]
+		label := escapeLabel(name)
+		for i, bound := range Buckets {
+			var count int64
+			if i < len(stats.Buckets) {
+				count = stats.Buckets[i]
+			}
+			fmt.Fprintf(&b, "%s_bucket{command=\"%s\",le=\"%s\"} %d\n", histogram, label, strconv.FormatFloat(bound, 'g', -1, 64), count)
+		}
+		fmt.Fprintf(&b, "%s_bucket{command=\"%s\",le=\"+Inf\"} %d\n", histogram, label, stats.Runs)
+		fmt.Fprintf(&b, "%s_sum{command=\"%s\"} %s\n", histogram, label, strconv.FormatFloat(stats.DurationSum, 'f', -1, 64))
+		fmt.Fprintf(&b, "%s_count{command=\"%s\"} %d\n", histogram, label, stats.Runs)
+	}
+	return b.String()
+}
+
+func escapeLabel(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return replacer.Replace(value)
+}
@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alpen/alpen-cli/internal/lifecycle"
+	"github.com/alpen/alpen-cli/internal/logging"
+)
+
+func TestStoreObserveCountsRetriesAndPercentiles(t *testing.T) {
+	store := &Store{}
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	store.Observe("deploy", 2*time.Second, true, now)
+	store.Observe("deploy", 4*time.Second, false, now.Add(time.Minute))
+	store.Observe("deploy", 6*time.Second, false, now.Add(time.Hour))
+
+	stats := store.Commands["deploy"]
+	if stats.Runs != 3 || stats.Failures != 1 || stats.Retries != 1 {
+		t.Fatalf("unexpected counters: %+v", stats)
+	}
+	if got := stats.Percentile(0.5); got != 4*time.Second {
+		t.Fatalf("unexpected p50: %s", got)
+	}
+	if got := stats.Percentile(0.95); got != 6*time.Second {
+		t.Fatalf("unexpected p95: %s", got)
+	}
+	if rate := stats.FailureRate(); rate < 0.33 || rate > 0.34 {
+		t.Fatalf("unexpected failure rate: %f", rate)
+	}
+}
+
+func TestPluginWritesStateAndTextfile(t *testing.T) {
+	dir := t.TempDir()
+	statePath := filepath.Join(dir, "state", "metrics.json")
+	textfile := filepath.Join(dir, "textfile", "alpen.prom")
+	plugin := New(statePath, textfile, logging.Discard())
+
+	start := time.Now().Add(-3 * time.Second)
+	payload := &lifecycle.Context{CommandPath: []string{"db", "migrate"}, StartAt: start, EndAt: start.Add(3 * time.Second)}
+	if err := plugin.Handle(context.Background(), lifecycle.EventAfterExecute, payload); err != nil {
+		t.Fatalf("handle success failed: %v", err)
+	}
+	payload.Err = errors.New("exit status 1")
+	if err := plugin.Handle(context.Background(), lifecycle.EventError, payload); err != nil {
+		t.Fatalf("handle error failed: %v", err)
+	}
+
+	store, err := Load(statePath)
+	if err != nil {
+		t.Fatalf("load state failed: %v", err)
+	}
+	if stats := store.Commands["db migrate"]; stats == nil || stats.Runs != 2 || stats.Failures != 1 {
+		t.Fatalf("unexpected stored stats: %+v", store.Commands)
+	}
+
+	data, err := os.ReadFile(textfile)
+	if err != nil {
+		t.Fatalf("read textfile failed: %v", err)
+	}
+	text := string(data)
+	for _, want := range []string{
+		`alpen_command_runs_total{command="db migrate"} 2`,
+		`alpen_command_failures_total{command="db migrate"} 1`,
+		`alpen_command_duration_seconds_bucket{command="db migrate",le="5"} 2`,
+		`alpen_command_duration_seconds_bucket{command="db migrate",le="1"} 0`,
+		`alpen_command_duration_seconds_count{command="db migrate"} 2`,
+	} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("textfile missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestPluginIgnoresRecordFailures(t *testing.T) {
+	dir := t.TempDir()
+	blocker := filepath.Join(dir, "blocker")
+	if err := os.WriteFile(blocker, nil, 0o644); err != nil {
+		t.Fatalf("write blocker failed: %v", err)
+	}
+	// textfile 所在目录实际是普通文件，写入必然失败
+	plugin := New(filepath.Join(dir, "metrics.json"), filepath.Join(blocker, "alpen.prom"), logging.Discard())
+	payload := &lifecycle.Context{CommandPath: []string{"deploy"}, StartAt: time.Now(), EndAt: time.Now()}
+	if err := plugin.Handle(context.Background(), lifecycle.EventAfterExecute, payload); err != nil {
+		t.Fatalf("metrics failures must not fail the command: %v", err)
+	}
+}
@@ -0,0 +1,348 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/template"
+	"time"
+
+	"github.com/alpen/alpen-cli/internal/config"
+	"github.com/alpen/alpen-cli/internal/i18n"
+	"github.com/alpen/alpen-cli/internal/lifecycle"
+	"github.com/alpen/alpen-cli/internal/logging"
+)
+
+// PluginName 为通知插件在注册表中的名称
+const PluginName = "notify"
+
+const (
+	defaultRetries        = 3
+	defaultBackoff        = 500 * time.Millisecond
+	maxBackoff            = 10 * time.Second
+	defaultRequestTimeout = 10 * time.Second
+)
+
+// Resolver 根据命令路径返回生效的通知配置，未配置时返回 nil
+type Resolver func(commandPath []string) *config.NotifySpec
+
+// Options 用于构造通知插件
+type Options struct {
+	Resolve Resolver
+	Logger  *slog.Logger
+	Client  *http.Client
+	// Backoff 为首次重试前的等待时间，之后每次翻倍
+	Backoff time.Duration
+}
+
+// Plugin 在长耗时命令结束后发送 Webhook 或执行本地通知命令
+type Plugin struct {
+	resolve Resolver
+	logger  *slog.Logger
+	client  *http.Client
+	backoff time.Duration
+	wg      sync.WaitGroup
+	pending atomic.Int64
+	// ctx 在 Flush 超时后取消，正在发送的请求与重试等待随之结束
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// Payload 为通知模板与默认 JSON 请求体的数据
+type Payload struct {
+	Command     string    `json:"command"`
+	CommandPath []string  `json:"command_path"`
+	Status      string    `json:"status"`
+	ExitCode    int       `json:"exit_code"`
+	Duration    string    `json:"duration"`
+	DurationMS  int64     `json:"duration_ms"`
+	StartAt     time.Time `json:"start_at"`
+	EndAt       time.Time `json:"end_at"`
+	Environment string    `json:"environment,omitempty"`
+	ConfigFile  string    `json:"config_file,omitempty"`
+	Host        string    `json:"host"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// New 创建通知插件
+func New(opts Options) *Plugin {
+	logger := opts.Logger
+	if logger == nil {
+		logger = logging.Stderr()
+	}
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{Timeout: defaultRequestTimeout}
+	}
+	backoff := opts.Backoff
+	if backoff <= 0 {
+		backoff = defaultBackoff
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Plugin{
+		resolve: opts.Resolve,
+		logger:  logger,
+		client:  client,
+		backoff: backoff,
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+// SetResolver 替换通知配置来源，便于在配置加载完成后绑定
+func (p *Plugin) SetResolver(resolve Resolver) {
+	p.resolve = resolve
+}
+
+// Name 实现 plugins.Plugin
+func (p *Plugin) Name() string {
+	return PluginName
+}
+
+// Subscribes 实现 plugins.Subscriber，命令配置了对应状态的通知时返回 true（不考虑 after 时长）
+func (p *Plugin) Subscribes(event lifecycle.Event, payload *lifecycle.Context) bool {
+	if payload == nil || p.resolve == nil {
+		return false
+	}
+	status := eventStatus(event)
+	if status == "" {
+		return false
+	}
+	spec := p.resolve(payload.CommandPath)
+	if spec == nil {
+		return false
+	}
+	for _, trigger := range spec.Triggers() {
+		if trigger == status {
+			return true
+		}
+	}
+	return false
+}
+
+// eventStatus 将生命周期事件映射为通知状态，其他事件返回空字符串
+func eventStatus(event lifecycle.Event) string {
+	switch event {
+	case lifecycle.EventAfterExecute:
+		return config.NotifyOnSuccess
+	case lifecycle.EventError:
+		return config.NotifyOnFailure
+	default:
+		return ""
+	}
+}
+
+// Handle 在命令结束后异步发送通知，任何失败都只记录日志而不影响主命令
+func (p *Plugin) Handle(_ context.Context, event lifecycle.Event, payload *lifecycle.Context) error {
+	if payload == nil || p.resolve == nil {
+		return nil
+	}
+	status := eventStatus(event)
+	if status == "" {
+		return nil
+	}
+	spec := p.resolve(payload.CommandPath)
+	if spec == nil || !shouldNotify(*spec, status, payload) {
+		return nil
+	}
+	data := buildPayload(status, payload)
+	rule := *spec
+	p.wg.Add(1)
+	p.pending.Add(1)
+	go func() {
+		defer p.wg.Done()
+		defer p.pending.Add(-1)
+		// Flush 放弃的通知已统一记录日志
+		if err := p.deliver(rule, data); err != nil && p.ctx.Err() == nil {
+			p.logger.Warn(i18n.T("notify.send_failed"), "path", data.Command, "err", err)
+		}
+	}()
+	return nil
+}
+
+// Wait 等待进行中的通知发送完成，超时后直接返回以免阻塞进程退出
+func (p *Plugin) Wait(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// Flush 在进程退出前最多等待 grace，超时后取消仍在发送的通知并记录放弃的数量，
+// 无响应的通知地址不会拖住命令退出
+func (p *Plugin) Flush(grace time.Duration) {
+	if p.Wait(grace) {
+		return
+	}
+	pending := p.pending.Load()
+	p.cancel()
+	p.logger.Warn(i18n.T("notify.abandoned"), "pending", pending, "grace", grace)
+}
+
+func shouldNotify(spec config.NotifySpec, status string, payload *lifecycle.Context) bool {
+	matched := false
+	for _, trigger := range spec.Triggers() {
+		if trigger == status {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return false
+	}
+	if after := spec.After.Std(); after > 0 {
+		if payload.StartAt.IsZero() || payload.EndAt.Sub(payload.StartAt) < after {
+			return false
+		}
+	}
+	return true
+}
+
+func buildPayload(status string, ctx *lifecycle.Context) Payload {
+	host, _ := os.Hostname()
+	data := Payload{
+		Command:     strings.Join(ctx.CommandPath, " "),
+		CommandPath: append([]string(nil), ctx.CommandPath...),
+		Status:      status,
+		ExitCode:    ctx.ExitCode,
+		StartAt:     ctx.StartAt,
+		EndAt:       ctx.EndAt,
+		Environment: ctx.Environment,
+		ConfigFile:  ctx.ConfigPath,
+		Host:        host,
+	}
+	if !ctx.StartAt.IsZero() && !ctx.EndAt.IsZero() {
+		duration := ctx.EndAt.Sub(ctx.StartAt)
+		data.Duration = duration.Round(time.Millisecond).String()
+		data.DurationMS = duration.Milliseconds()
+	}
+	if ctx.Err != nil {
+		data.Error = ctx.Err.Error()
+	}
+	return data
+}
+
+// Render 根据模板生成请求体，未配置模板时输出默认 JSON
+func Render(spec config.NotifySpec, data Payload) ([]byte, error) {
+	if strings.TrimSpace(spec.Template) == "" {
+		return json.Marshal(data)
+	}
+	tmpl, err := template.New("notify").Funcs(template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			encoded, err := json.Marshal(v)
+			return string(encoded), err
+		},
+	}).Parse(spec.Template)
+	if err != nil {
+		return nil, i18n.Errorf("notify.template_parse_failed", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, i18n.Errorf("notify.template_render_failed", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (p *Plugin) deliver(spec config.NotifySpec, data Payload) error {
+	body, err := Render(spec, data)
+	if err != nil {
+		return err
+	}
+	retries := defaultRetries
+	if spec.Retries != nil {
+		retries = *spec.Retries
+	}
+	var lastErr error
+	wait := p.backoff
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(wait):
+			case <-p.ctx.Done():
+				return p.ctx.Err()
+			}
+			wait *= 2
+			if wait > maxBackoff {
+				wait = maxBackoff
+			}
+		}
+		if lastErr = p.send(spec, data, body); lastErr == nil {
+			return nil
+		}
+	}
+	return i18n.Errorf("notify.retries_exhausted", retries, lastErr)
+}
+
+func (p *Plugin) send(spec config.NotifySpec, data Payload, body []byte) error {
+	if url := strings.TrimSpace(spec.URL); url != "" {
+		if err := p.post(url, spec.Headers, body); err != nil {
+			return err
+		}
+	}
+	if command := strings.TrimSpace(spec.Command); command != "" {
+		if err := runCommand(p.ctx, command, data, body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Plugin) post(url string, headers map[string]string, body []byte) error {
+	req, err := http.NewRequestWithContext(p.ctx, http.MethodPost, os.ExpandEnv(url), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "alpen-cli")
+	for key, value := range headers {
+		req.Header.Set(key, os.ExpandEnv(value))
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return i18n.Errorf("notify.server_status", resp.Status)
+	}
+	return nil
+}
+
+// runCommand 执行本地通知命令，请求体通过标准输入传入，关键字段通过环境变量提供
+func runCommand(parent context.Context, command string, data Payload, body []byte) error {
+	ctx, cancel := context.WithTimeout(parent, defaultRequestTimeout)
+	defer cancel()
+	shell, args := "/bin/sh", []string{"-c", command}
+	if runtime.GOOS == "windows" {
+		shell, args = "cmd.exe", []string{"/C", command}
+	}
+	cmd := exec.CommandContext(ctx, shell, args...)
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Env = append(os.Environ(),
+		"ALPEN_NOTIFY_COMMAND="+data.Command,
+		"ALPEN_NOTIFY_STATUS="+data.Status,
+		"ALPEN_NOTIFY_EXIT_CODE="+strconv.Itoa(data.ExitCode),
+		"ALPEN_NOTIFY_DURATION="+data.Duration,
+	)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return i18n.Errorf("notify.command_failed", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alpen/alpen-cli/internal/config"
+	"github.com/alpen/alpen-cli/internal/lifecycle"
+)
+
+type recorder struct {
+	mu       sync.Mutex
+	bodies   [][]byte
+	failures int
+}
+
+func (r *recorder) handler(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failures > 0 {
+		r.failures--
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	r.bodies = append(r.bodies, body)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (r *recorder) received() [][]byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([][]byte(nil), r.bodies...)
+}
+
+func newTestPlugin(spec *config.NotifySpec) *Plugin {
+	return New(Options{
+		Resolve: func([]string) *config.NotifySpec { return spec },
+		Backoff: time.Millisecond,
+	})
+}
+
+func finishedContext(duration time.Duration, err error) *lifecycle.Context {
+	start := time.Now().Add(-duration)
+	ctx := &lifecycle.Context{
+		CommandPath: []string{"build", "release"},
+		Environment: "prod",
+		StartAt:     start,
+		EndAt:       start.Add(duration),
+		Err:         err,
+	}
+	if err != nil {
+		ctx.ExitCode = 2
+	}
+	return ctx
+}
+
+func TestPluginPostsPayloadWithRetry(t *testing.T) {
+	rec := &recorder{failures: 2}
+	server := httptest.NewServer(http.HandlerFunc(rec.handler))
+	defer server.Close()
+
+	plugin := newTestPlugin(&config.NotifySpec{URL: server.URL, On: []string{config.NotifyOnFailure}})
+	if err := plugin.Handle(context.Background(), lifecycle.EventError, finishedContext(time.Minute, errors.New("exit status 2"))); err != nil {
+		t.Fatalf("handle should never fail, got %v", err)
+	}
+	if !plugin.Wait(2 * time.Second) {
+		t.Fatalf("notification did not finish in time")
+	}
+
+	bodies := rec.received()
+	if len(bodies) != 1 {
+		t.Fatalf("expected exactly one delivered notification, got %d", len(bodies))
+	}
+	var payload Payload
+	if err := json.Unmarshal(bodies[0], &payload); err != nil {
+		t.Fatalf("payload is not valid json: %v", err)
+	}
+	if payload.Command != "build release" || payload.Status != config.NotifyOnFailure || payload.ExitCode != 2 {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+}
+
+func TestPluginSkipsShortOrUnmatchedRuns(t *testing.T) {
+	rec := &recorder{}
+	server := httptest.NewServer(http.HandlerFunc(rec.handler))
+	defer server.Close()
+
+	plugin := newTestPlugin(&config.NotifySpec{
+		URL:   server.URL,
+		On:    []string{config.NotifyOnFailure},
+		After: config.Duration(time.Minute),
+	})
+	_ = plugin.Handle(context.Background(), lifecycle.EventAfterExecute, finishedContext(2*time.Minute, nil))
+	_ = plugin.Handle(context.Background(), lifecycle.EventError, finishedContext(time.Second, errors.New("boom")))
+	plugin.Wait(time.Second)
+
+	if got := len(rec.received()); got != 0 {
+		t.Fatalf("expected no notifications, got %d", got)
+	}
+}
+
+func TestPluginRendersTemplate(t *testing.T) {
+	rec := &recorder{}
+	server := httptest.NewServer(http.HandlerFunc(rec.handler))
+	defer server.Close()
+
+	plugin := newTestPlugin(&config.NotifySpec{
+		URL:      server.URL,
+		Template: `{"text": {{ json (printf "%s finished: %s" .Command .Status) }}}`,
+	})
+	_ = plugin.Handle(context.Background(), lifecycle.EventAfterExecute, finishedContext(time.Second, nil))
+	plugin.Wait(time.Second)
+
+	bodies := rec.received()
+	if len(bodies) != 1 {
+		t.Fatalf("expected one notification, got %d", len(bodies))
+	}
+	var message map[string]string
+	if err := json.Unmarshal(bodies[0], &message); err != nil {
+		t.Fatalf("rendered template is not valid json: %v (%s)", err, bodies[0])
+	}
+	if message["text"] != "build release finished: success" {
+		t.Fatalf("unexpected rendered text: %q", message["text"])
+	}
+}
+
+func TestPluginGivesUpWithoutFailingCommand(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	retries := 1
+	plugin := newTestPlugin(&config.NotifySpec{URL: server.URL, Retries: &retries})
+	logs := &lockedBuffer{}
+	plugin.logger = slog.New(slog.NewTextHandler(logs, nil))
+
+	if err := plugin.Handle(context.Background(), lifecycle.EventAfterExecute, finishedContext(time.Second, nil)); err != nil {
+		t.Fatalf("handle should never fail, got %v", err)
+	}
+	if !plugin.Wait(2 * time.Second) {
+		t.Fatalf("delivery did not stop after retries")
+	}
+	if logs.Len() == 0 {
+		t.Fatalf("expected delivery failure to be logged")
+	}
+}
+
+func TestPluginFlushAbandonsHangingWebhook(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		select {
+		case <-release:
+		case <-req.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	plugin := newTestPlugin(&config.NotifySpec{URL: server.URL})
+	logs := &lockedBuffer{}
+	plugin.logger = slog.New(slog.NewTextHandler(logs, nil))
+
+	start := time.Now()
+	if err := plugin.Handle(context.Background(), lifecycle.EventAfterExecute, finishedContext(time.Second, nil)); err != nil {
+		t.Fatalf("handle should never fail, got %v", err)
+	}
+	plugin.Flush(100 * time.Millisecond)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("flush blocked for %s on a hanging webhook", elapsed)
+	}
+	if !strings.Contains(logs.String(), "pending=1") {
+		t.Fatalf("expected abandoned delivery to be logged, got %q", logs.String())
+	}
+	if !plugin.Wait(time.Second) {
+		t.Fatalf("abandoned delivery was not cancelled")
+	}
+}
+
+type lockedBuffer struct {
+	mu   sync.Mutex
+	data []byte
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.data = append(b.data, p...)
+	return len(p), nil
+}
+
+func (b *lockedBuffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.data)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return string(b.data)
+}
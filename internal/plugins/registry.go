@@ -2,10 +2,11 @@ package plugins
 
 import (
 	"context"
-	"fmt"
 	"sync"
 
+	"github.com/alpen/alpen-cli/internal/i18n"
 	"github.com/alpen/alpen-cli/internal/lifecycle"
+	"github.com/alpen/alpen-cli/internal/tracing"
 )
 
 // Plugin 定义插件需要实现的接口
@@ -14,6 +15,12 @@ type Plugin interface {
 	Handle(ctx context.Context, event lifecycle.Event, payload *lifecycle.Context) error
 }
 
+// Subscriber 为插件可选实现的接口，用于在不派发事件的情况下判断插件是否会处理该事件，
+// 供 --dry-run 展示执行计划；未实现该接口的插件视为处理所有事件
+type Subscriber interface {
+	Subscribes(event lifecycle.Event, payload *lifecycle.Context) bool
+}
+
 // Registry 维护插件列表并负责派发事件
 type Registry struct {
 	mu      sync.RWMutex
@@ -33,7 +40,7 @@ func (r *Registry) Register(plugin Plugin) error {
 	defer r.mu.Unlock()
 	for _, existing := range r.plugins {
 		if existing.Name() == plugin.Name() {
-			return fmt.Errorf("插件 %s 已注册", plugin.Name())
+			return i18n.Errorf("plugins.already_registered", plugin.Name())
 		}
 	}
 	r.plugins = append(r.plugins, plugin)
@@ -44,14 +51,44 @@ func (r *Registry) Register(plugin Plugin) error {
 func (r *Registry) Emit(ctx context.Context, event lifecycle.Event, payload *lifecycle.Context) error {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
+	ctx, span := tracing.Start(ctx, "alpen.plugins.emit")
+	defer span.End()
+	span.SetAttr("alpen.event", string(event))
+	names := make([]string, 0, len(r.plugins))
+	for _, plugin := range r.plugins {
+		names = append(names, plugin.Name())
+	}
+	span.SetAttr("alpen.plugins", names)
 	for _, plugin := range r.plugins {
-		if err := plugin.Handle(ctx, event, payload); err != nil {
-			return fmt.Errorf("插件 %s 处理事件失败: %w", plugin.Name(), err)
+		pluginCtx, pluginSpan := tracing.Start(ctx, "alpen.plugin.handle")
+		pluginSpan.SetAttr("alpen.plugin", plugin.Name())
+		pluginSpan.SetAttr("alpen.event", string(event))
+		err := plugin.Handle(pluginCtx, event, payload)
+		pluginSpan.RecordError(err)
+		pluginSpan.End()
+		if err != nil {
+			err = i18n.Errorf("plugins.handle_failed", plugin.Name(), err)
+			span.RecordError(err)
+			return err
 		}
 	}
 	return nil
 }
 
+// Subscribers 返回会处理指定事件的插件名称，按注册顺序排列
+func (r *Registry) Subscribers(event lifecycle.Event, payload *lifecycle.Context) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var names []string
+	for _, plugin := range r.plugins {
+		if subscriber, ok := plugin.(Subscriber); ok && !subscriber.Subscribes(event, payload) {
+			continue
+		}
+		names = append(names, plugin.Name())
+	}
+	return names
+}
+
 // Snapshot 返回当前已注册插件列表，便于对外展示
 func (r *Registry) Snapshot() []Plugin {
 	r.mu.RLock()
@@ -0,0 +1,134 @@
+package prereq
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/alpen/alpen-cli/internal/config"
+	"github.com/alpen/alpen-cli/internal/i18n"
+)
+
+// devVersion 为未注入版本号的开发构建，不检查 min_alpen_version
+const devVersion = "dev"
+
+// Checker 检查执行目标在当前机器上的平台与前置条件
+type Checker struct {
+	// Version 为当前 alpen 版本
+	Version string
+	// GOOS 为当前平台，默认取 runtime.GOOS
+	GOOS      string
+	LookPath  func(string) (string, error)
+	LookupEnv func(string) (string, bool)
+	Stat      func(string) (os.FileInfo, error)
+}
+
+// New 创建使用当前进程环境的检查器
+func New(version string) *Checker {
+	return &Checker{
+		Version:   version,
+		GOOS:      runtime.GOOS,
+		LookPath:  exec.LookPath,
+		LookupEnv: os.LookupEnv,
+		Stat:      os.Stat,
+	}
+}
+
+// Report 描述执行目标的检查结果
+type Report struct {
+	// Platform 非空表示当前平台不在 platforms 中，内容为原因
+	Platform string
+	// Version 非空表示当前版本低于 min_alpen_version，内容为原因
+	Version      string
+	MissingBins  []string
+	MissingEnv   []string
+	MissingFiles []string
+}
+
+// Supported 判断目标是否适用于当前平台
+func (r Report) Supported() bool {
+	return r.Platform == ""
+}
+
+// OK 判断目标是否满足全部条件
+func (r Report) OK() bool {
+	return r.Platform == "" && r.Version == "" && len(r.MissingBins) == 0 && len(r.MissingEnv) == 0 && len(r.MissingFiles) == 0
+}
+
+// Problems 返回未满足的条件，每项一行
+func (r Report) Problems() []string {
+	var problems []string
+	if r.Platform != "" {
+		problems = append(problems, r.Platform)
+	}
+	if r.Version != "" {
+		problems = append(problems, r.Version)
+	}
+	if len(r.MissingBins) > 0 {
+		problems = append(problems, i18n.T("prereq.missing_bins")+strings.Join(r.MissingBins, ", "))
+	}
+	if len(r.MissingEnv) > 0 {
+		problems = append(problems, i18n.T("prereq.missing_env")+strings.Join(r.MissingEnv, ", "))
+	}
+	if len(r.MissingFiles) > 0 {
+		problems = append(problems, i18n.T("prereq.missing_files")+strings.Join(r.MissingFiles, ", "))
+	}
+	return problems
+}
+
+// Summary 返回单行的问题摘要
+func (r Report) Summary() string {
+	return strings.Join(r.Problems(), i18n.T("common.clause_sep"))
+}
+
+// Err 在条件不满足时返回列出全部问题的错误
+func (r Report) Err(label string) error {
+	if r.OK() {
+		return nil
+	}
+	return i18n.Errorf("prereq.unavailable", label, strings.Join(r.Problems(), "\n  - "))
+}
+
+// Check 检查执行目标，所有条件都会检查以便一次列出全部缺失项
+func (c *Checker) Check(target config.Target) Report {
+	var report Report
+	if len(target.Platforms) > 0 && !slices.Contains(target.Platforms, c.GOOS) {
+		report.Platform = i18n.T("prereq.platform", strings.Join(target.Platforms, i18n.T("common.list_sep")), c.GOOS)
+	}
+	if required := target.MinAlpenVersion; required != "" && c.Version != devVersion {
+		if cmp, err := config.CompareVersions(c.Version, required); err != nil || cmp < 0 {
+			report.Version = i18n.T("prereq.version", required, c.Version)
+		}
+	}
+	for _, bin := range target.Requires.Bins {
+		if _, err := c.LookPath(bin); err != nil {
+			report.MissingBins = append(report.MissingBins, bin)
+		}
+	}
+	for _, name := range target.Requires.Env {
+		if value, ok := target.Env[name]; ok && value != "" {
+			continue
+		}
+		if value, ok := c.LookupEnv(name); !ok || value == "" {
+			report.MissingEnv = append(report.MissingEnv, name)
+		}
+	}
+	for _, file := range target.Requires.Files {
+		if _, err := c.Stat(c.resolveFile(file, target.WorkDir)); err != nil {
+			report.MissingFiles = append(report.MissingFiles, file)
+		}
+	}
+	return report
+}
+
+// resolveFile 展开 ~ 与环境变量，相对路径以 workdir（未配置或含模板时为当前目录）为基准
+func (c *Checker) resolveFile(file string, workDir string) string {
+	path := config.ExpandPath(file)
+	if filepath.IsAbs(path) || workDir == "" || config.HasTemplate(workDir) {
+		return path
+	}
+	return filepath.Join(config.ExpandPath(workDir), path)
+}
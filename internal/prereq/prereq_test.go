@@ -0,0 +1,75 @@
+package prereq
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/alpen/alpen-cli/internal/config"
+)
+
+func fakeChecker(version string) *Checker {
+	return &Checker{
+		Version: version,
+		GOOS:    "linux",
+		LookPath: func(name string) (string, error) {
+			if name == "jq" {
+				return "/usr/bin/jq", nil
+			}
+			return "", errors.New("not found")
+		},
+		LookupEnv: func(name string) (string, bool) {
+			return "", false
+		},
+		Stat: func(path string) (os.FileInfo, error) {
+			if path == "/srv/app/Makefile" {
+				return nil, nil
+			}
+			return nil, os.ErrNotExist
+		},
+	}
+}
+
+func TestCheckListsEveryMissingRequirement(t *testing.T) {
+	target := config.Target{
+		Path:            []string{"deploy"},
+		Platforms:       []string{"darwin"},
+		MinAlpenVersion: "2.0.0",
+		WorkDir:         "/srv/app",
+		Env:             map[string]string{"REGION": "eu"},
+		Requires: config.RequiresSpec{
+			Bins:  []string{"jq", "kubectl"},
+			Env:   []string{"REGION", "KUBECONFIG"},
+			Files: []string{"Makefile", "values.yaml"},
+		},
+	}
+	report := fakeChecker("1.4.2").Check(target)
+	if report.Supported() || report.OK() {
+		t.Fatalf("expected unsupported report, got %+v", report)
+	}
+	err := report.Err(target.Label())
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	for _, want := range []string{"仅支持 darwin（当前 linux）", "需要 alpen >= 2.0.0", "kubectl", "KUBECONFIG", "values.yaml"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %q in %v", want, err)
+		}
+	}
+	for _, unexpected := range []string{"jq", "REGION", "Makefile"} {
+		if strings.Contains(err.Error(), unexpected) {
+			t.Fatalf("did not expect %q in %v", unexpected, err)
+		}
+	}
+}
+
+func TestCheckSkipsVersionForDevBuilds(t *testing.T) {
+	target := config.Target{Platforms: []string{"linux"}, MinAlpenVersion: "9.0.0"}
+	if report := fakeChecker("dev").Check(target); !report.OK() {
+		t.Fatalf("expected dev build to pass, got %+v", report)
+	}
+	if report := fakeChecker("v9.1.0-rc1").Check(target); !report.OK() {
+		t.Fatalf("expected newer version to pass, got %+v", report)
+	}
+}
@@ -0,0 +1,191 @@
+package redact
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/alpen/alpen-cli/internal/config"
+	"github.com/alpen/alpen-cli/internal/i18n"
+)
+
+// Mask 为遮蔽后的占位文本
+const Mask = "***"
+
+// minValueLength 以下的值不在自由文本中遮蔽，避免 "1"、"on" 之类的值误伤输出
+const minValueLength = 4
+
+var sensitiveMarkers = []string{"password", "passwd", "secret", "token", "apikey", "api_key", "api-key", "credential", "private_key", "private-key"}
+
+// Options 描述额外的遮蔽规则
+type Options struct {
+	// Keys 为额外视为敏感的环境变量名通配符，例如 "*_CRED"、"DATABASE_URL"
+	Keys []string
+	// Patterns 为需要在输出中遮蔽的正则表达式
+	Patterns []string
+}
+
+// Redactor 遮蔽敏感环境变量的值、已声明的密钥以及匹配正则的文本
+type Redactor struct {
+	keys     []string
+	patterns []*regexp.Regexp
+	values   []string
+}
+
+// New 按配置创建遮蔽器
+func New(opts Options) (*Redactor, error) {
+	r := &Redactor{}
+	for _, key := range opts.Keys {
+		key = strings.ToUpper(strings.TrimSpace(key))
+		if key == "" {
+			continue
+		}
+		if _, err := path.Match(key, ""); err != nil {
+			return nil, i18n.Errorf("redact.invalid_key", key, err)
+		}
+		r.keys = append(r.keys, key)
+	}
+	for _, pattern := range opts.Patterns {
+		if strings.TrimSpace(pattern) == "" {
+			continue
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, i18n.Errorf("redact.invalid_pattern", pattern, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+// WithValues 返回额外遮蔽指定值的副本
+func (r *Redactor) WithValues(values ...string) *Redactor {
+	clone := &Redactor{}
+	if r != nil {
+		clone.keys = r.keys
+		clone.patterns = r.patterns
+		clone.values = append(clone.values, r.values...)
+	}
+	seen := map[string]struct{}{}
+	for _, v := range clone.values {
+		seen[v] = struct{}{}
+	}
+	for _, v := range values {
+		if len(v) < minValueLength {
+			continue
+		}
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		clone.values = append(clone.values, v)
+	}
+	// 先替换较长的值，避免包含关系导致残留
+	sort.SliceStable(clone.values, func(i, j int) bool { return len(clone.values[i]) > len(clone.values[j]) })
+	return clone
+}
+
+// WithEnv 返回额外遮蔽 env 中敏感变量值的副本
+func (r *Redactor) WithEnv(env map[string]string) *Redactor {
+	var values []string
+	for key, value := range env {
+		if r.IsSensitiveKey(key) {
+			values = append(values, value)
+		}
+	}
+	return r.WithValues(values...)
+}
+
+// IsSensitiveKey 判断变量名或参数名是否敏感
+func (r *Redactor) IsSensitiveKey(name string) bool {
+	if IsSensitiveKey(name) {
+		return true
+	}
+	if r == nil {
+		return false
+	}
+	upper := strings.ToUpper(strings.TrimLeft(name, "-"))
+	for _, pattern := range r.keys {
+		if ok, _ := path.Match(pattern, upper); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// String 遮蔽文本中出现的已知敏感值与匹配正则的内容
+func (r *Redactor) String(s string) string {
+	if r == nil || s == "" {
+		return s
+	}
+	for _, value := range r.values {
+		s = strings.ReplaceAll(s, value, Mask)
+	}
+	for _, re := range r.patterns {
+		s = re.ReplaceAllString(s, Mask)
+	}
+	return s
+}
+
+// Env 返回遮蔽后的环境变量副本，敏感变量的值整体替换为 Mask，secret: 引用原样保留
+func (r *Redactor) Env(env map[string]string) map[string]string {
+	if env == nil {
+		return nil
+	}
+	result := make(map[string]string, len(env))
+	for key, value := range env {
+		if _, ok := config.ParseSecretRef(value); ok {
+			result[key] = value
+			continue
+		}
+		if value != "" && r.IsSensitiveKey(key) {
+			result[key] = Mask
+			continue
+		}
+		result[key] = r.String(value)
+	}
+	return result
+}
+
+// Args 遮蔽形如 --token=xxx、--password xxx、API_KEY=xxx 的参数值及其中出现的已知敏感值
+func (r *Redactor) Args(args []string) []string {
+	if len(args) == 0 {
+		return nil
+	}
+	result := make([]string, len(args))
+	maskNext := false
+	for i, arg := range args {
+		if maskNext {
+			result[i] = Mask
+			maskNext = false
+			continue
+		}
+		if key, _, ok := strings.Cut(arg, "="); ok && r.IsSensitiveKey(key) {
+			result[i] = key + "=" + Mask
+			continue
+		}
+		if strings.HasPrefix(arg, "-") && r.IsSensitiveKey(arg) {
+			maskNext = true
+		}
+		result[i] = r.String(arg)
+	}
+	return result
+}
+
+// Args 使用默认规则遮蔽参数
+func Args(args []string) []string {
+	var r *Redactor
+	return r.Args(args)
+}
+
+// IsSensitiveKey 按默认规则判断名称是否敏感：包含 password、secret、token 等字样或以 _KEY 结尾
+func IsSensitiveKey(name string) bool {
+	lower := strings.ToLower(strings.TrimLeft(name, "-"))
+	for _, marker := range sensitiveMarkers {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return strings.HasSuffix(lower, "_key") || strings.HasSuffix(lower, "-key")
+}
@@ -0,0 +1,52 @@
+package redact
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestArgsMasksSensitiveFlags(t *testing.T) {
+	got := Args([]string{"--password=hunter2", "API_KEY=xyz", "--name", "demo", "--secret", "s3"})
+	want := []string{"--password=***", "API_KEY=***", "--name", "demo", "--secret", "***"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Fatalf("unexpected redaction: %v", got)
+	}
+}
+
+func TestRedactorMasksEnvValuesSecretsAndPatterns(t *testing.T) {
+	r, err := New(Options{Keys: []string{"*_DSN"}, Patterns: []string{`ghp_[A-Za-z0-9]{8}`}})
+	if err != nil {
+		t.Fatalf("new redactor failed: %v", err)
+	}
+	env := map[string]string{
+		"GITHUB_TOKEN": "tok-123456",
+		"DB_PASSWORD":  "pa55word",
+		"DATABASE_DSN": "postgres://u:p@h/db",
+		"MODE":         "release",
+		"DEPLOY_KEY":   "secret:deploy/key",
+		"SHORT_TOKEN":  "ab",
+	}
+	masker := r.WithEnv(env).WithValues("vault-value")
+
+	masked := masker.Env(env)
+	if masked["GITHUB_TOKEN"] != Mask || masked["DB_PASSWORD"] != Mask || masked["DATABASE_DSN"] != Mask {
+		t.Fatalf("sensitive env not masked: %+v", masked)
+	}
+	if masked["MODE"] != "release" || masked["DEPLOY_KEY"] != "secret:deploy/key" {
+		t.Fatalf("non-sensitive env or secret reference altered: %+v", masked)
+	}
+
+	output := "token=tok-123456 pw=pa55word vault=vault-value gh=ghp_abcdEFGH mode=release ab"
+	got := masker.String(output)
+	want := "token=*** pw=*** vault=*** gh=*** mode=release ab"
+	if got != want {
+		t.Fatalf("unexpected output redaction:\n got %s\nwant %s", got, want)
+	}
+	if r.String("tok-123456") != "tok-123456" {
+		t.Fatalf("WithEnv should not mutate the base redactor")
+	}
+
+	if _, err := New(Options{Patterns: []string{"("}}); err == nil {
+		t.Fatalf("expected invalid regex to be rejected")
+	}
+}
@@ -3,13 +3,13 @@ package scripts
 import (
 	"bufio"
 	"errors"
-	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/alpen/alpen-cli/internal/config"
+	"github.com/alpen/alpen-cli/internal/i18n"
 )
 
 // ResolveRoot 返回 ~/.alpen/config/scripts 目录
@@ -48,15 +48,15 @@ func VerifyExecutable(path string) error {
 	info, err := os.Stat(path)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			return fmt.Errorf("脚本 %s 不存在", path)
+			return i18n.Errorf("scripts.not_found", path)
 		}
 		return err
 	}
 	if info.IsDir() {
-		return fmt.Errorf("脚本 %s 指向目录", path)
+		return i18n.Errorf("scripts.is_dir", path)
 	}
 	if info.Mode()&0o111 == 0 {
-		return fmt.Errorf("脚本 %s 缺少可执行权限", path)
+		return i18n.Errorf("scripts.not_executable", path)
 	}
 	f, err := os.Open(path)
 	if err != nil {
@@ -69,7 +69,7 @@ func VerifyExecutable(path string) error {
 		return err
 	}
 	if len(head) < 2 || head[0] != '#' || head[1] != '!' {
-		return fmt.Errorf("脚本 %s 缺少 Shebang", path)
+		return i18n.Errorf("scripts.no_shebang", path)
 	}
 	return nil
 }
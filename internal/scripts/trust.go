@@ -0,0 +1,282 @@
+package scripts
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kballard/go-shellquote"
+
+	"github.com/alpen/alpen-cli/internal/config"
+	"github.com/alpen/alpen-cli/internal/fsutil"
+	"github.com/alpen/alpen-cli/internal/i18n"
+)
+
+// TrustFileName 为脚本信任记录在状态目录下的文件名
+const TrustFileName = "trust.json"
+
+// 脚本变更时的处理策略
+const (
+	TrustPolicyWarn  = "warn"
+	TrustPolicyBlock = "block"
+	TrustPolicyOff   = "off"
+)
+
+// TrustStatus 表示脚本与信任记录的比对结果
+type TrustStatus string
+
+const (
+	TrustStatusTrusted   TrustStatus = "trusted"
+	TrustStatusUntrusted TrustStatus = "untrusted"
+	TrustStatusChanged   TrustStatus = "changed"
+	TrustStatusMissing   TrustStatus = "missing"
+)
+
+// Label 返回状态在当前语言下的描述
+func (s TrustStatus) Label() string {
+	switch s {
+	case TrustStatusTrusted:
+		return i18n.T("scripts.trust.trusted")
+	case TrustStatusUntrusted:
+		return i18n.T("scripts.trust.untrusted")
+	case TrustStatusChanged:
+		return i18n.T("scripts.trust.changed")
+	case TrustStatusMissing:
+		return i18n.T("scripts.trust.missing")
+	default:
+		return string(s)
+	}
+}
+
+// TrustRecord 为单个脚本的信任记录
+type TrustRecord struct {
+	SHA256    string    `json:"sha256"`
+	TrustedAt time.Time `json:"trusted_at"`
+}
+
+// TrustStore 记录脚本绝对路径与其被信任时的 SHA-256
+type TrustStore struct {
+	Scripts map[string]TrustRecord `json:"scripts"`
+}
+
+// TrustStorePath 返回 ~/.alpen/state/trust.json 的绝对路径
+func TrustStorePath() (string, error) {
+	return config.StatePath(TrustFileName)
+}
+
+// NewTrustChecker 按策略创建校验器，校验范围为 ~/.alpen/config
+func NewTrustChecker(policy string) (*TrustChecker, error) {
+	storePath, err := TrustStorePath()
+	if err != nil {
+		return nil, err
+	}
+	root, err := config.ConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	return &TrustChecker{StorePath: storePath, Policy: policy, Root: filepath.Clean(root)}, nil
+}
+
+// LoadTrustStore 读取信任记录，文件不存在时返回空记录
+func LoadTrustStore(path string) (*TrustStore, error) {
+	store := &TrustStore{Scripts: map[string]TrustRecord{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, store); err != nil {
+		return nil, i18n.Errorf("scripts.trust_parse_failed", path, err)
+	}
+	if store.Scripts == nil {
+		store.Scripts = map[string]TrustRecord{}
+	}
+	return store, nil
+}
+
+// Save 原子写入信任记录
+func (s *TrustStore) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(path, data, 0o600)
+}
+
+// Check 计算脚本当前哈希并与记录比对
+func (s *TrustStore) Check(path string) (TrustStatus, string, error) {
+	hash, err := HashFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return TrustStatusMissing, "", nil
+	}
+	if err != nil {
+		return "", "", err
+	}
+	record, ok := s.Scripts[filepath.Clean(path)]
+	switch {
+	case !ok:
+		return TrustStatusUntrusted, hash, nil
+	case record.SHA256 != hash:
+		return TrustStatusChanged, hash, nil
+	default:
+		return TrustStatusTrusted, hash, nil
+	}
+}
+
+// Trust 记录脚本的当前哈希
+func (s *TrustStore) Trust(path string, hash string) {
+	s.Scripts[filepath.Clean(path)] = TrustRecord{SHA256: hash, TrustedAt: time.Now().UTC()}
+}
+
+// HashFile 返回文件内容的 SHA-256
+func HashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// TrustChecker 在执行前按策略校验 ~/.alpen/config 下的脚本，首次出现的脚本自动登记（TOFU）
+type TrustChecker struct {
+	StorePath string
+	Policy    string
+	// Root 为需要校验的目录，默认 ~/.alpen/config（包含脚本仓库与 .conf 模块）
+	Root string
+}
+
+// Verify 校验脚本哈希：未登记的脚本自动信任并返回 TrustStatusUntrusted；
+// 哈希变化时 block 策略返回错误，warn 策略返回 TrustStatusChanged 由调用方提示
+func (c *TrustChecker) Verify(path string) (TrustStatus, error) {
+	if c == nil || normalizePolicy(c.Policy) == TrustPolicyOff {
+		return "", nil
+	}
+	if c.Root == "" || !IsUnderRoot(path, c.Root) {
+		return "", nil
+	}
+	unlock, err := fsutil.AcquireLock(c.StorePath + ".lock")
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	store, err := LoadTrustStore(c.StorePath)
+	if err != nil {
+		return "", err
+	}
+	status, hash, err := store.Check(path)
+	if err != nil {
+		return "", err
+	}
+	switch status {
+	case TrustStatusUntrusted:
+		store.Trust(path, hash)
+		if err := store.Save(c.StorePath); err != nil {
+			return "", err
+		}
+	case TrustStatusChanged:
+		if normalizePolicy(c.Policy) == TrustPolicyBlock {
+			return status, i18n.Errorf("scripts.changed", path, path)
+		}
+	}
+	return status, nil
+}
+
+// Status 只读地返回脚本的信任状态，不登记新脚本，未启用或不在校验范围内时返回空字符串
+func (c *TrustChecker) Status(path string) (TrustStatus, error) {
+	if c == nil || normalizePolicy(c.Policy) == TrustPolicyOff {
+		return "", nil
+	}
+	if c.Root == "" || !IsUnderRoot(path, c.Root) {
+		return "", nil
+	}
+	store, err := LoadTrustStore(c.StorePath)
+	if err != nil {
+		return "", err
+	}
+	status, _, err := store.Check(path)
+	return status, err
+}
+
+func normalizePolicy(policy string) string {
+	switch strings.ToLower(strings.TrimSpace(policy)) {
+	case TrustPolicyBlock:
+		return TrustPolicyBlock
+	case TrustPolicyOff:
+		return TrustPolicyOff
+	default:
+		return TrustPolicyWarn
+	}
+}
+
+// ResolveCommandScript 解析命令字符串的首个 token，返回其指向的脚本路径
+func ResolveCommandScript(command string, workingDir string) (string, bool, error) {
+	tokens, err := shellquote.Split(command)
+	if err != nil {
+		return "", false, i18n.Errorf("scripts.command_parse_failed", command, err)
+	}
+	if len(tokens) == 0 {
+		return "", false, nil
+	}
+	path, relevant, err := ResolveCommandTarget(os.ExpandEnv(tokens[0]), workingDir)
+	if err != nil || !relevant {
+		return "", false, err
+	}
+	return filepath.Clean(path), true, nil
+}
+
+// ReferencedScript 描述配置中某条命令引用的脚本
+type ReferencedScript struct {
+	Path     string
+	Commands []string
+}
+
+// ReferencedScripts 汇总配置中所有命令引用的脚本路径，按路径排序
+func ReferencedScripts(cfg *config.Config) []ReferencedScript {
+	if cfg == nil {
+		return nil
+	}
+	byPath := map[string]*ReferencedScript{}
+	add := func(target config.Target) {
+		if target.Command == "" {
+			return
+		}
+		path, ok, err := ResolveCommandScript(target.Command, "")
+		if err != nil || !ok {
+			return
+		}
+		ref, exists := byPath[path]
+		if !exists {
+			ref = &ReferencedScript{Path: path}
+			byPath[path] = ref
+		}
+		ref.Commands = append(ref.Commands, target.Label())
+	}
+	for _, name := range cfg.SortedCommandNames() {
+		spec := cfg.Commands[name]
+		add(spec.Target(name))
+		for _, actionName := range spec.SortedActionNames() {
+			add(spec.ActionTarget(name, actionName, spec.Actions[actionName]))
+		}
+	}
+	result := make([]ReferencedScript, 0, len(byPath))
+	for _, ref := range byPath {
+		result = append(result, *ref)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Path < result[j].Path })
+	return result
+}
@@ -0,0 +1,97 @@
+package scripts
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alpen/alpen-cli/internal/config"
+)
+
+func writeScript(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir failed: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o755); err != nil {
+		t.Fatalf("write script failed: %v", err)
+	}
+}
+
+func TestTrustCheckerRecordsOnFirstUseAndDetectsChanges(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "config")
+	script := filepath.Join(root, "scripts", "deploy.sh")
+	writeScript(t, script, "#!/bin/sh\necho v1\n")
+
+	checker := &TrustChecker{StorePath: filepath.Join(dir, "state", TrustFileName), Policy: TrustPolicyWarn, Root: root}
+	status, err := checker.Verify(script)
+	if err != nil || status != TrustStatusUntrusted {
+		t.Fatalf("expected first use to be recorded, got %q err=%v", status, err)
+	}
+	if status, err := checker.Verify(script); err != nil || status != TrustStatusTrusted {
+		t.Fatalf("expected trusted on second run, got %q err=%v", status, err)
+	}
+
+	writeScript(t, script, "#!/bin/sh\necho v2\n")
+	if status, err := checker.Verify(script); err != nil || status != TrustStatusChanged {
+		t.Fatalf("warn policy should pass with changed status, got %q err=%v", status, err)
+	}
+	checker.Policy = TrustPolicyBlock
+	if _, err := checker.Verify(script); err == nil {
+		t.Fatalf("block policy should refuse changed script")
+	}
+	checker.Policy = TrustPolicyOff
+	if _, err := checker.Verify(script); err != nil {
+		t.Fatalf("off policy should skip verification: %v", err)
+	}
+
+	store, err := LoadTrustStore(checker.StorePath)
+	if err != nil {
+		t.Fatalf("load store failed: %v", err)
+	}
+	hash, err := HashFile(script)
+	if err != nil {
+		t.Fatalf("hash failed: %v", err)
+	}
+	store.Trust(script, hash)
+	if err := store.Save(checker.StorePath); err != nil {
+		t.Fatalf("save store failed: %v", err)
+	}
+	checker.Policy = TrustPolicyBlock
+	if status, err := checker.Verify(script); err != nil || status != TrustStatusTrusted {
+		t.Fatalf("expected re-trusted script to pass, got %q err=%v", status, err)
+	}
+}
+
+func TestTrustCheckerIgnoresScriptsOutsideRoot(t *testing.T) {
+	dir := t.TempDir()
+	outside := filepath.Join(dir, "bin", "tool")
+	writeScript(t, outside, "#!/bin/sh\n")
+	checker := &TrustChecker{StorePath: filepath.Join(dir, TrustFileName), Policy: TrustPolicyBlock, Root: filepath.Join(dir, "config")}
+	if status, err := checker.Verify(outside); err != nil || status != "" {
+		t.Fatalf("expected outside script to be skipped, got %q err=%v", status, err)
+	}
+	if _, err := os.Stat(checker.StorePath); !os.IsNotExist(err) {
+		t.Fatalf("store should not be written for skipped scripts")
+	}
+}
+
+func TestReferencedScriptsCollectsCommandsAndActions(t *testing.T) {
+	cfg := &config.Config{Commands: map[string]config.CommandSpec{
+		"deploy": {
+			Command: "/opt/alpen/deploy.sh --fast",
+			Actions: map[string]config.ActionSpec{
+				"rollback": {Command: "/opt/alpen/deploy.sh --rollback"},
+				"status":   {Command: "kubectl get pods"},
+			},
+		},
+	}}
+	refs := ReferencedScripts(cfg)
+	if len(refs) != 1 || refs[0].Path != "/opt/alpen/deploy.sh" {
+		t.Fatalf("unexpected refs: %+v", refs)
+	}
+	if len(refs[0].Commands) != 2 || refs[0].Commands[0] != "deploy" || refs[0].Commands[1] != "deploy rollback" {
+		t.Fatalf("unexpected commands: %+v", refs[0].Commands)
+	}
+}
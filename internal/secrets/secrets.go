@@ -0,0 +1,231 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"os"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/alpen/alpen-cli/internal/config"
+	"github.com/alpen/alpen-cli/internal/fsutil"
+	"github.com/alpen/alpen-cli/internal/i18n"
+)
+
+// DefaultFileName 为加密密钥库在状态目录下的文件名
+const DefaultFileName = "secrets.enc"
+
+const (
+	fileVersion       = 1
+	kdfName           = "pbkdf2-sha256"
+	defaultIterations = 310000
+	keyLength         = 32
+	saltLength        = 16
+)
+
+// ErrWrongKey 表示口令或密钥文件无法解密现有密钥库
+var ErrWrongKey = i18n.NewError("secrets.wrong_key")
+
+// ErrVaultNotFound 表示密钥库尚未创建
+var ErrVaultNotFound = i18n.NewError("secrets.vault_missing")
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+(/[A-Za-z0-9_.-]+)*$`)
+
+// KeySource 返回用于派生加密密钥的口令或密钥文件内容，create 表示正在创建新的密钥库
+type KeySource func(create bool) ([]byte, error)
+
+// encryptedFile 为密钥库在磁盘上的格式，明文为 name -> value 的 JSON
+type encryptedFile struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// Vault 为解密后的密钥库
+type Vault struct {
+	path       string
+	key        []byte
+	salt       []byte
+	iterations int
+	values     map[string]string
+}
+
+// DefaultPath 返回 ~/.alpen/state/secrets.enc 的绝对路径
+func DefaultPath() (string, error) {
+	return config.StatePath(DefaultFileName)
+}
+
+// OpenExisting 读取并解密已存在的密钥库，文件不存在时返回 ErrVaultNotFound，不会询问新口令
+func OpenExisting(path string, source KeySource) (*Vault, error) {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil, ErrVaultNotFound
+	}
+	return Open(path, source)
+}
+
+// Open 读取并解密密钥库，文件不存在时以新口令创建空库（调用 Save 后落盘）
+func Open(path string, source KeySource) (*Vault, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		material, err := source(true)
+		if err != nil {
+			return nil, err
+		}
+		salt := make([]byte, saltLength)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, err
+		}
+		return &Vault{
+			path:       path,
+			key:        pbkdf2SHA256(material, salt, defaultIterations, keyLength),
+			salt:       salt,
+			iterations: defaultIterations,
+			values:     map[string]string{},
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var file encryptedFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, i18n.Errorf("secrets.parse_failed", path, err)
+	}
+	if file.Version != fileVersion || file.KDF != kdfName || file.Iterations <= 0 {
+		return nil, i18n.Errorf("secrets.format_unsupported", path)
+	}
+	material, err := source(false)
+	if err != nil {
+		return nil, err
+	}
+	key := pbkdf2SHA256(material, file.Salt, file.Iterations, keyLength)
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, file.Nonce, file.Ciphertext, nil)
+	if err != nil {
+		return nil, ErrWrongKey
+	}
+	values := map[string]string{}
+	if err := json.Unmarshal(plaintext, &values); err != nil {
+		return nil, i18n.Errorf("secrets.content_parse_failed", err)
+	}
+	return &Vault{path: path, key: key, salt: file.Salt, iterations: file.Iterations, values: values}, nil
+}
+
+// Save 使用新的随机 nonce 重新加密并原子写入密钥库
+func (v *Vault) Save() error {
+	plaintext, err := json.Marshal(v.values)
+	if err != nil {
+		return err
+	}
+	aead, err := newAEAD(v.key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(encryptedFile{
+		Version:    fileVersion,
+		KDF:        kdfName,
+		Iterations: v.iterations,
+		Salt:       v.salt,
+		Nonce:      nonce,
+		Ciphertext: aead.Seal(nil, nonce, plaintext, nil),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(v.path, data, 0o600)
+}
+
+// Get 返回密钥值
+func (v *Vault) Get(name string) (string, bool) {
+	value, ok := v.values[name]
+	return value, ok
+}
+
+// Set 写入或覆盖密钥
+func (v *Vault) Set(name string, value string) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	v.values[name] = value
+	return nil
+}
+
+// Delete 删除密钥，返回是否存在
+func (v *Vault) Delete(name string) bool {
+	if _, ok := v.values[name]; !ok {
+		return false
+	}
+	delete(v.values, name)
+	return true
+}
+
+// Names 返回排序后的密钥名称
+func (v *Vault) Names() []string {
+	names := make([]string, 0, len(v.values))
+	for name := range v.values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidateName 校验密钥名称，允许以 / 分组，例如 github/token
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return i18n.Errorf("secrets.name_invalid", name)
+	}
+	return nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Resolver 在首次需要密钥时才解锁密钥库，之后复用解密结果
+type Resolver struct {
+	open  func() (*Vault, error)
+	once  sync.Once
+	vault *Vault
+	err   error
+}
+
+// NewResolver 创建延迟解锁的密钥解析器
+func NewResolver(open func() (*Vault, error)) *Resolver {
+	return &Resolver{open: open}
+}
+
+// Resolve 返回指定名称的密钥值
+func (r *Resolver) Resolve(name string) (string, error) {
+	r.once.Do(func() {
+		r.vault, r.err = r.open()
+	})
+	if errors.Is(r.err, ErrVaultNotFound) {
+		return "", i18n.Errorf("secrets.vault_missing_for", name, name)
+	}
+	if r.err != nil {
+		return "", i18n.Errorf("secrets.unlock_failed", r.err)
+	}
+	value, ok := r.vault.Get(name)
+	if !ok {
+		return "", i18n.Errorf("secrets.not_found", name, name)
+	}
+	return value, nil
+}
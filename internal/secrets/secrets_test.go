@@ -0,0 +1,95 @@
+package secrets
+
+import (
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPBKDF2SHA256KnownVector(t *testing.T) {
+	// RFC 7914 第 11 节测试向量
+	got := hex.EncodeToString(pbkdf2SHA256([]byte("passwd"), []byte("salt"), 1, 64))
+	want := "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"
+	if got != want {
+		t.Fatalf("unexpected derived key:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestVaultRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultFileName)
+	passphrase := func(value string) KeySource {
+		return func(bool) ([]byte, error) { return []byte(value), nil }
+	}
+
+	asked := false
+	if _, err := OpenExisting(path, func(bool) ([]byte, error) { asked = true; return nil, nil }); !errors.Is(err, ErrVaultNotFound) || asked {
+		t.Fatalf("expected ErrVaultNotFound without asking for a passphrase, got %v", err)
+	}
+
+	vault, err := Open(path, passphrase("correct horse"))
+	if err != nil {
+		t.Fatalf("open new vault failed: %v", err)
+	}
+	if err := vault.Set("github/token", "ghp_secret"); err != nil {
+		t.Fatalf("set failed: %v", err)
+	}
+	if err := vault.Set("bad name", "x"); err == nil {
+		t.Fatalf("expected invalid name to be rejected")
+	}
+	if err := vault.Save(); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read vault failed: %v", err)
+	}
+	if strings.Contains(string(data), "ghp_secret") || strings.Contains(string(data), "github/token") {
+		t.Fatalf("vault file leaks plaintext: %s", data)
+	}
+
+	reopened, err := Open(path, passphrase("correct horse"))
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	if value, ok := reopened.Get("github/token"); !ok || value != "ghp_secret" {
+		t.Fatalf("unexpected value %q ok=%v", value, ok)
+	}
+	if _, err := Open(path, passphrase("wrong")); !errors.Is(err, ErrWrongKey) {
+		t.Fatalf("expected ErrWrongKey, got %v", err)
+	}
+
+	resolver := NewResolver(func() (*Vault, error) { return reopened, nil })
+	if value, err := resolver.Resolve("github/token"); err != nil || value != "ghp_secret" {
+		t.Fatalf("resolve failed: %q %v", value, err)
+	}
+	if _, err := resolver.Resolve("missing"); err == nil {
+		t.Fatalf("expected missing secret error")
+	}
+	missing := NewResolver(func() (*Vault, error) { return OpenExisting(path+".missing", passphrase("x")) })
+	if _, err := missing.Resolve("github/token"); err == nil || !strings.Contains(err.Error(), "alpen secret set github/token") {
+		t.Fatalf("expected a hint to create the vault, got %v", err)
+	}
+}
+
+func TestUnlockerPrefersKeyFile(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "alpen.key")
+	if err := os.WriteFile(keyFile, []byte("file-material\n"), 0o600); err != nil {
+		t.Fatalf("write key file failed: %v", err)
+	}
+	t.Setenv(PassphraseEnv, "env-material")
+	material, err := Unlocker{KeyFile: keyFile}.Key(false)
+	if err != nil || string(material) != "file-material" {
+		t.Fatalf("expected key file material, got %q %v", material, err)
+	}
+	material, err = Unlocker{}.Key(false)
+	if err != nil || string(material) != "env-material" {
+		t.Fatalf("expected env material, got %q %v", material, err)
+	}
+	t.Setenv(PassphraseEnv, "")
+	if _, err := (Unlocker{Input: strings.NewReader("")}).Key(false); err == nil {
+		t.Fatalf("expected error without terminal")
+	}
+}
@@ -0,0 +1,98 @@
+package secrets
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/alpen/alpen-cli/internal/config"
+	"github.com/alpen/alpen-cli/internal/i18n"
+)
+
+// PassphraseEnv 为非交互环境下提供口令的环境变量
+const PassphraseEnv = "ALPEN_SECRET_PASSPHRASE"
+
+// OpenDefault 使用默认路径打开密钥库，keyFile 为空时通过环境变量或终端获取口令
+func OpenDefault(keyFile string, input io.Reader, output io.Writer) (*Vault, error) {
+	path, err := DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	unlocker := Unlocker{KeyFile: config.ExpandPath(keyFile), Input: input, Output: output}
+	return Open(path, unlocker.Key)
+}
+
+// OpenDefaultExisting 与 OpenDefault 相同，但密钥库不存在时返回 ErrVaultNotFound，供执行命令时解析 secret 引用
+func OpenDefaultExisting(keyFile string, input io.Reader, output io.Writer) (*Vault, error) {
+	path, err := DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	unlocker := Unlocker{KeyFile: config.ExpandPath(keyFile), Input: input, Output: output}
+	return OpenExisting(path, unlocker.Key)
+}
+
+// Unlocker 依次尝试密钥文件、环境变量与终端输入获取口令
+type Unlocker struct {
+	KeyFile string
+	Input   io.Reader
+	Output  io.Writer
+}
+
+// Key 实现 KeySource
+func (u Unlocker) Key(create bool) ([]byte, error) {
+	if path := strings.TrimSpace(u.KeyFile); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, i18n.Errorf("secrets.key_file_read_failed", path, err)
+		}
+		data = bytes.TrimSpace(data)
+		if len(data) == 0 {
+			return nil, i18n.Errorf("secrets.key_file_empty", path)
+		}
+		return data, nil
+	}
+	if passphrase := os.Getenv(PassphraseEnv); passphrase != "" {
+		return []byte(passphrase), nil
+	}
+	file, ok := u.Input.(*os.File)
+	if !ok || !term.IsTerminal(int(file.Fd())) {
+		return nil, i18n.Errorf("secrets.no_terminal", PassphraseEnv)
+	}
+
+	passphrase, err := u.readPassphrase(file, i18n.T("secrets.passphrase_prompt"))
+	if err != nil {
+		return nil, err
+	}
+	if create {
+		confirm, err := u.readPassphrase(file, i18n.T("secrets.passphrase_confirm"))
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(passphrase, confirm) {
+			return nil, i18n.Errorf("secrets.passphrase_mismatch")
+		}
+	}
+	return passphrase, nil
+}
+
+func (u Unlocker) readPassphrase(file *os.File, prompt string) ([]byte, error) {
+	output := u.Output
+	if output == nil {
+		output = os.Stderr
+	}
+	fmt.Fprint(output, prompt)
+	passphrase, err := term.ReadPassword(int(file.Fd()))
+	fmt.Fprintln(output)
+	if err != nil {
+		return nil, i18n.Errorf("secrets.passphrase_read_failed", err)
+	}
+	if len(passphrase) == 0 {
+		return nil, i18n.Errorf("secrets.passphrase_empty")
+	}
+	return passphrase, nil
+}
@@ -0,0 +1,400 @@
+package templating
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"github.com/kballard/go-shellquote"
+
+	"github.com/alpen/alpen-cli/internal/config"
+	"github.com/alpen/alpen-cli/internal/i18n"
+)
+
+// shTimeout 为 sh 变量单次求值的超时时间
+const shTimeout = 30 * time.Second
+
+// Args 为模板中的 .args，直接输出时按 shell 规则转义并以空格连接
+type Args []string
+
+// String 实现 fmt.Stringer
+func (a Args) String() string {
+	return shellquote.Join(a...)
+}
+
+// Quoted 为 command 与 sh 变量中 .param、.env、.vars 的取值，直接输出时按 shell 规则转义为单个参数，
+// 需要原样插入时使用 {{ raw .param.name }}
+type Quoted string
+
+// String 实现 fmt.Stringer
+func (q Quoted) String() string {
+	return shellquote.Join(string(q))
+}
+
+// funcs 为模板可用的函数
+var funcs = template.FuncMap{"raw": raw}
+
+// raw 返回不经转义的取值，.args 以空格连接
+func raw(value any) string {
+	switch v := value.(type) {
+	case Quoted:
+		return string(v)
+	case Args:
+		return strings.Join(v, " ")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Input 描述一次渲染的运行时输入
+type Input struct {
+	Args   []string
+	Params map[string]string
+	// Env 为 .env 的取值，为空时使用当前进程环境变量
+	Env map[string]string
+	// Preview 为 true 时不执行 sh 变量的命令，以 <sh: 命令> 占位，用于执行计划与执行确认之前的校验
+	Preview bool
+}
+
+// Result 为渲染后的执行内容
+type Result struct {
+	Command string
+	Env     map[string]string
+	WorkDir string
+	// ArgsConsumed 表示 command 中引用了 .args，额外参数不应再追加到命令末尾
+	ArgsConsumed bool
+	// Vars 为实际求值过的变量
+	Vars map[string]string
+}
+
+// Render 使用 text/template 渲染目标的 command、env 与 workdir；
+// vars 仅在被引用时才求值，sh 变量的命令不会无故执行。
+// command 与 sh 变量的命令会交给 shell 执行，其中的 .param、.env、.vars 按 shell 规则转义
+func Render(target config.Target, in Input) (Result, error) {
+	params, err := ResolveParams(target.Params, in.Params)
+	if err != nil {
+		return Result{}, err
+	}
+	env := in.Env
+	if env == nil {
+		env = environMap()
+	}
+	r := &renderer{
+		label:    target.Label(),
+		specs:    target.Vars,
+		resolved: map[string]string{},
+		visiting: map[string]bool{},
+		preview:  in.Preview,
+		pending:  map[string]bool{},
+		env:      env,
+		args:     Args(in.Args),
+		params:   params,
+	}
+
+	result := Result{Env: map[string]string{}}
+	if result.Command, result.ArgsConsumed, err = r.render("command", target.Command, true); err != nil {
+		return Result{}, err
+	}
+	for _, key := range sortedKeys(target.Env) {
+		value, _, err := r.render("env."+key, target.Env[key], false)
+		if err != nil {
+			return Result{}, err
+		}
+		result.Env[key] = value
+	}
+	workDir, _, err := r.render("workdir", target.WorkDir, false)
+	if err != nil {
+		return Result{}, err
+	}
+	result.WorkDir = config.ExpandPath(workDir)
+	result.Vars = r.resolved
+	return result, nil
+}
+
+// ResolveParams 合并参数默认值与传入值，未声明的参数或缺少必填参数时返回错误
+func ResolveParams(specs map[string]config.ParamSpec, provided map[string]string) (map[string]string, error) {
+	params := map[string]string{}
+	for name, value := range provided {
+		if _, ok := specs[name]; !ok {
+			return nil, i18n.Errorf("templating.undeclared_param", name)
+		}
+		params[name] = value
+	}
+	var missing []string
+	for name, spec := range specs {
+		if _, ok := params[name]; ok {
+			continue
+		}
+		if spec.Required() {
+			missing = append(missing, name)
+			continue
+		}
+		params[name] = *spec.Default
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, i18n.Errorf("templating.missing_params", strings.Join(missing, ", "))
+	}
+	return params, nil
+}
+
+// ParseParamFlags 解析 --param name=value 列表
+func ParseParamFlags(values []string) (map[string]string, error) {
+	params := map[string]string{}
+	for _, raw := range values {
+		name, value, ok := strings.Cut(raw, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, i18n.Errorf("templating.param_format", raw)
+		}
+		params[name] = value
+	}
+	return params, nil
+}
+
+type renderer struct {
+	label    string
+	specs    map[string]config.VarSpec
+	resolved map[string]string
+	visiting map[string]bool
+	preview  bool
+	// pending 记录预览时未求值、以占位值代替的 sh 变量
+	pending map[string]bool
+	env     map[string]string
+	args    Args
+	params  map[string]string
+}
+
+// render 渲染单个字段，返回结果以及是否引用了 .args；shell 表示字段会交给 shell 执行
+func (r *renderer) render(field string, text string, shell bool) (string, bool, error) {
+	if !config.HasTemplate(text) {
+		return text, false, nil
+	}
+	tmpl, err := template.New(field).Option("missingkey=error").Funcs(funcs).Parse(text)
+	if err != nil {
+		return "", false, i18n.Errorf("templating.syntax_error", r.label, field, err)
+	}
+	refs := collectRefs(tmpl.Tree.Root)
+	if err := r.resolveAll(refs.vars, refs.allVars); err != nil {
+		return "", false, err
+	}
+	data := map[string]any{"args": r.args, "env": r.env, "param": r.params, "vars": r.resolved}
+	if shell {
+		data["env"], data["param"], data["vars"] = quoteAll(r.env), quoteAll(r.params), r.quotedVars()
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", false, i18n.Errorf("templating.render_failed", r.label, field, err)
+	}
+	return buf.String(), refs.args, nil
+}
+
+func (r *renderer) resolveAll(names []string, all bool) error {
+	if all {
+		names = sortedKeys(r.specs)
+	}
+	for _, name := range names {
+		if _, err := r.resolve(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolve 按需求值变量并缓存，检测变量之间的循环引用
+func (r *renderer) resolve(name string) (string, error) {
+	if value, ok := r.resolved[name]; ok {
+		return value, nil
+	}
+	spec, ok := r.specs[name]
+	if !ok {
+		return "", i18n.Errorf("templating.var_undefined", r.label, name)
+	}
+	if r.visiting[name] {
+		return "", i18n.Errorf("templating.var_cycle", r.label, name)
+	}
+	r.visiting[name] = true
+	defer delete(r.visiting, name)
+
+	var value string
+	switch spec.Source() {
+	case "env":
+		var present bool
+		value, present = r.env[spec.Env]
+		if !present || value == "" {
+			value = spec.Default
+		}
+	case "sh":
+		script, _, err := r.render("vars."+name, spec.Sh, true)
+		if err != nil {
+			return "", err
+		}
+		if r.preview {
+			r.pending[name] = true
+			r.resolved[name] = "<sh: " + script + ">"
+			return r.resolved[name], nil
+		}
+		value, err = runShell(script)
+		if err != nil {
+			return "", i18n.Errorf("templating.var_eval_failed", r.label, name, err)
+		}
+		if value == "" {
+			value = spec.Default
+		}
+	default:
+		rendered, _, err := r.render("vars."+name, spec.Value, false)
+		if err != nil {
+			return "", err
+		}
+		value = rendered
+	}
+	r.resolved[name] = value
+	return value, nil
+}
+
+func runShell(script string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), shTimeout)
+	defer cancel()
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd.exe", "/C", script)
+	} else {
+		cmd = exec.CommandContext(ctx, "/bin/sh", "-c", script)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return strings.TrimRight(stdout.String(), "\r\n"), nil
+}
+
+type templateRefs struct {
+	vars    []string
+	allVars bool
+	args    bool
+}
+
+// collectRefs 遍历模板语法树，找出引用的 .vars.<name> 与 .args
+func collectRefs(root parse.Node) templateRefs {
+	refs := templateRefs{}
+	seen := map[string]bool{}
+	markField := func(ident []string) {
+		switch {
+		case len(ident) == 0:
+		case ident[0] == "args":
+			refs.args = true
+		case ident[0] == "vars" && len(ident) == 1:
+			refs.allVars = true
+		case ident[0] == "vars" && !seen[ident[1]]:
+			seen[ident[1]] = true
+			refs.vars = append(refs.vars, ident[1])
+		}
+	}
+	var walk func(node parse.Node)
+	walk = func(node parse.Node) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, child := range n.Nodes {
+				walk(child)
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe)
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+			for _, cmd := range n.Cmds {
+				walk(cmd)
+			}
+		case *parse.CommandNode:
+			for _, arg := range n.Args {
+				walk(arg)
+			}
+		case *parse.ChainNode:
+			walk(n.Node)
+		case *parse.IfNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.RangeNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.WithNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.FieldNode:
+			markField(n.Ident)
+		case *parse.VariableNode:
+			// $.vars.name 等价于 .vars.name，其他局部变量不涉及数据引用
+			if len(n.Ident) > 1 && n.Ident[0] == "$" {
+				markField(n.Ident[1:])
+			} else if len(n.Ident) == 1 && n.Ident[0] == "$" {
+				refs.allVars = true
+			}
+		case *parse.DotNode:
+			// 以 . 整体传递数据时无法静态判断，按引用全部变量处理
+			refs.allVars = true
+		}
+	}
+	walk(root)
+	return refs
+}
+
+// quoteAll 将取值转换为 Quoted，使其在 shell 命令中按单个参数输出
+func quoteAll(values map[string]string) map[string]Quoted {
+	quoted := make(map[string]Quoted, len(values))
+	for key, value := range values {
+		quoted[key] = Quoted(value)
+	}
+	return quoted
+}
+
+// quotedVars 返回 shell 命令中的 .vars，预览时的占位值不转义，原样展示在执行计划中
+func (r *renderer) quotedVars() map[string]any {
+	vars := make(map[string]any, len(r.resolved))
+	for name, value := range r.resolved {
+		if r.pending[name] {
+			vars[name] = value
+		} else {
+			vars[name] = Quoted(value)
+		}
+	}
+	return vars
+}
+
+func environMap() map[string]string {
+	env := map[string]string{}
+	for _, pair := range os.Environ() {
+		if key, value, ok := strings.Cut(pair, "="); ok {
+			env[key] = value
+		}
+	}
+	return env
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
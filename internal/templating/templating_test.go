@@ -0,0 +1,195 @@
+package templating
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/alpen/alpen-cli/internal/config"
+)
+
+func loadTarget(t *testing.T, content string, path ...string) config.Target {
+	t.Helper()
+	var cfg config.Config
+	if err := yaml.Unmarshal([]byte(content), &cfg); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("validate failed: %v", err)
+	}
+	target, ok := cfg.ResolveTarget(path)
+	if !ok {
+		t.Fatalf("target %v not found", path)
+	}
+	return target
+}
+
+func TestRenderScopesVarsParamsAndArgs(t *testing.T) {
+	target := loadTarget(t, `
+vars:
+  host: example.com
+  user: deploy
+commands:
+  ssh:
+    vars:
+      login: "{{ .vars.user }}@{{ .vars.host }}"
+    params:
+      port: "22"
+      region:
+        description: 部署区域
+    workdir: "/srv/{{ .param.region }}"
+    env:
+      TARGET: "{{ .vars.login }}"
+    actions:
+      run:
+        vars:
+          user: ops
+        command: "ssh -p {{ .param.port }} {{ .vars.login }} {{ .args }}"
+`, "ssh", "run")
+
+	result, err := Render(target, Input{
+		Args:   []string{"uptime", "a b"},
+		Params: map[string]string{"region": "eu"},
+		Env:    map[string]string{},
+	})
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	if result.Command != "ssh -p 22 ops@example.com uptime 'a b'" {
+		t.Fatalf("unexpected command: %s", result.Command)
+	}
+	if !result.ArgsConsumed {
+		t.Fatalf("expected args to be consumed by the template")
+	}
+	if result.Env["TARGET"] != "ops@example.com" || result.WorkDir != "/srv/eu" {
+		t.Fatalf("unexpected env/workdir: %+v %s", result.Env, result.WorkDir)
+	}
+
+	if _, err := Render(target, Input{Env: map[string]string{}}); err == nil || !strings.Contains(err.Error(), "region") {
+		t.Fatalf("expected missing param error, got %v", err)
+	}
+	if _, err := Render(target, Input{Params: map[string]string{"region": "eu", "zone": "a"}, Env: map[string]string{}}); err == nil {
+		t.Fatalf("expected unknown param error")
+	}
+}
+
+func TestRenderEvaluatesShellVarsLazily(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "evaluated")
+	target := loadTarget(t, `
+vars:
+  unused:
+    sh: "touch `+marker+`"
+  rev:
+    sh: "echo abc123"
+  home:
+    env: ALPEN_TEST_HOME
+    default: /fallback
+commands:
+  build:
+    command: "make REV={{ .vars.rev }} HOME_DIR={{ .vars.home }} {{ .env.SHELL_NAME }}"
+`, "build")
+
+	result, err := Render(target, Input{Env: map[string]string{"SHELL_NAME": "zsh"}})
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	if result.Command != "make REV=abc123 HOME_DIR=/fallback zsh" {
+		t.Fatalf("unexpected command: %s", result.Command)
+	}
+	if result.ArgsConsumed {
+		t.Fatalf("args should still be appended when the template does not use them")
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Fatalf("unreferenced sh var should not be evaluated")
+	}
+}
+
+func TestRenderDetectsCyclesAndUndefinedVars(t *testing.T) {
+	target := config.Target{
+		Path:    []string{"loop"},
+		Command: "echo {{ .vars.a }}",
+		Vars: map[string]config.VarSpec{
+			"a": {Value: "{{ .vars.b }}"},
+			"b": {Value: "{{ .vars.a }}"},
+		},
+	}
+	if _, err := Render(target, Input{Env: map[string]string{}}); err == nil || !strings.Contains(err.Error(), "循环引用") {
+		t.Fatalf("expected cycle error, got %v", err)
+	}
+	target.Command = "echo {{ .vars.missing }}"
+	if _, err := Render(target, Input{Env: map[string]string{}}); err == nil || !strings.Contains(err.Error(), "未定义") {
+		t.Fatalf("expected undefined var error, got %v", err)
+	}
+	target.Command = "echo plain $HOME"
+	result, err := Render(target, Input{Env: map[string]string{}})
+	if err != nil || result.Command != "echo plain $HOME" {
+		t.Fatalf("plain commands should pass through untouched: %q %v", result.Command, err)
+	}
+}
+
+func TestRenderQuotesParamsAndEnvInShellCommands(t *testing.T) {
+	dir := t.TempDir()
+	sentinel := filepath.Join(dir, "injected")
+	target := loadTarget(t, `
+commands:
+  deploy:
+    params:
+      tag: latest
+    env:
+      TAG: "{{ .param.tag }}"
+    command: "echo {{ .param.tag }} {{ .env.ALPEN_TEST_VALUE }} > `+filepath.Join(dir, "out")+`"
+`, "deploy")
+
+	payload := "x; touch " + sentinel + " #"
+	result, err := Render(target, Input{
+		Params: map[string]string{"tag": payload},
+		Env:    map[string]string{"ALPEN_TEST_VALUE": "$(touch " + sentinel + ")"},
+	})
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	if output, err := exec.Command("/bin/sh", "-c", result.Command).CombinedOutput(); err != nil {
+		t.Fatalf("run failed: %v %s", err, output)
+	}
+	if _, err := os.Stat(sentinel); !os.IsNotExist(err) {
+		t.Fatalf("param or env value was executed by the shell: %s", result.Command)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "out")); string(data) != payload+" $(touch "+sentinel+")\n" {
+		t.Fatalf("values should reach the command unchanged, got %q", data)
+	}
+	if result.Env["TAG"] != payload {
+		t.Fatalf("env values are not shell commands and must not be quoted, got %q", result.Env["TAG"])
+	}
+
+	target.Command = "echo {{ raw .param.tag }}"
+	if result, err = Render(target, Input{Params: map[string]string{"tag": "a b"}, Env: map[string]string{}}); err != nil || result.Command != "echo a b" {
+		t.Fatalf("raw should insert the value unquoted: %q %v", result.Command, err)
+	}
+}
+
+func TestRenderPreviewDoesNotRunShellVars(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "evaluated")
+	target := loadTarget(t, `
+vars:
+  rev:
+    sh: "touch `+marker+` && echo abc123"
+commands:
+  build:
+    command: "make REV={{ .vars.rev }}"
+`, "build")
+
+	result, err := Render(target, Input{Env: map[string]string{}, Preview: true})
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	if result.Command != "make REV=<sh: touch "+marker+" && echo abc123>" {
+		t.Fatalf("unexpected preview command: %s", result.Command)
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Fatalf("preview must not evaluate sh vars")
+	}
+}
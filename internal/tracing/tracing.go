@@ -0,0 +1,431 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alpen/alpen-cli/internal/i18n"
+)
+
+// TraceparentEnv 为 W3C Trace Context 在脚本环境中使用的变量名
+const TraceparentEnv = "TRACEPARENT"
+
+const (
+	defaultServiceName   = "alpen-cli"
+	exportTimeout        = 5 * time.Second
+	instrumentationScope = "github.com/alpen/alpen-cli"
+)
+
+// Options 描述追踪的导出方式
+type Options struct {
+	ServiceName string
+	// Endpoint 为 OTLP/HTTP traces 接收地址，例如 http://localhost:4318/v1/traces
+	Endpoint string
+	Headers  map[string]string
+	// File 为 JSONL 文件路径，未配置采集端或导出失败时写入
+	File string
+	// Parent 为父进程传入的 traceparent，用于加入已有链路
+	Parent string
+}
+
+// Span 表示一次计时操作
+type Span struct {
+	tracer   *tracer
+	traceID  [16]byte
+	spanID   [8]byte
+	parentID [8]byte
+	name     string
+	start    time.Time
+	end      time.Time
+	mu       sync.Mutex
+	attrs    map[string]interface{}
+	errMsg   string
+	ended    bool
+}
+
+type tracer struct {
+	opts  Options
+	mu    sync.Mutex
+	spans []*Span
+	root  *Span
+}
+
+type spanKey struct{}
+
+var (
+	globalMu sync.RWMutex
+	global   *tracer
+)
+
+// Setup 启用全局追踪并创建进程级根 Span，返回的函数用于结束根 Span 并导出数据
+func Setup(opts Options, rootName string) func() error {
+	t := &tracer{opts: opts}
+	root := t.newSpan(rootName, nil)
+	if traceID, spanID, ok := parseTraceparent(opts.Parent); ok {
+		root.traceID = traceID
+		root.parentID = spanID
+	}
+	t.root = root
+
+	globalMu.Lock()
+	global = t
+	globalMu.Unlock()
+
+	return func() error {
+		root.End()
+		globalMu.Lock()
+		if global == t {
+			global = nil
+		}
+		globalMu.Unlock()
+		return t.export()
+	}
+}
+
+// Enabled 判断当前是否启用了追踪
+func Enabled() bool {
+	globalMu.RLock()
+	defer globalMu.RUnlock()
+	return global != nil
+}
+
+// Root 返回进程级根 Span，未启用追踪时返回 nil
+func Root() *Span {
+	globalMu.RLock()
+	defer globalMu.RUnlock()
+	if global == nil {
+		return nil
+	}
+	return global.root
+}
+
+// Start 创建子 Span；ctx 中没有 Span 时挂在进程根 Span 下，未启用追踪时返回 nil（所有方法均可安全调用）
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	globalMu.RLock()
+	t := global
+	globalMu.RUnlock()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if t == nil {
+		return ctx, nil
+	}
+	parent := FromContext(ctx)
+	if parent == nil {
+		parent = t.root
+	}
+	span := t.newSpan(name, parent)
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// FromContext 返回 ctx 中的当前 Span
+func FromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// Traceparent 返回 ctx 对应 Span 的 W3C traceparent，未启用追踪时返回空字符串
+func Traceparent(ctx context.Context) string {
+	span := FromContext(ctx)
+	if span == nil {
+		span = Root()
+	}
+	if span == nil {
+		return ""
+	}
+	return fmt.Sprintf("00-%s-%s-01", hex.EncodeToString(span.traceID[:]), hex.EncodeToString(span.spanID[:]))
+}
+
+// SetAttr 设置属性，支持 string、bool、int、int64、float64 与 []string
+func (s *Span) SetAttr(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attrs[key] = value
+}
+
+// RecordError 记录错误并将 Span 状态标记为失败
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errMsg = err.Error()
+}
+
+// End 结束 Span，重复调用无副作用
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = time.Now()
+	s.mu.Unlock()
+
+	s.tracer.mu.Lock()
+	s.tracer.spans = append(s.tracer.spans, s)
+	s.tracer.mu.Unlock()
+}
+
+func (t *tracer) newSpan(name string, parent *Span) *Span {
+	span := &Span{
+		tracer: t,
+		name:   name,
+		start:  time.Now(),
+		attrs:  map[string]interface{}{},
+	}
+	_, _ = rand.Read(span.spanID[:])
+	if parent != nil {
+		span.traceID = parent.traceID
+		span.parentID = parent.spanID
+	} else {
+		_, _ = rand.Read(span.traceID[:])
+	}
+	return span
+}
+
+// export 优先发送到 OTLP 采集端，未配置或发送失败时写入本地 JSONL 文件
+func (t *tracer) export() error {
+	t.mu.Lock()
+	spans := append([]*Span(nil), t.spans...)
+	t.mu.Unlock()
+	if len(spans) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(t.buildRequest(spans))
+	if err != nil {
+		return err
+	}
+	var sendErr error
+	if endpoint := strings.TrimSpace(t.opts.Endpoint); endpoint != "" {
+		if sendErr = t.post(endpoint, body); sendErr == nil {
+			return nil
+		}
+	}
+	if file := strings.TrimSpace(t.opts.File); file != "" {
+		if err := appendLine(file, body); err != nil {
+			return err
+		}
+		return nil
+	}
+	return sendErr
+}
+
+func (t *tracer) post(endpoint string, body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range t.opts.Headers {
+		req.Header.Set(key, os.ExpandEnv(value))
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return i18n.Errorf("tracing.collector_status", resp.Status)
+	}
+	return nil
+}
+
+func appendLine(path string, line []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// 以下结构对应 OTLP/HTTP JSON 编码的 ExportTraceServiceRequest
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue *string         `json:"stringValue,omitempty"`
+	BoolValue   *bool           `json:"boolValue,omitempty"`
+	IntValue    *string         `json:"intValue,omitempty"`
+	DoubleValue *float64        `json:"doubleValue,omitempty"`
+	ArrayValue  *otlpArrayValue `json:"arrayValue,omitempty"`
+}
+
+type otlpArrayValue struct {
+	Values []otlpValue `json:"values"`
+}
+
+const (
+	spanKindInternal = 1
+	statusOK         = 1
+	statusError      = 2
+)
+
+func (t *tracer) buildRequest(spans []*Span) otlpRequest {
+	serviceName := strings.TrimSpace(t.opts.ServiceName)
+	if serviceName == "" {
+		serviceName = defaultServiceName
+	}
+	converted := make([]otlpSpan, 0, len(spans))
+	for _, span := range spans {
+		converted = append(converted, span.toOTLP())
+	}
+	return otlpRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{Attributes: []otlpKeyValue{
+				{Key: "service.name", Value: toOTLPValue(serviceName)},
+			}},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: instrumentationScope},
+				Spans: converted,
+			}},
+		}},
+	}
+}
+
+func (s *Span) toOTLP() otlpSpan {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := otlpSpan{
+		TraceID:           hex.EncodeToString(s.traceID[:]),
+		SpanID:            hex.EncodeToString(s.spanID[:]),
+		Name:              s.name,
+		Kind:              spanKindInternal,
+		StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+		Status:            otlpStatus{Code: statusOK},
+	}
+	if s.parentID != ([8]byte{}) {
+		result.ParentSpanID = hex.EncodeToString(s.parentID[:])
+	}
+	if s.errMsg != "" {
+		result.Status = otlpStatus{Code: statusError, Message: s.errMsg}
+	}
+	keys := make([]string, 0, len(s.attrs))
+	for key := range s.attrs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		result.Attributes = append(result.Attributes, otlpKeyValue{Key: key, Value: toOTLPValue(s.attrs[key])})
+	}
+	return result
+}
+
+func toOTLPValue(value interface{}) otlpValue {
+	switch v := value.(type) {
+	case string:
+		return otlpValue{StringValue: &v}
+	case bool:
+		return otlpValue{BoolValue: &v}
+	case int:
+		text := strconv.Itoa(v)
+		return otlpValue{IntValue: &text}
+	case int64:
+		text := strconv.FormatInt(v, 10)
+		return otlpValue{IntValue: &text}
+	case float64:
+		return otlpValue{DoubleValue: &v}
+	case []string:
+		values := make([]otlpValue, 0, len(v))
+		for _, item := range v {
+			values = append(values, toOTLPValue(item))
+		}
+		return otlpValue{ArrayValue: &otlpArrayValue{Values: values}}
+	default:
+		text := fmt.Sprint(v)
+		return otlpValue{StringValue: &text}
+	}
+}
+
+// parseTraceparent 解析 00-<trace-id>-<span-id>-<flags> 格式
+func parseTraceparent(value string) ([16]byte, [8]byte, bool) {
+	var traceID [16]byte
+	var spanID [8]byte
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) != 4 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return traceID, spanID, false
+	}
+	if _, err := hex.Decode(traceID[:], []byte(parts[1])); err != nil {
+		return traceID, spanID, false
+	}
+	if _, err := hex.Decode(spanID[:], []byte(parts[2])); err != nil {
+		return traceID, spanID, false
+	}
+	if traceID == ([16]byte{}) || spanID == ([8]byte{}) {
+		return traceID, spanID, false
+	}
+	return traceID, spanID, true
+}
@@ -0,0 +1,101 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStartWithoutSetupIsNoop(t *testing.T) {
+	ctx, span := Start(context.Background(), "noop")
+	span.SetAttr("key", "value")
+	span.RecordError(errors.New("ignored"))
+	span.End()
+	if FromContext(ctx) != nil || Traceparent(ctx) != "" {
+		t.Fatalf("expected tracing to be disabled")
+	}
+}
+
+func TestSpansExportedToFileWithParent(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "traces.jsonl")
+	parent := "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+	shutdown := Setup(Options{File: file, Parent: parent}, "alpen")
+
+	ctx, span := Start(context.Background(), "alpen.execute")
+	span.SetAttr("alpen.command.path", "db migrate")
+	span.SetAttr("alpen.exit_code", 3)
+	span.RecordError(errors.New("exit status 3"))
+	traceparent := Traceparent(ctx)
+	_, child := Start(ctx, "alpen.plugins.emit")
+	child.SetAttr("alpen.plugins", []string{"audit", "notify"})
+	child.End()
+	span.End()
+
+	if err := shutdown(); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if !strings.HasPrefix(traceparent, "00-0af7651916cd43dd8448eb211c80319c-") {
+		t.Fatalf("expected traceparent to reuse parent trace id, got %s", traceparent)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("read trace file failed: %v", err)
+	}
+	var req otlpRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		t.Fatalf("trace file is not OTLP JSON: %v", err)
+	}
+	spans := req.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 3 {
+		t.Fatalf("expected 3 spans, got %d", len(spans))
+	}
+	byName := map[string]otlpSpan{}
+	for _, s := range spans {
+		byName[s.Name] = s
+	}
+	execSpan := byName["alpen.execute"]
+	if execSpan.Status.Code != statusError || execSpan.ParentSpanID != byName["alpen"].SpanID {
+		t.Fatalf("unexpected execute span: %+v", execSpan)
+	}
+	if byName["alpen"].ParentSpanID != "b7ad6b7169203331" {
+		t.Fatalf("expected root span to join parent, got %+v", byName["alpen"])
+	}
+	if byName["alpen.plugins.emit"].ParentSpanID != execSpan.SpanID {
+		t.Fatalf("expected emit span to be a child of execute span")
+	}
+	if Enabled() {
+		t.Fatalf("expected tracing to be disabled after shutdown")
+	}
+}
+
+func TestExportFallsBackToFileWhenCollectorFails(t *testing.T) {
+	var received int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.ReadAll(r.Body)
+		received++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	file := filepath.Join(t.TempDir(), "traces.jsonl")
+	shutdown := Setup(Options{Endpoint: server.URL + "/v1/traces", File: file}, "alpen")
+	_, span := Start(context.Background(), "alpen.config.load")
+	span.End()
+	if err := shutdown(); err != nil {
+		t.Fatalf("expected fallback to file, got %v", err)
+	}
+	if received != 1 {
+		t.Fatalf("expected one export attempt to collector, got %d", received)
+	}
+	if info, err := os.Stat(file); err != nil || info.Size() == 0 {
+		t.Fatalf("expected spans written to fallback file: %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package tui
+
+import (
+	"bufio"
+)
+
+// KeyCode 为特殊按键，普通字符为 KeyRune
+type KeyCode int
+
+// 支持的按键
+const (
+	KeyRune KeyCode = iota
+	KeyEnter
+	KeyTab
+	KeyBacktab
+	KeyBackspace
+	KeyEscape
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyHome
+	KeyEnd
+	KeyPageUp
+	KeyPageDown
+	KeyCtrlC
+	KeyCtrlU
+	KeyUnknown
+)
+
+// Key 为一次按键，Code 为 KeyRune 时 Rune 为输入的字符
+type Key struct {
+	Code KeyCode
+	Rune rune
+}
+
+// csiKeys 为 ESC [ 之后的终止字符对应的按键
+var csiKeys = map[byte]KeyCode{
+	'A': KeyUp,
+	'B': KeyDown,
+	'C': KeyRight,
+	'D': KeyLeft,
+	'H': KeyHome,
+	'F': KeyEnd,
+	'Z': KeyBacktab,
+}
+
+// tildeKeys 为 ESC [ <n> ~ 形式的按键
+var tildeKeys = map[string]KeyCode{
+	"1": KeyHome,
+	"4": KeyEnd,
+	"5": KeyPageUp,
+	"6": KeyPageDown,
+	"7": KeyHome,
+	"8": KeyEnd,
+}
+
+func readKey(reader *bufio.Reader) (Key, error) {
+	r, _, err := reader.ReadRune()
+	if err != nil {
+		return Key{}, err
+	}
+	switch r {
+	case '\r', '\n':
+		return Key{Code: KeyEnter}, nil
+	case '\t':
+		return Key{Code: KeyTab}, nil
+	case 0x7f, 0x08:
+		return Key{Code: KeyBackspace}, nil
+	case 0x03:
+		return Key{Code: KeyCtrlC}, nil
+	case 0x15:
+		return Key{Code: KeyCtrlU}, nil
+	case 0x1b:
+		return readEscape(reader)
+	}
+	if r < 0x20 {
+		return Key{Code: KeyUnknown}, nil
+	}
+	return Key{Code: KeyRune, Rune: r}, nil
+}
+
+// readEscape 解析 ESC 开头的控制序列，单独的 ESC 视为 Esc 键
+func readEscape(reader *bufio.Reader) (Key, error) {
+	if reader.Buffered() == 0 {
+		return Key{Code: KeyEscape}, nil
+	}
+	next, err := reader.ReadByte()
+	if err != nil {
+		return Key{}, err
+	}
+	if next != '[' && next != 'O' {
+		return Key{Code: KeyEscape}, nil
+	}
+	var params []byte
+	for {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return Key{}, err
+		}
+		if b >= 0x40 && b <= 0x7e {
+			if b == '~' {
+				if code, ok := tildeKeys[string(params)]; ok {
+					return Key{Code: code}, nil
+				}
+				return Key{Code: KeyUnknown}, nil
+			}
+			if code, ok := csiKeys[b]; ok {
+				return Key{Code: code}, nil
+			}
+			return Key{Code: KeyUnknown}, nil
+		}
+		params = append(params, b)
+	}
+}
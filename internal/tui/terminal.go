@@ -0,0 +1,109 @@
+// Package tui 提供全屏终端界面所需的基础能力：原始模式、备用屏幕、按键解析与整屏绘制
+package tui
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/alpen/alpen-cli/internal/i18n"
+)
+
+// ANSI 控制序列
+const (
+	enterAltScreen = "\x1b[?1049h"
+	leaveAltScreen = "\x1b[?1049l"
+	hideCursor     = "\x1b[?25l"
+	showCursor     = "\x1b[?25h"
+	disableWrap    = "\x1b[?7l"
+	enableWrap     = "\x1b[?7h"
+	cursorHome     = "\x1b[H"
+	clearLine      = "\x1b[K"
+	clearBelow     = "\x1b[J"
+)
+
+// ErrNotTerminal 表示输入或输出不是交互式终端
+var ErrNotTerminal = i18n.NewError("tui.not_terminal")
+
+// Terminal 为进入全屏模式的终端，使用完毕后必须调用 Close 恢复
+type Terminal struct {
+	in     *os.File
+	out    *os.File
+	reader *bufio.Reader
+	state  *term.State
+}
+
+// Open 将终端切换为原始模式并进入备用屏幕
+func Open(in *os.File, out *os.File) (*Terminal, error) {
+	if !term.IsTerminal(int(in.Fd())) || !term.IsTerminal(int(out.Fd())) {
+		return nil, ErrNotTerminal
+	}
+	t := &Terminal{in: in, out: out, reader: bufio.NewReader(in)}
+	if err := t.Resume(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Suspend 暂时退出全屏模式，恢复终端原有状态，便于子进程直接读写终端
+func (t *Terminal) Suspend() error {
+	if t.state == nil {
+		return nil
+	}
+	fmt.Fprint(t.out, enableWrap+showCursor+leaveAltScreen)
+	err := term.Restore(int(t.in.Fd()), t.state)
+	t.state = nil
+	return err
+}
+
+// Resume 重新进入全屏模式
+func (t *Terminal) Resume() error {
+	if t.state != nil {
+		return nil
+	}
+	state, err := term.MakeRaw(int(t.in.Fd()))
+	if err != nil {
+		return i18n.Errorf("tui.raw_mode_failed", err)
+	}
+	t.state = state
+	fmt.Fprint(t.out, enterAltScreen+hideCursor+disableWrap)
+	return nil
+}
+
+// Close 退出全屏模式并恢复终端
+func (t *Terminal) Close() error {
+	return t.Suspend()
+}
+
+// Size 返回终端的列数与行数，获取失败时返回 80x24
+func (t *Terminal) Size() (int, int) {
+	width, height, err := term.GetSize(int(t.out.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		return 80, 24
+	}
+	return width, height
+}
+
+// Draw 从左上角开始重绘整屏，超出宽度的内容由终端截断
+func (t *Terminal) Draw(lines []string) {
+	var b strings.Builder
+	b.WriteString(cursorHome)
+	for i, line := range lines {
+		b.WriteString(line)
+		b.WriteString(clearLine)
+		if i < len(lines)-1 {
+			b.WriteString("\r\n")
+		}
+	}
+	b.WriteString(clearBelow)
+	io.WriteString(t.out, b.String())
+}
+
+// ReadKey 阻塞读取一次按键
+func (t *Terminal) ReadKey() (Key, error) {
+	return readKey(t.reader)
+}
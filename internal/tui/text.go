@@ -0,0 +1,82 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/alpen/alpen-cli/internal/ui/width"
+)
+
+// Width 返回去除 ANSI 控制序列后的显示宽度
+func Width(text string) int {
+	return width.String(StripANSI(text))
+}
+
+// StripANSI 去除文本中的 ANSI 控制序列
+func StripANSI(text string) string {
+	if !strings.Contains(text, "\x1b") {
+		return text
+	}
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] == 0x1b {
+			i = skipEscape(text, i)
+			continue
+		}
+		b.WriteByte(text[i])
+	}
+	return b.String()
+}
+
+// Fit 将文本截断或补齐到指定显示宽度，保留其中的 ANSI 控制序列，截断时以省略号结尾
+func Fit(text string, columns int) string {
+	if columns <= 0 {
+		return ""
+	}
+	total := Width(text)
+	if total <= columns {
+		return text + strings.Repeat(" ", columns-total)
+	}
+	var b strings.Builder
+	used := 0
+	for i := 0; i < len(text); {
+		if text[i] == 0x1b {
+			end := skipEscape(text, i) + 1
+			b.WriteString(text[i:end])
+			i = end
+			continue
+		}
+		size, cells := width.Next(text[i:])
+		if used+cells > columns-1 {
+			break
+		}
+		b.WriteString(text[i : i+size])
+		used += cells
+		i += size
+	}
+	// 宽字符放不下时用空格补齐，保证结果恰好占满指定宽度
+	b.WriteString(strings.Repeat(" ", columns-1-used) + "…")
+	if strings.Contains(text, "\x1b") {
+		// 重置颜色，避免被截断的颜色延续到后续内容
+		b.WriteString("\x1b[0m")
+	}
+	return b.String()
+}
+
+// Wrap 按显示宽度将纯文本折行
+func Wrap(text string, columns int) []string {
+	return width.Wrap(text, columns)
+}
+
+// skipEscape 返回从 start 开始的控制序列最后一个字节的位置
+func skipEscape(text string, start int) int {
+	i := start + 1
+	if i < len(text) && text[i] == '[' {
+		for i++; i < len(text); i++ {
+			if text[i] >= 0x40 && text[i] <= 0x7e {
+				return i
+			}
+		}
+		return len(text) - 1
+	}
+	return min(i, len(text)-1)
+}
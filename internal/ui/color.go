@@ -4,100 +4,190 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"runtime"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+
+	"golang.org/x/term"
+
+	"github.com/alpen/alpen-cli/internal/i18n"
+	"github.com/alpen/alpen-cli/internal/ui/width"
 )
 
-// ANSI 转义码颜色定义
+// ANSI 转义码，颜色由主题决定
 const (
 	reset  = "\033[0m"
-	red    = "\033[31m"
-	green  = "\033[32m"
-	yellow = "\033[33m"
-	cyan   = "\033[36m"
-	gray   = "\033[90m"
 	bold   = "\033[1m"
+	invert = "\033[7m"
+)
+
+// --color 的取值
+const (
+	ColorAuto   = "auto"
+	ColorAlways = "always"
+	ColorNever  = "never"
 )
 
-// 检测是否支持彩色输出
-var colorEnabled = checkColorSupport()
+var (
+	colorMode = ColorAuto
+	// terminals 缓存各文件描述符是否为终端
+	terminals sync.Map
+)
 
-func checkColorSupport() bool {
-	// Windows 下需要检测终端类型
-	if runtime.GOOS == "windows" {
-		// 简化：假设 Windows 终端支持 ANSI（Windows 10+ 默认支持）
-		return os.Getenv("TERM") != "dumb"
+// SetColorMode 设置彩色输出方式：auto 按环境变量与输出目标是否为终端判断，always、never 强制开启或关闭
+func SetColorMode(mode string) error {
+	switch value := strings.ToLower(strings.TrimSpace(mode)); value {
+	case "", ColorAuto:
+		colorMode = ColorAuto
+	case ColorAlways, ColorNever:
+		colorMode = value
+	default:
+		return i18n.Errorf("ui.color_mode_invalid", mode)
 	}
-	// Unix-like 系统检测终端
-	if os.Getenv("TERM") == "dumb" {
+	return nil
+}
+
+// ColorEnabled 判断写入 w 的内容是否使用彩色：--color 优先，其次为 FORCE_COLOR、NO_COLOR、
+// CLICOLOR_FORCE、CLICOLOR 与 TERM=dumb，均未指定时仅在 w 为终端时启用
+func ColorEnabled(w io.Writer) bool {
+	switch colorMode {
+	case ColorAlways:
+		return true
+	case ColorNever:
 		return false
 	}
-	if _, ok := os.LookupEnv("NO_COLOR"); ok {
-		return false
+	if enabled, decided := colorFromEnv(); decided {
+		return enabled
+	}
+	return isTerminal(w)
+}
+
+func colorFromEnv() (enabled bool, decided bool) {
+	if value, ok := os.LookupEnv("FORCE_COLOR"); ok {
+		return value != "0" && !strings.EqualFold(value, "false"), true
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false, true
+	}
+	if value := os.Getenv("CLICOLOR_FORCE"); value != "" && value != "0" {
+		return true, true
+	}
+	if os.Getenv("CLICOLOR") == "0" || os.Getenv("TERM") == "dumb" {
+		return false, true
+	}
+	return false, false
+}
+
+// isTerminal 判断 w 是否为终端；缓冲区、输出面板等非文件的写入目标最终写到标准输出，按标准输出判断
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(interface{ Fd() uintptr })
+	if !ok {
+		file = os.Stdout
+	}
+	fd := file.Fd()
+	if cached, ok := terminals.Load(fd); ok {
+		return cached.(bool)
+	}
+	result := term.IsTerminal(int(fd))
+	terminals.Store(fd, result)
+	return result
+}
+
+// Columns 返回写入目标 w 的终端宽度：优先使用 COLUMNS 环境变量，其次查询终端尺寸；
+// 输出被重定向或无法获取时返回 0，表示不限制宽度
+func Columns(w io.Writer) int {
+	if columns, err := strconv.Atoi(strings.TrimSpace(os.Getenv("COLUMNS"))); err == nil && columns > 0 {
+		return columns
+	}
+	file, ok := w.(interface{ Fd() uintptr })
+	if !ok || !isTerminal(w) {
+		return 0
+	}
+	columns, _, err := term.GetSize(int(file.Fd()))
+	if err != nil || columns <= 0 {
+		return 0
 	}
-	return true
+	return columns
 }
 
-// colorize 为文本添加颜色
+// colorize 为写到标准输出的文本添加颜色，color 为主题颜色或 bold 等转义码
 func colorize(color, text string) string {
-	if !colorEnabled {
+	return colorizeFor(os.Stdout, color, text)
+}
+
+// colorizeFor 按写入目标 w 是否启用彩色为文本添加颜色
+func colorizeFor(w io.Writer, color, text string) string {
+	if !ColorEnabled(w) {
 		return text
 	}
 	return color + text + reset
 }
 
+// palette 返回当前主题中 role 的颜色
+func palette(role string) string {
+	return current.colors[role]
+}
+
 // Success 输出成功消息（绿色）
 func Success(w io.Writer, format string, args ...interface{}) {
 	msg := fmt.Sprintf(format, args...)
-	fmt.Fprintln(w, colorize(green, "+ "+msg))
+	fmt.Fprintln(w, colorizeFor(w, palette("success"), Icons().Success+" "+msg))
 }
 
 // Error 输出错误消息（红色）
 func Error(w io.Writer, format string, args ...interface{}) {
 	msg := fmt.Sprintf(format, args...)
-	fmt.Fprintln(w, colorize(red, "x "+msg))
+	fmt.Fprintln(w, colorizeFor(w, palette("error"), Icons().Error+" "+msg))
 }
 
 // Warning 输出警告消息（黄色）
 func Warning(w io.Writer, format string, args ...interface{}) {
 	msg := fmt.Sprintf(format, args...)
-	fmt.Fprintln(w, colorize(yellow, "! "+msg))
+	fmt.Fprintln(w, colorizeFor(w, palette("warning"), Icons().Warning+" "+msg))
 }
 
 // Info 输出信息消息（灰色）
 func Info(w io.Writer, format string, args ...interface{}) {
 	msg := fmt.Sprintf(format, args...)
-	fmt.Fprintln(w, colorize(gray, "• "+msg))
+	fmt.Fprintln(w, colorizeFor(w, palette("muted"), Icons().Info+" "+msg))
 }
 
 // Prompt 输出提示符（青色加粗）
 func Prompt(w io.Writer, text string) {
-	fmt.Fprint(w, colorize(cyan+bold, text))
+	fmt.Fprint(w, colorizeFor(w, palette("primary")+bold, text))
 }
 
 // Title 输出标题（粗体）
 func Title(w io.Writer, text string) {
-	fmt.Fprintln(w, colorize(bold, text))
+	fmt.Fprintln(w, colorizeFor(w, bold, text))
 }
 
+// separatorWidth 为分隔线的默认宽度
+const separatorWidth = 40
+
 // Separator 输出分隔线
 func Separator(w io.Writer) {
-	fmt.Fprintln(w, colorize(gray, "────────────────────────────────────────"))
+	separatorLine(w, separatorWidth)
+}
+
+func separatorLine(w io.Writer, columns int) {
+	fmt.Fprintln(w, colorizeFor(w, palette("muted"), strings.Repeat(Icons().Line, columns)))
 }
 
-// MenuTitle 输出菜单标题
+// MenuTitle 输出菜单标题，标题较长时分隔线随之加长
 func MenuTitle(w io.Writer, title string) {
-	Separator(w)
-	fmt.Fprintln(w, colorize(cyan+bold, "  "+title))
-	Separator(w)
+	columns := max(separatorWidth, width.String(title)+4)
+	separatorLine(w, columns)
+	fmt.Fprintln(w, colorizeFor(w, palette("primary")+bold, "  "+title))
+	separatorLine(w, columns)
 }
 
 // MenuItem 输出菜单项
 func MenuItem(w io.Writer, index int, label, description string) {
-	indexStr := colorize(cyan+bold, fmt.Sprintf("%d.", index))
+	indexStr := colorizeFor(w, palette("primary")+bold, fmt.Sprintf("%d.", index))
 	if description != "" && description != label {
-		fmt.Fprintf(w, "  %s %s %s\n", indexStr, label, colorize(gray, "- "+description))
+		fmt.Fprintf(w, "  %s %s %s\n", indexStr, label, colorizeFor(w, palette("muted"), "- "+description))
 	} else {
 		fmt.Fprintf(w, "  %s %s\n", indexStr, label)
 	}
@@ -105,19 +195,35 @@ func MenuItem(w io.Writer, index int, label, description string) {
 
 // Executing 输出执行提示（带动画效果）
 func Executing(w io.Writer, scriptName string) {
-	fmt.Fprintln(w, colorize(yellow, "~ 正在执行: "+scriptName))
+	fmt.Fprintln(w, colorizeFor(w, palette("warning"), Icons().Running+" "+i18n.T("ui.executing")+scriptName))
 }
 
 // Duration 输出耗时（灰色）
 func Duration(w io.Writer, duration string) {
-	fmt.Fprintln(w, colorize(gray, "  耗时: "+duration))
+	fmt.Fprintln(w, colorizeFor(w, palette("muted"), i18n.T("ui.duration")+duration))
+}
+
+// decorationsEnabled 为 false 时省略执行开始、结束框架与执行摘要，只保留脚本自身的输出
+var decorationsEnabled = true
+
+// SetDecorations 设置是否输出执行框架与执行摘要，标准输出不是终端或指定 --quiet 时关闭
+func SetDecorations(enabled bool) {
+	decorationsEnabled = enabled
+}
+
+// Decorations 返回是否输出执行框架与执行摘要
+func Decorations() bool {
+	return decorationsEnabled
 }
 
 // BeginExecution 输出统一的执行开始框架
 func BeginExecution(w io.Writer, scriptName string) {
+	if !decorationsEnabled {
+		return
+	}
 	name := strings.TrimSpace(scriptName)
 	if name == "" {
-		name = "未命名命令"
+		name = i18n.T("ui.unnamed_command")
 	}
 	fmt.Fprintln(w, "")
 	Executing(w, name)
@@ -126,16 +232,22 @@ func BeginExecution(w io.Writer, scriptName string) {
 
 // EndExecution 输出统一的执行结束分隔
 func EndExecution(w io.Writer) {
+	if !decorationsEnabled {
+		return
+	}
 	fmt.Fprintln(w, "")
 	Separator(w)
 }
 
 // ExecutionSummary 输出统一的脚本执行摘要
 func ExecutionSummary(w io.Writer, success bool, duration time.Duration, execErr error) {
+	if !decorationsEnabled {
+		return
+	}
 	if success {
-		fmt.Fprintln(w, colorize(green, "+ 命令执行完成"))
+		fmt.Fprintln(w, colorizeFor(w, palette("success"), Icons().Success+" "+i18n.T("ui.summary.succeeded")))
 	} else {
-		fmt.Fprintln(w, colorize(red, "x 命令执行失败"))
+		fmt.Fprintln(w, colorizeFor(w, palette("error"), Icons().Error+" "+i18n.T("ui.summary.failed")))
 	}
 
 	Duration(w, duration.String())
@@ -149,13 +261,16 @@ func ExecutionSummary(w io.Writer, success bool, duration time.Duration, execErr
 		return
 	}
 
+	label := i18n.T("ui.summary.error")
+	// 后续行与首行的错误内容对齐
+	indent := strings.Repeat(" ", width.String(label))
 	lines := strings.Split(message, "\n")
 	for index, line := range lines {
-		prefix := "  错误: "
+		prefix := label
 		if index > 0 {
-			prefix = "        "
+			prefix = indent
 		}
-		fmt.Fprintln(w, colorize(red, prefix+strings.TrimSpace(line)))
+		fmt.Fprintln(w, colorizeFor(w, palette("error"), prefix+strings.TrimSpace(line)))
 	}
 }
 
@@ -164,29 +279,34 @@ func Highlight(text string) string {
 	return colorize(bold, text)
 }
 
-// Gray 灰色文本
+// Reverse 反色文本，用于标记当前选中项
+func Reverse(text string) string {
+	return colorize(invert, text)
+}
+
+// Gray 次要文本，使用主题的 muted 颜色（默认灰色）
 func Gray(text string) string {
-	return colorize(gray, text)
+	return colorize(palette("muted"), text)
 }
 
-// Red 红色文本
+// Red 错误文本，使用主题的 error 颜色（默认红色）
 func Red(text string) string {
-	return colorize(red, text)
+	return colorize(palette("error"), text)
 }
 
-// Green 绿色文本
+// Green 成功文本，使用主题的 success 颜色（默认绿色）
 func Green(text string) string {
-	return colorize(green, text)
+	return colorize(palette("success"), text)
 }
 
-// Yellow 黄色文本
+// Yellow 警告文本，使用主题的 warning 颜色（默认黄色）
 func Yellow(text string) string {
-	return colorize(yellow, text)
+	return colorize(palette("warning"), text)
 }
 
-// Cyan 青色文本
+// Cyan 强调文本，使用主题的 primary 颜色（默认青色）
 func Cyan(text string) string {
-	return colorize(cyan, text)
+	return colorize(palette("primary"), text)
 }
 
 // Banner 输出简洁标题横幅
@@ -194,12 +314,12 @@ func Banner(w io.Writer, title string) {
 	if strings.TrimSpace(title) == "" {
 		return
 	}
-	lineWidth := len([]rune(title)) + 4
+	lineWidth := width.String(title) + 4
 	if lineWidth < 12 {
 		lineWidth = 12
 	}
-	fmt.Fprintln(w, colorize(cyan+bold, "  "+title))
-	fmt.Fprintln(w, colorize(gray, "  "+strings.Repeat("─", lineWidth)))
+	fmt.Fprintln(w, colorizeFor(w, palette("primary")+bold, "  "+title))
+	fmt.Fprintln(w, colorizeFor(w, palette("muted"), "  "+strings.Repeat(Icons().Line, lineWidth)))
 }
 
 // Box 输出带边框的文本块
@@ -207,47 +327,50 @@ func Box(w io.Writer, content string) {
 	lines := strings.Split(content, "\n")
 	maxLen := 0
 	for _, line := range lines {
-		if len(line) > maxLen {
-			maxLen = len(line)
-		}
+		maxLen = max(maxLen, width.String(line))
 	}
 
-	border := strings.Repeat("─", maxLen+2)
-	fmt.Fprintln(w, colorize(gray, "┌"+border+"┐"))
+	muted := palette("muted")
+	corners, side := [4]string{"┌", "┐", "└", "┘"}, Icons().Border
+	if current.ascii {
+		corners = [4]string{"+", "+", "+", "+"}
+	}
+	border := strings.Repeat(Icons().Line, maxLen+2)
+	fmt.Fprintln(w, colorizeFor(w, muted, corners[0]+border+corners[1]))
 	for _, line := range lines {
-		padding := maxLen - len(line)
-		fmt.Fprintf(w, colorize(gray, "│")+" %s%s "+colorize(gray, "│")+"\n",
+		padding := maxLen - width.String(line)
+		fmt.Fprintf(w, colorizeFor(w, muted, side)+" %s%s "+colorizeFor(w, muted, side)+"\n",
 			line, strings.Repeat(" ", padding))
 	}
-	fmt.Fprintln(w, colorize(gray, "└"+border+"┘"))
+	fmt.Fprintln(w, colorizeFor(w, muted, corners[2]+border+corners[3]))
 }
 
 // CommandItem 输出格式化的命令项（用于菜单显示）
 func CommandItem(label, description string) string {
 	if description != "" {
 		return fmt.Sprintf("%s %s %s",
-			colorize(cyan+bold, label),
-			colorize(gray, "│"),
-			colorize(gray, description))
+			colorize(palette("primary")+bold, label),
+			colorize(palette("muted"), Icons().Border),
+			colorize(palette("muted"), description))
 	}
-	return colorize(cyan+bold, label)
+	return colorize(palette("primary")+bold, label)
 }
 
 // KeyValue 输出统一的键值对格式
 func KeyValue(w io.Writer, key, value string) {
-	fmt.Fprintf(w, "  %s %s\n", Gray(key+":"), Cyan(value))
+	fmt.Fprintf(w, "  %s %s\n", colorizeFor(w, palette("muted"), key+":"), colorizeFor(w, palette("primary"), value))
 }
 
 // KeyValueSuccess 输出成功状态的键值对
 func KeyValueSuccess(w io.Writer, key, value string) {
-	fmt.Fprintf(w, "  %s %s\n", Gray(key+":"), Green(value))
+	fmt.Fprintf(w, "  %s %s\n", colorizeFor(w, palette("muted"), key+":"), colorizeFor(w, palette("success"), value))
 }
 
 // SectionHeader 输出简洁的节标题（青色标题 + 灰色路径说明）
 func SectionHeader(w io.Writer, title, subtitle string) {
-	fmt.Fprintln(w, Cyan(title))
+	fmt.Fprintln(w, colorizeFor(w, palette("primary"), title))
 	if strings.TrimSpace(subtitle) != "" {
-		fmt.Fprintf(w, "%s %s\n", Gray("配置路径:"), Gray(subtitle))
+		fmt.Fprintf(w, "%s %s\n", colorizeFor(w, palette("muted"), i18n.T("ui.config_path")), colorizeFor(w, palette("muted"), subtitle))
 	}
 	fmt.Fprintln(w, "")
 }
@@ -0,0 +1,245 @@
+package ui
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/alpen/alpen-cli/internal/i18n"
+	"github.com/alpen/alpen-cli/internal/ui/logo"
+)
+
+// Theme 描述界面的配色、图标与 Logo，可通过 ~/.alpen/config/theme.yaml 覆盖，未配置的项使用默认值
+type Theme struct {
+	// ASCII 为 true 时未单独配置的图标使用 ASCII 字符，适用于不支持 Unicode 符号的终端或字体
+	ASCII  bool    `yaml:"ascii"`
+	Colors Palette `yaml:"colors"`
+	Icons  IconSet `yaml:"icons"`
+	// Logo 为欢迎页的 Logo，未配置时使用内置 Logo，配置为空字符串时不显示
+	Logo *string `yaml:"logo"`
+}
+
+// Palette 为各类文本的颜色，取值为颜色名（black、red、green、yellow、blue、magenta、cyan、white、gray）、
+// 0-255 的 256 色编号或 #rrggbb
+type Palette struct {
+	// Primary 用于命令名、标题与提示符，默认 cyan
+	Primary string `yaml:"primary"`
+	Success string `yaml:"success"`
+	Warning string `yaml:"warning"`
+	Error   string `yaml:"error"`
+	// Muted 用于说明、分隔线与次要信息，默认 gray
+	Muted string `yaml:"muted"`
+}
+
+// IconSet 为界面中使用的符号
+type IconSet struct {
+	Success   string `yaml:"success"`
+	Error     string `yaml:"error"`
+	Warning   string `yaml:"warning"`
+	Info      string `yaml:"info"`
+	Running   string `yaml:"running"`
+	Done      string `yaml:"done"`
+	Failed    string `yaml:"failed"`
+	Bullet    string `yaml:"bullet"`
+	Pointer   string `yaml:"pointer"`
+	Focus     string `yaml:"focus"`
+	Prompt    string `yaml:"prompt"`
+	Arrow     string `yaml:"arrow"`
+	Origin    string `yaml:"origin"`
+	Collapsed string `yaml:"collapsed"`
+	Expanded  string `yaml:"expanded"`
+	Pinned    string `yaml:"pinned"`
+	Recent    string `yaml:"recent"`
+	Menu      string `yaml:"menu"`
+	Checked   string `yaml:"checked"`
+	Unchecked string `yaml:"unchecked"`
+	// Line 与 Border 为横向与纵向分隔线使用的字符
+	Line   string `yaml:"line"`
+	Border string `yaml:"border"`
+	// Cursor 为输入框中的光标
+	Cursor string `yaml:"cursor"`
+	// Spinner 为运行中动画的帧，每个字符为一帧
+	Spinner string `yaml:"spinner"`
+}
+
+var unicodeIcons = IconSet{
+	Success: "+", Error: "x", Warning: "!", Info: "•", Running: "~",
+	Done: "✓", Failed: "✗", Bullet: "·", Pointer: "›", Focus: "❯", Prompt: "▸", Arrow: "→", Origin: "←",
+	Collapsed: "▸", Expanded: "▾", Pinned: "★", Recent: "↻", Menu: "☰", Checked: "◉", Unchecked: "◯",
+	Line: "─", Border: "│", Cursor: "▏", Spinner: "⠋⠙⠹⠸⠼⠴⠦⠧⠇⠏",
+}
+
+var asciiIcons = IconSet{
+	Success: "+", Error: "x", Warning: "!", Info: "*", Running: "~",
+	Done: "v", Failed: "x", Bullet: "-", Pointer: ">", Focus: ">", Prompt: ">", Arrow: "->", Origin: "<-",
+	Collapsed: "+", Expanded: "-", Pinned: "*", Recent: "@", Menu: "=", Checked: "[x]", Unchecked: "[ ]",
+	Line: "-", Border: "|", Cursor: "_", Spinner: `|/-\`,
+}
+
+var defaultPalette = Palette{Primary: "cyan", Success: "green", Warning: "yellow", Error: "red", Muted: "gray"}
+
+// namedColors 为颜色名对应的 ANSI 前景色
+var namedColors = map[string]string{
+	"black": "30", "red": "31", "green": "32", "yellow": "33",
+	"blue": "34", "magenta": "35", "cyan": "36", "white": "37",
+	"gray": "90", "grey": "90",
+}
+
+// activeTheme 为解析后的当前主题
+type activeTheme struct {
+	ascii  bool
+	colors map[string]string
+	icons  IconSet
+	logo   []string
+}
+
+var current = mustResolve(Theme{})
+
+// LoadTheme 读取主题文件并应用，文件不存在时保持默认主题
+func LoadTheme(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var theme Theme
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&theme); err != nil && !errors.Is(err, io.EOF) {
+		return i18n.Errorf("theme.parse_failed", path, err)
+	}
+	if err := SetTheme(theme); err != nil {
+		return i18n.Errorf("theme.invalid", path, err)
+	}
+	return nil
+}
+
+// SetTheme 应用主题，颜色无效时返回错误并保持当前主题
+func SetTheme(theme Theme) error {
+	resolved, err := resolve(theme)
+	if err != nil {
+		return err
+	}
+	current = resolved
+	return nil
+}
+
+// Icons 返回当前主题的图标
+func Icons() IconSet {
+	return current.icons
+}
+
+// ThemeColor 返回当前主题中 primary、success、warning、error 或 muted 对应的 ANSI 转义码，不判断是否启用彩色
+func ThemeColor(role string) string {
+	return current.colors[role]
+}
+
+// ThemeIcon 按主题文件中的名称（如 checked、focus）返回图标，名称未知时返回空字符串
+func ThemeIcon(name string) string {
+	icons := current.icons
+	for _, field := range icons.fields() {
+		if field.name == name {
+			return *field.value
+		}
+	}
+	return ""
+}
+
+func mustResolve(theme Theme) activeTheme {
+	resolved, err := resolve(theme)
+	if err != nil {
+		panic(err)
+	}
+	return resolved
+}
+
+func resolve(theme Theme) (activeTheme, error) {
+	resolved := activeTheme{ascii: theme.ASCII, colors: map[string]string{}}
+	roles := []struct {
+		name, value, fallback string
+	}{
+		{"primary", theme.Colors.Primary, defaultPalette.Primary},
+		{"success", theme.Colors.Success, defaultPalette.Success},
+		{"warning", theme.Colors.Warning, defaultPalette.Warning},
+		{"error", theme.Colors.Error, defaultPalette.Error},
+		{"muted", theme.Colors.Muted, defaultPalette.Muted},
+	}
+	for _, role := range roles {
+		value := role.value
+		if strings.TrimSpace(value) == "" {
+			value = role.fallback
+		}
+		code, err := parseColor(value)
+		if err != nil {
+			return activeTheme{}, i18n.Errorf("theme.color_field", role.name, err)
+		}
+		resolved.colors[role.name] = code
+	}
+
+	resolved.icons = unicodeIcons
+	if theme.ASCII {
+		resolved.icons = asciiIcons
+	}
+	overlayIcons(&resolved.icons, theme.Icons)
+
+	resolved.logo = logo.Lines()
+	if theme.Logo != nil {
+		resolved.logo = nil
+		if text := strings.TrimRight(*theme.Logo, "\n"); strings.TrimSpace(text) != "" {
+			resolved.logo = strings.Split(text, "\n")
+		}
+	}
+	return resolved, nil
+}
+
+// iconField 为图标在主题文件中的名称与对应字段
+type iconField struct {
+	name  string
+	value *string
+}
+
+func (s *IconSet) fields() []iconField {
+	return []iconField{
+		{"success", &s.Success}, {"error", &s.Error}, {"warning", &s.Warning}, {"info", &s.Info},
+		{"running", &s.Running}, {"done", &s.Done}, {"failed", &s.Failed}, {"bullet", &s.Bullet},
+		{"pointer", &s.Pointer}, {"focus", &s.Focus}, {"prompt", &s.Prompt}, {"arrow", &s.Arrow},
+		{"origin", &s.Origin}, {"collapsed", &s.Collapsed}, {"expanded", &s.Expanded}, {"pinned", &s.Pinned}, {"recent", &s.Recent},
+		{"menu", &s.Menu}, {"checked", &s.Checked}, {"unchecked", &s.Unchecked}, {"line", &s.Line},
+		{"border", &s.Border}, {"cursor", &s.Cursor}, {"spinner", &s.Spinner},
+	}
+}
+
+// overlayIcons 用 custom 中已配置的图标覆盖 base
+func overlayIcons(base *IconSet, custom IconSet) {
+	overrides := custom.fields()
+	for i, field := range base.fields() {
+		if value := *overrides[i].value; value != "" {
+			*field.value = value
+		}
+	}
+}
+
+// parseColor 将颜色名、256 色编号或 #rrggbb 转换为 ANSI 前景色转义码
+func parseColor(spec string) (string, error) {
+	value := strings.ToLower(strings.TrimSpace(spec))
+	if code, ok := namedColors[value]; ok {
+		return "\033[" + code + "m", nil
+	}
+	if index, err := strconv.Atoi(value); err == nil && index >= 0 && index <= 255 {
+		return fmt.Sprintf("\033[38;5;%dm", index), nil
+	}
+	if len(value) == 7 && value[0] == '#' {
+		if rgb, err := strconv.ParseUint(value[1:], 16, 32); err == nil {
+			return fmt.Sprintf("\033[38;2;%d;%d;%dm", rgb>>16, rgb>>8&0xff, rgb&0xff), nil
+		}
+	}
+	return "", i18n.Errorf("theme.color_invalid", spec)
+}
@@ -0,0 +1,107 @@
+package ui
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func clearColorEnv(t *testing.T) {
+	t.Helper()
+	for _, name := range []string{"FORCE_COLOR", "NO_COLOR", "CLICOLOR_FORCE", "CLICOLOR", "TERM"} {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+	t.Cleanup(func() { _ = SetColorMode(ColorAuto) })
+}
+
+func TestColorEnabledFollowsModeAndEnvironment(t *testing.T) {
+	clearColorEnv(t)
+	var buf bytes.Buffer
+	if ColorEnabled(&buf) {
+		t.Fatalf("non-terminal writers should not be colored by default")
+	}
+	t.Setenv("CLICOLOR_FORCE", "1")
+	if !ColorEnabled(&buf) {
+		t.Fatalf("CLICOLOR_FORCE should enable colors")
+	}
+	t.Setenv("NO_COLOR", "1")
+	if ColorEnabled(&buf) {
+		t.Fatalf("NO_COLOR should win over CLICOLOR_FORCE")
+	}
+	t.Setenv("FORCE_COLOR", "1")
+	if !ColorEnabled(&buf) {
+		t.Fatalf("FORCE_COLOR should win over NO_COLOR")
+	}
+	if err := SetColorMode(ColorNever); err != nil || ColorEnabled(&buf) {
+		t.Fatalf("--color never should disable colors, got %v", err)
+	}
+	if err := SetColorMode("sometimes"); err == nil {
+		t.Fatalf("expected invalid color mode to fail")
+	}
+
+	_ = SetColorMode(ColorAlways)
+	Success(&buf, "done")
+	if !strings.Contains(buf.String(), "\033[32m+ done") {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+}
+
+func TestSetThemeAppliesPaletteAndIcons(t *testing.T) {
+	clearColorEnv(t)
+	t.Cleanup(func() { _ = SetTheme(Theme{}) })
+	empty := ""
+	theme := Theme{
+		ASCII:  true,
+		Colors: Palette{Primary: "#ff8800", Muted: "240"},
+		Icons:  IconSet{Pinned: "P"},
+		Logo:   &empty,
+	}
+	if err := SetTheme(theme); err != nil {
+		t.Fatalf("set theme: %v", err)
+	}
+	icons := Icons()
+	if icons.Pinned != "P" || icons.Done != "v" || icons.Line != "-" || ThemeIcon("checked") != "[x]" {
+		t.Fatalf("unexpected icons: %+v", icons)
+	}
+	if ThemeColor("primary") != "\033[38;2;255;136;0m" || ThemeColor("muted") != "\033[38;5;240m" || ThemeColor("error") != "\033[31m" {
+		t.Fatalf("unexpected palette: %q %q %q", ThemeColor("primary"), ThemeColor("muted"), ThemeColor("error"))
+	}
+	var buf bytes.Buffer
+	RenderLogo(&buf)
+	if buf.Len() != 0 {
+		t.Fatalf("empty logo should hide the logo, got %q", buf.String())
+	}
+
+	if err := SetTheme(Theme{Colors: Palette{Success: "chartreuse"}}); err == nil || Icons().Pinned != "P" {
+		t.Fatalf("invalid colors should be rejected without changing the theme, got %v", err)
+	}
+}
+
+func TestLoadThemeRejectsUnknownFields(t *testing.T) {
+	t.Cleanup(func() { _ = SetTheme(Theme{}) })
+	dir := t.TempDir()
+	if err := LoadTheme(filepath.Join(dir, "missing.yaml")); err != nil {
+		t.Fatalf("missing theme should keep the default, got %v", err)
+	}
+	path := filepath.Join(dir, "theme.yaml")
+	if err := os.WriteFile(path, []byte("icons:\n  sucess: ok\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := LoadTheme(path); err == nil {
+		t.Fatalf("expected unknown field to fail")
+	}
+	if err := os.WriteFile(path, []byte("icons:\n  success: ok\nlogo: |\n  ALPEN\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := LoadTheme(path); err != nil || Icons().Success != "ok" {
+		t.Fatalf("load theme: %v", err)
+	}
+	var buf bytes.Buffer
+	RenderLogo(&buf)
+	if !strings.Contains(buf.String(), "ALPEN") {
+		t.Fatalf("expected custom logo, got %q", buf.String())
+	}
+}
@@ -4,7 +4,8 @@ import (
 	"fmt"
 	"io"
 
-	"github.com/alpen/alpen-cli/internal/ui/logo"
+	"github.com/alpen/alpen-cli/internal/i18n"
+	"github.com/alpen/alpen-cli/internal/ui/width"
 )
 
 // CommandInfo 命令信息
@@ -13,9 +14,9 @@ type CommandInfo struct {
 	Description string
 }
 
-// RenderLogo 输出统一的 ASCII Logo
+// RenderLogo 输出主题中的 Logo，主题将 Logo 配置为空时不输出
 func RenderLogo(w io.Writer) {
-	lines := logo.Lines()
+	lines := current.logo
 	if len(lines) == 0 {
 		return
 	}
@@ -30,16 +31,16 @@ func ShowWelcome(w io.Writer, commands []CommandInfo) {
 	RenderLogo(w)
 
 	// 显示简介
-	fmt.Fprintln(w, Gray("  团队脚本统一管理与执行工具"))
+	fmt.Fprintln(w, Gray(i18n.T("ui.welcome.tagline")))
 	fmt.Fprintln(w, "")
 
 	// 显示核心命令
-	MenuTitle(w, "核心命令")
+	MenuTitle(w, i18n.T("ui.welcome.commands"))
 	fmt.Fprintln(w, "")
 
 	for _, c := range commands {
 		fmt.Fprintf(w, "  %s  %s\n",
-			Cyan(fmt.Sprintf("%-12s", c.Name)),
+			Cyan(width.PadRight(c.Name, 12)),
 			Gray(c.Description))
 	}
 
@@ -48,9 +49,10 @@ func ShowWelcome(w io.Writer, commands []CommandInfo) {
 	fmt.Fprintln(w, "")
 
 	// 显示快速开始提示
-	fmt.Fprintf(w, "  • 快速开始：%s\n",
+	bullet := "  " + Icons().Info + " "
+	fmt.Fprintf(w, bullet+i18n.T("ui.welcome.quick_start"),
 		Highlight("alpen ui"))
-	fmt.Fprintf(w, "  • 查看帮助：%s\n",
+	fmt.Fprintf(w, bullet+i18n.T("ui.welcome.help"),
 		Highlight("alpen help"))
 
 	fmt.Fprintln(w, "")
@@ -0,0 +1,246 @@
+// Package width 按终端显示宽度度量、截断与折行文本：中日韩文字与 emoji 占两列，组合字符与零宽字符不占列
+package width
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	zeroWidthJoiner    = '\u200d'
+	variationSelector  = '\ufe0f'
+	textPresentation   = '\ufe0e'
+	ellipsis           = "…"
+	ellipsisWidth      = 1
+	hangulJamoMedialLo = 0x1160
+	hangulJamoMedialHi = 0x11ff
+)
+
+// wideRanges 为 East Asian Width 中 W（宽）与 F（全角）的码位区间，以及默认以 emoji 形式显示的符号，按起始码位升序排列
+var wideRanges = [][2]rune{
+	{0x1100, 0x115f}, {0x231a, 0x231b}, {0x2329, 0x232a}, {0x23e9, 0x23ec}, {0x23f0, 0x23f0},
+	{0x23f3, 0x23f3}, {0x25fd, 0x25fe}, {0x2614, 0x2615}, {0x2648, 0x2653}, {0x267f, 0x267f},
+	{0x2693, 0x2693}, {0x26a1, 0x26a1}, {0x26aa, 0x26ab}, {0x26bd, 0x26be}, {0x26c4, 0x26c5},
+	{0x26ce, 0x26ce}, {0x26d4, 0x26d4}, {0x26ea, 0x26ea}, {0x26f2, 0x26f3}, {0x26f5, 0x26f5},
+	{0x26fa, 0x26fa}, {0x26fd, 0x26fd}, {0x2705, 0x2705}, {0x270a, 0x270b}, {0x2728, 0x2728},
+	{0x274c, 0x274c}, {0x274e, 0x274e}, {0x2753, 0x2755}, {0x2757, 0x2757}, {0x2795, 0x2797},
+	{0x27b0, 0x27b0}, {0x27bf, 0x27bf}, {0x2b1b, 0x2b1c}, {0x2b50, 0x2b50}, {0x2b55, 0x2b55},
+	{0x2e80, 0x303e}, {0x3041, 0x33ff}, {0x3400, 0x4dbf}, {0x4e00, 0x9fff}, {0xa000, 0xa4cf},
+	{0xa960, 0xa97f}, {0xac00, 0xd7a3}, {0xf900, 0xfaff}, {0xfe10, 0xfe19}, {0xfe30, 0xfe6f},
+	{0xff00, 0xff60}, {0xffe0, 0xffe6}, {0x16fe0, 0x16fe4}, {0x17000, 0x18cff}, {0x1b000, 0x1b2ff},
+	{0x1f004, 0x1f004}, {0x1f0cf, 0x1f0cf}, {0x1f18e, 0x1f18e}, {0x1f191, 0x1f19a}, {0x1f200, 0x1f202},
+	{0x1f210, 0x1f23b}, {0x1f240, 0x1f248}, {0x1f250, 0x1f251}, {0x1f260, 0x1f265}, {0x1f300, 0x1f320},
+	{0x1f32d, 0x1f335}, {0x1f337, 0x1f37c}, {0x1f37e, 0x1f393}, {0x1f3a0, 0x1f3ca}, {0x1f3cf, 0x1f3d3},
+	{0x1f3e0, 0x1f3f0}, {0x1f3f4, 0x1f3f4}, {0x1f3f8, 0x1f43e}, {0x1f440, 0x1f440}, {0x1f442, 0x1f4fc},
+	{0x1f4ff, 0x1f53d}, {0x1f54b, 0x1f54e}, {0x1f550, 0x1f567}, {0x1f57a, 0x1f57a}, {0x1f595, 0x1f596},
+	{0x1f5a4, 0x1f5a4}, {0x1f5fb, 0x1f64f}, {0x1f680, 0x1f6c5}, {0x1f6cc, 0x1f6cc}, {0x1f6d0, 0x1f6d2},
+	{0x1f6d5, 0x1f6d7}, {0x1f6dc, 0x1f6df}, {0x1f6eb, 0x1f6ec}, {0x1f6f4, 0x1f6fc}, {0x1f7e0, 0x1f7eb},
+	{0x1f7f0, 0x1f7f0}, {0x1f90c, 0x1f93a}, {0x1f93c, 0x1f945}, {0x1f947, 0x1f9ff}, {0x1fa70, 0x1faff},
+	{0x20000, 0x2fffd}, {0x30000, 0x3fffd},
+}
+
+// Rune 返回单个字符的显示宽度：控制字符与零宽字符为 0，宽字符为 2，其余为 1（含 East Asian Width 中的歧义字符）
+func Rune(r rune) int {
+	switch {
+	case r == 0 || r < 0x20 || (r >= 0x7f && r < 0xa0):
+		return 0
+	case r < 0x300:
+		return 1
+	case r == zeroWidthJoiner || (r >= hangulJamoMedialLo && r <= hangulJamoMedialHi):
+		return 0
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	case isWide(r):
+		return 2
+	}
+	return 1
+}
+
+func isWide(r rune) bool {
+	low, high := 0, len(wideRanges)-1
+	for low <= high {
+		mid := (low + high) / 2
+		switch {
+		case r < wideRanges[mid][0]:
+			high = mid - 1
+		case r > wideRanges[mid][1]:
+			low = mid + 1
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// Next 返回 text 开头的一个显示单元（基础字符及其后的组合字符、变体选择符与零宽连接的字符）的字节长度与显示宽度
+func Next(text string) (int, int) {
+	if text == "" {
+		return 0, 0
+	}
+	r, size := utf8.DecodeRuneInString(text)
+	cells := Rune(r)
+	for size < len(text) {
+		next, n := utf8.DecodeRuneInString(text[size:])
+		switch {
+		case next == variationSelector:
+			// 变体选择符要求以 emoji 形式显示，终端通常按两列绘制
+			if cells == 1 {
+				cells = 2
+			}
+		case next == textPresentation:
+		case next == zeroWidthJoiner:
+			// 零宽连接符与其后的字符组成同一个 emoji
+			size += n
+			if size < len(text) {
+				_, joined := utf8.DecodeRuneInString(text[size:])
+				n = joined
+			} else {
+				n = 0
+			}
+		case Rune(next) != 0:
+			return size, cells
+		}
+		size += n
+	}
+	return size, cells
+}
+
+// String 返回纯文本的显示宽度，文本不应包含 ANSI 控制序列
+func String(text string) int {
+	total := 0
+	for len(text) > 0 {
+		size, cells := Next(text)
+		total += cells
+		text = text[size:]
+	}
+	return total
+}
+
+// PadRight 在文本右侧补空格到 columns 列，文本已达到该宽度时原样返回
+func PadRight(text string, columns int) string {
+	if cells := String(text); cells < columns {
+		return text + strings.Repeat(" ", columns-cells)
+	}
+	return text
+}
+
+// Truncate 将文本截断到不超过 max 列，截断时以省略号结尾
+func Truncate(text string, max int) string {
+	if max <= 0 {
+		return ""
+	}
+	if String(text) <= max {
+		return text
+	}
+	limit := max - ellipsisWidth
+	used, end := 0, 0
+	for end < len(text) {
+		size, cells := Next(text[end:])
+		if used+cells > limit {
+			break
+		}
+		used += cells
+		end += size
+	}
+	return strings.TrimRight(text[:end], " ") + ellipsis
+}
+
+// Wrap 按显示宽度折行：西文在空格处断开，中日韩文字可在任意字符间断开，超过宽度的单词强制拆分
+func Wrap(text string, max int) []string {
+	if max <= 0 {
+		return nil
+	}
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		lines = append(lines, wrapParagraph(paragraph, max)...)
+	}
+	return lines
+}
+
+func wrapParagraph(text string, max int) []string {
+	var (
+		lines []string
+		line  strings.Builder
+		used  int
+	)
+	flush := func() {
+		lines = append(lines, strings.TrimRight(line.String(), " "))
+		line.Reset()
+		used = 0
+	}
+	for _, word := range words(text) {
+		cells := String(word)
+		if word == " " || word == "\t" {
+			// 保留段落开头的缩进，换行处的空白丢弃
+			if (used > 0 || len(lines) == 0) && used < max {
+				line.WriteString(word)
+				used++
+			}
+			continue
+		}
+		if used > 0 && used+cells > max {
+			flush()
+		}
+		for cells > max {
+			head, rest := split(word, max)
+			line.WriteString(head)
+			flush()
+			word, cells = rest, String(rest)
+		}
+		line.WriteString(word)
+		used += cells
+	}
+	if used > 0 || len(lines) == 0 {
+		flush()
+	}
+	return lines
+}
+
+// words 将文本拆分为单词、单个空白字符与单个宽字符，宽字符之间允许换行
+func words(text string) []string {
+	var (
+		result []string
+		start  = -1
+	)
+	for i := 0; i < len(text); {
+		size, cells := Next(text[i:])
+		switch {
+		case text[i] == ' ' || text[i] == '\t':
+			if start >= 0 {
+				result = append(result, text[start:i])
+				start = -1
+			}
+			result = append(result, text[i:i+1])
+		case cells == 2:
+			if start >= 0 {
+				result = append(result, text[start:i])
+				start = -1
+			}
+			result = append(result, text[i:i+size])
+		default:
+			if start < 0 {
+				start = i
+			}
+		}
+		i += size
+	}
+	if start >= 0 {
+		result = append(result, text[start:])
+	}
+	return result
+}
+
+// split 在不超过 max 列处拆分文本，至少保留一个显示单元以保证前进
+func split(text string, max int) (string, string) {
+	used, end := 0, 0
+	for end < len(text) {
+		size, cells := Next(text[end:])
+		if used+cells > max && end > 0 {
+			break
+		}
+		used += cells
+		end += size
+	}
+	return text[:end], text[end:]
+}
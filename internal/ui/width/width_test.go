@@ -0,0 +1,66 @@
+package width
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestStringMeasuresWideAndZeroWidthCharacters(t *testing.T) {
+	cases := map[string]int{
+		"deploy":                     6,
+		"部署到生产环境":                    14,
+		"ｆｕｌｌ":                       8,
+		"café":                       4,
+		"cafe\u0301":                 4,
+		"🚀 发布":                       7,
+		"\u2764\ufe0f":               2,
+		"\U0001f468\u200d\U0001f4bb": 2,
+		"\x1b":                       0,
+		"한국어":                        6,
+	}
+	for text, want := range cases {
+		if got := String(text); got != want {
+			t.Errorf("String(%q) = %d, want %d", text, got, want)
+		}
+	}
+}
+
+func TestTruncateKeepsWidthWithinLimit(t *testing.T) {
+	if got := Truncate("部署到生产环境", 7); got != "部署到…" {
+		t.Fatalf("unexpected truncation: %q", got)
+	}
+	if got := Truncate("deploy release", 10); got != "deploy re…" || String(got) > 10 {
+		t.Fatalf("unexpected truncation: %q", got)
+	}
+	if got := PadRight("部署", 6); got != "部署  " {
+		t.Fatalf("unexpected padding: %q", got)
+	}
+	if got := Truncate("短", 4); got != "短" {
+		t.Fatalf("short text should be kept, got %q", got)
+	}
+}
+
+func TestWrapBreaksAtSpacesAndBetweenWideCharacters(t *testing.T) {
+	got := Wrap("build the release image and push it", 12)
+	want := []string{"build the", "release", "image and", "push it"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("Wrap = %q, want %q", got, want)
+	}
+	got = Wrap("构建发布镜像并推送到仓库", 8)
+	want = []string{"构建发布", "镜像并推", "送到仓库"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("Wrap = %q, want %q", got, want)
+	}
+	got = Wrap("同步 staging-database 数据", 10)
+	for _, line := range got {
+		if String(line) > 10 {
+			t.Fatalf("line %q exceeds width in %q", line, got)
+		}
+	}
+	if got := Wrap("  run --all", 8); !slices.Equal(got, []string{"  run", "--all"}) {
+		t.Fatalf("leading indentation should be kept, got %q", got)
+	}
+	if !slices.Equal(Wrap("", 10), []string{""}) {
+		t.Fatalf("empty text should produce one empty line")
+	}
+}